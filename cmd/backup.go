@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// scheduleBackups takes an automatic backup every interval for as long as the
+// server runs. Failures are logged and retried at the next tick.
+func scheduleBackups(service ports.BackupService, interval time.Duration) {
+	log.Printf("Automatic backups every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		backup, err := service.CreateBackup(context.Background(), domain.BackupAutomatic)
+		if err != nil {
+			log.Printf("Automatic backup failed: %v", err)
+			continue
+		}
+		log.Printf("Automatic backup %s saved", backup.Name)
+	}
+}
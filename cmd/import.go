@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/adapters/dataset"
+	"palworld-helper/internal/adapters/datatable"
+	"palworld-helper/internal/core/services"
+)
+
+const importUsage = "usage: palworld-helper [-db path] import-datatables -version v [-dry-run] file.json..."
+
+// runImportDataTables converts DataTable exports from the game files, such as
+// the item recipe, technology, item and text tables, and upserts them
+func runImportDataTables(dbPath string, args []string) error {
+	flags := flag.NewFlagSet("import-datatables", flag.ContinueOnError)
+	version := flags.String("version", "", "game version the exports come from")
+	dryRun := flags.Bool("dry-run", false, "print what would be imported without writing to the database")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *version == "" || flags.NArg() == 0 {
+		return fmt.Errorf(importUsage)
+	}
+
+	tables := datatable.NewTables()
+	for _, path := range flags.Args() {
+		if err := tables.ReadFile(path); err != nil {
+			return err
+		}
+	}
+
+	data, err := tables.Dataset(*version)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d resources, %d recipes, %d technologies\n", len(data.Resources), len(data.Recipes), len(data.Technologies))
+	if *dryRun {
+		for _, recipe := range data.Recipes {
+			fmt.Printf("recipe %s -> %s %v\n", recipe.BlueprintID, recipe.Name, recipe.Resources)
+		}
+		for _, technology := range data.Technologies {
+			fmt.Printf("technology %s -> %s (level %d) %v\n", technology.BlueprintID, technology.Name, technology.Level, technology.Recipes)
+		}
+		return nil
+	}
+
+	db, err := database.NewSQLiteDB(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Load the bundled dataset first so the import is not overwritten by it at
+	// the next startup
+	datasetService := services.NewDatasetService(dataset.NewEmbeddedSource(), db)
+	if _, err := datasetService.Sync(); err != nil {
+		return err
+	}
+
+	status, err := datasetService.Import(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported game data %s\n", status.LoadedVersion)
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"palworld-helper/internal/adapters/database"
+)
+
+func TestRunImportDataTablesUsage(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"-version", "0.6.0"},
+		{"../internal/adapters/datatable/testdata/DT_ItemRecipeDataTable.json"},
+	}
+	for _, args := range tests {
+		if err := runImportDataTables(filepath.Join(t.TempDir(), "palworld.db"), args); err == nil {
+			t.Errorf("runImportDataTables(%q) succeeded, want the usage error", args)
+		}
+	}
+}
+
+func TestRunImportDataTablesDryRun(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "palworld.db")
+	args := []string{
+		"-version", "0.6.0", "-dry-run",
+		"../internal/adapters/datatable/testdata/DT_ItemRecipeDataTable.json",
+		"../internal/adapters/datatable/testdata/DT_TechnologyRecipeUnlock.json",
+	}
+	if err := runImportDataTables(dbPath, args); err != nil {
+		t.Fatalf("runImportDataTables() error = %v", err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("dry run created the database: %v", err)
+	}
+
+	if err := runImportDataTables(dbPath, []string{"-version", "0.6.0", "-dry-run", "missing.json"}); err == nil {
+		t.Error("runImportDataTables() of a missing file succeeded, want an error")
+	}
+}
+
+func TestRunImportDataTables(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "palworld.db")
+	args := []string{
+		"-version", "0.6.0",
+		"../internal/adapters/datatable/testdata/DT_ItemRecipeDataTable.json",
+		"../internal/adapters/datatable/testdata/DT_ItemNameText.json",
+	}
+	if err := runImportDataTables(dbPath, args); err != nil {
+		t.Fatalf("runImportDataTables() error = %v", err)
+	}
+
+	db, err := database.NewSQLiteDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	recipes, err := db.GetAllRecipes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := make(map[string]int)
+	for _, recipe := range recipes {
+		imported[recipe.Name] = recipe.WorkAmount
+	}
+	// The bundled Pal Sphere is updated and the new recipe is inserted
+	if imported["Pal Sphere"] != 300 {
+		t.Errorf("Pal Sphere work amount = %d, want the imported 300", imported["Pal Sphere"])
+	}
+	if _, ok := imported["Pal crystal S"]; !ok {
+		t.Error("Pal crystal S was not imported")
+	}
+}
@@ -1,27 +1,103 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
 	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/adapters/dataset"
+	"palworld-helper/internal/core/domain"
 	"palworld-helper/internal/core/services"
 	"palworld-helper/web"
 )
 
 func main() {
+	dbPath := flag.String("db", "./data/palworld.db", "path to the SQLite database")
+	queryReadRole := flag.String("query-read-role", string(domain.DefaultQueryPolicy.ReadRole), "role allowed to run read-only SQL from the admin query page")
+	queryWriteRole := flag.String("query-write-role", string(domain.DefaultQueryPolicy.WriteRole), "role allowed to run SQL that changes data from the admin query page, or none")
+	queryTimeout := flag.Duration("query-timeout", domain.DefaultQueryPolicy.Timeout, "time after which a query from the admin query page is interrupted, or 0 for no limit")
+	backupDir := flag.String("backup-dir", "", "directory of database backups (default: backups next to the database)")
+	backupInterval := flag.Duration("backup-interval", 0, "time between automatic backups, such as 24h, or 0 to disable them")
+	backupKeep := flag.Int("backup-keep", 7, "number of automatic backups, and of backups taken before restores, to keep, or 0 to keep all of them")
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "migrate":
+		if err := runMigrate(*dbPath, flag.Args()[1:]); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	case "import-datatables":
+		if err := runImportDataTables(*dbPath, flag.Args()[1:]); err != nil {
+			log.Fatal("Import failed: ", err)
+		}
+		return
+	case "create-admin":
+		if err := runCreateAdmin(*dbPath, flag.Args()[1:]); err != nil {
+			log.Fatal("Failed to create admin: ", err)
+		}
+		return
+	}
+
+	queryPolicy, err := parseQueryPolicy(*queryReadRole, *queryWriteRole, *queryTimeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Initialize database
-	db, err := database.NewSQLiteDB("./data/palworld.db")
+	db, err := database.NewSQLiteDB(*dbPath)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 	defer db.Close()
 
+	// Load the bundled game dataset when its version changed
+	datasetService := services.NewDatasetService(dataset.NewEmbeddedSource(), db)
+	status, err := datasetService.Sync()
+	if err != nil {
+		log.Fatal("Failed to load game dataset:", err)
+	}
+	log.Printf("Game dataset %s loaded", status.LoadedVersion)
+
 	// Initialize services
-	craftingService := services.NewCraftingService(db)
-	adminService := services.NewAdminService(db)
+	craftingService := services.NewCraftingService(db, db)
+	versionService := services.NewGameVersionService(db)
+	listService := services.NewCraftingListService(db, db, craftingService, versionService)
+	exportService := services.NewExportService(db, craftingService, listService)
+	technologyService := services.NewTechnologyService(db)
+	// Paldeck images are served from web/static by the web server
+	paldeckImages := os.DirFS("web/static/images/paldeck")
+	palService := services.NewPalService(db, paldeckImages)
+	breedingService := services.NewBreedingService(db, paldeckImages)
+	adminService := services.NewAdminService(db, db, queryPolicy)
+	authService := services.NewAuthService(db)
+
+	if *backupDir == "" {
+		*backupDir = filepath.Join(filepath.Dir(*dbPath), "backups")
+	}
+	backupService := services.NewBackupService(db, db, *backupDir, *backupKeep)
+	if *backupInterval > 0 {
+		go scheduleBackups(backupService, *backupInterval)
+	}
 
 	// Initialize web server
-	server := web.NewServer(craftingService, adminService)
+	server := web.NewServer(
+		craftingService,
+		listService,
+		exportService,
+		technologyService,
+		palService,
+		breedingService,
+		datasetService,
+		versionService,
+		adminService,
+		authService,
+		backupService,
+	)
 
 	log.Println("Palworld Helper starting on http://localhost:8080")
 	log.Println("Admin interface available at http://localhost:8080/admin")
@@ -30,3 +106,21 @@ func main() {
 		log.Fatal("Server failed to start:", err)
 	}
 }
+
+// parseQueryPolicy reads the roles and timeout of the admin query page, where
+// a write role of "none" makes every query read-only
+func parseQueryPolicy(readRole, writeRole string, timeout time.Duration) (domain.QueryPolicy, error) {
+	policy := domain.QueryPolicy{ReadRole: domain.Role(readRole), WriteRole: domain.Role(writeRole), Timeout: timeout}
+	if timeout < 0 {
+		return policy, fmt.Errorf("invalid -query-timeout %s, expected a positive duration or 0", timeout)
+	}
+	if !policy.ReadRole.Valid() {
+		return policy, fmt.Errorf("invalid -query-read-role %q, expected viewer, editor or admin", readRole)
+	}
+	if writeRole == "none" {
+		policy.WriteRole = ""
+	} else if !policy.WriteRole.Valid() {
+		return policy, fmt.Errorf("invalid -query-write-role %q, expected viewer, editor, admin or none", writeRole)
+	}
+	return policy, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/services"
+)
+
+const createAdminUsage = "usage: palworld-helper [-db path] create-admin -username name < password"
+
+// runCreateAdmin creates an admin user of the admin interface. The password is
+// read from the first line of stdin so it stays out of the shell history.
+func runCreateAdmin(dbPath string, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "name of the admin user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || flags.NArg() > 0 {
+		return fmt.Errorf(createAdminUsage)
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+
+	db, err := database.NewSQLiteDB(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := services.NewAuthService(db).CreateUser(domain.Credentials{
+		Username: *username,
+		Password: strings.TrimRight(password, "\r\n"),
+		Role:     domain.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Admin user %s created\n", user.Username)
+	return nil
+}
@@ -0,0 +1,445 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// loadMigrations reads the embedded migration scripts ordered by version
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// ensureMigrationTable creates the schema_migrations version table
+func (s *SQLiteDB) ensureMigrationTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the applied_at time of every applied version
+func (s *SQLiteDB) appliedMigrations() (map[int]string, error) {
+	if err := s.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT version, COALESCE(applied_at, '') FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// Migrate applies every pending migration
+func (s *SQLiteDB) Migrate() error {
+	_, err := s.MigrateUp(0)
+	return err
+}
+
+// MigrateUp applies up to steps pending migrations, or all of them when steps
+// is zero, and returns the versions that were applied
+func (s *SQLiteDB) MigrateUp(steps int) ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []int
+	for _, m := range migrations {
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		baseline := m.Version == migrations[0].Version
+		if err := s.applyMigration(m, baseline); err != nil {
+			return done, err
+		}
+		done = append(done, m.Version)
+	}
+
+	return done, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, one when steps is
+// zero, and returns the versions that were reverted
+func (s *SQLiteDB) MigrateDown(steps int) ([]int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []int
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := s.revertMigration(m); err != nil {
+			return done, err
+		}
+		done = append(done, m.Version)
+	}
+
+	return done, nil
+}
+
+// MigrationStatus lists every known migration and whether it is applied
+func (s *SQLiteDB) MigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		status = append(status, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return status, nil
+}
+
+// applyMigration runs an up script and records its version in one transaction.
+// The baseline migration first adopts tables left by the pre-migration schema.
+func (s *SQLiteDB) applyMigration(m Migration, baseline bool) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	restore, err := disableForeignKeys(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	var legacy map[string][]string
+	if baseline {
+		legacy, err = s.legacyTables(m.Up)
+		if err != nil {
+			return err
+		}
+		// Keep foreign keys in other tables pointing at the original name while
+		// a legacy table is renamed out of the way
+		if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = ON"); err != nil {
+			return fmt.Errorf("failed to enable legacy_alter_table: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = OFF"); err != nil {
+				dropConn(conn)
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for table := range legacy {
+		if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s_legacy"`, table, table)); err != nil {
+			return fmt.Errorf("failed to rename legacy table %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.Exec(m.Up); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+	}
+
+	for table, columns := range legacy {
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = `"` + column + `"`
+		}
+		list := strings.Join(quoted, ", ")
+
+		copyQuery := fmt.Sprintf(`INSERT INTO "%s" (%s) SELECT %s FROM "%s_legacy"`, table, list, list, table)
+		if _, err := tx.Exec(copyQuery); err != nil {
+			return fmt.Errorf("failed to copy legacy table %s: %w", table, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DROP TABLE "%s_legacy"`, table)); err != nil {
+			return fmt.Errorf("failed to drop legacy table %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// revertMigration runs a down script and forgets its version in one transaction
+func (s *SQLiteDB) revertMigration(m Migration) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	restore, err := disableForeignKeys(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.Down); err != nil {
+		return fmt.Errorf("failed to revert migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+		return fmt.Errorf("failed to forget migration %d: %w", m.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// disableForeignKeys turns foreign key enforcement off on a connection, so
+// tables can be dropped and rebuilt without their rows cascading. The returned
+// function restores the previous setting.
+func disableForeignKeys(ctx context.Context, conn *sql.Conn) (func(), error) {
+	var foreignKeys int
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, fmt.Errorf("failed to read foreign_keys: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA foreign_keys = %d", foreignKeys)); err != nil {
+			dropConn(conn)
+		}
+	}, nil
+}
+
+// dropConn discards a connection rather than return it to the pool with other
+// settings than the ones it was opened with
+func dropConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+}
+
+// legacyTables finds existing tables whose columns differ from the ones the
+// baseline script creates. It returns the columns each of them shares with
+// the baseline, which are the ones copied over when the table is rebuilt.
+func (s *SQLiteDB) legacyTables(baselineSQL string) (map[string][]string, error) {
+	scratch, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scratch database: %w", err)
+	}
+	defer scratch.Close()
+	scratch.SetMaxOpenConns(1)
+
+	if _, err := scratch.Exec(baselineSQL); err != nil {
+		return nil, fmt.Errorf("failed to load baseline schema: %w", err)
+	}
+
+	tables, err := tableNames(scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := make(map[string][]string)
+	for _, table := range tables {
+		want, err := tableColumns(scratch, table)
+		if err != nil {
+			return nil, err
+		}
+		have, err := tableColumns(s.db, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(have) == 0 || sameColumns(want, have) {
+			continue
+		}
+
+		existing := make(map[string]bool, len(have))
+		for _, column := range have {
+			existing[column.name] = true
+		}
+		var shared []string
+		for _, column := range want {
+			if existing[column.name] {
+				shared = append(shared, column.name)
+			}
+		}
+		legacy[table] = shared
+	}
+
+	return legacy, nil
+}
+
+func tableNames(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+type columnDef struct {
+	name     string
+	dataType string
+}
+
+func tableColumns(db *sql.DB, table string) ([]columnDef, error) {
+	rows, err := db.Query("SELECT name, UPPER(type) FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []columnDef
+	for rows.Next() {
+		var column columnDef
+		if err := rows.Scan(&column.name, &column.dataType); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func sameColumns(a, b []columnDef) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
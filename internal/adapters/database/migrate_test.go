@@ -0,0 +1,186 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// legacySchema is the schema databases had before migrations existed, with a
+// few rows in it
+const legacySchema = `
+CREATE TABLE resources (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE categories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE crafting_recipes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	category_id TEXT NOT NULL,
+	FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+CREATE TABLE recipe_resources (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	recipe_id INTEGER NOT NULL,
+	resource_id INTEGER NOT NULL,
+	quantity INTEGER NOT NULL,
+	FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
+	FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
+	UNIQUE(recipe_id, resource_id)
+);
+CREATE TABLE technologies (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	level INTEGER NOT NULL
+);
+
+INSERT INTO categories (id, name) VALUES (1, 'Structures');
+INSERT INTO resources (id, name) VALUES (1, 'Wood'), (2, 'Stone');
+INSERT INTO crafting_recipes (id, name, category_id) VALUES (1, 'Campfire', 1);
+INSERT INTO recipe_resources (recipe_id, resource_id, quantity) VALUES (1, 1, 10), (1, 2, 3);
+INSERT INTO technologies (id, name, level) VALUES (1, 'Campfire', 1);
+`
+
+// newLegacyDB creates a database with the pre-migration schema
+func newLegacyDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+	return path
+}
+
+// schemaSQL returns the statements SQLite stored for every table and index
+func schemaSQL(t *testing.T, db *SQLiteDB) map[string]string {
+	t.Helper()
+	rows, err := db.db.Query("SELECT name, sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	defer rows.Close()
+
+	schema := make(map[string]string)
+	for rows.Next() {
+		var name, statement string
+		if err := rows.Scan(&name, &statement); err != nil {
+			t.Fatalf("reading schema: %v", err)
+		}
+		schema[name] = statement
+	}
+	return schema
+}
+
+// checkLegacyRows checks the rows of the legacy schema made it through
+func checkLegacyRows(t *testing.T, db *SQLiteDB) {
+	t.Helper()
+	if n := countRows(t, db, "SELECT count(*) FROM resources WHERE name IN ('Wood', 'Stone')"); n != 2 {
+		t.Errorf("%d of the 2 resources kept", n)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM crafting_recipes WHERE id = 1 AND name = 'Campfire'"); n != 1 {
+		t.Errorf("%d of the 1 recipe kept", n)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM recipe_resources WHERE recipe_id = 1 AND quantity IN (10, 3)"); n != 2 {
+		t.Errorf("%d of the 2 recipe ingredients kept", n)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM technologies WHERE name = 'Campfire' AND level = 1"); n != 1 {
+		t.Errorf("%d of the 1 technology kept", n)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	path := newLegacyDB(t)
+
+	db, err := NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+
+	checkLegacyRows(t, db)
+
+	// Adopted tables have the baseline columns, with defaults for the new ones
+	if n := countRows(t, db, "SELECT count(*) FROM crafting_recipes WHERE category = '' AND yield = 1"); n != 1 {
+		t.Errorf("adopted recipe lacks the defaults of the baseline columns")
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM sqlite_master WHERE name LIKE '%_legacy'"); n != 0 {
+		t.Errorf("%d legacy tables left behind", n)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM pragma_foreign_key_check"); n != 0 {
+		t.Errorf("%d rows break a foreign key after adoption", n)
+	}
+
+	status, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	for _, migration := range status {
+		if !migration.Applied {
+			t.Errorf("migration %d_%s not applied", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	db, err := NewSQLiteDB(newLegacyDB(t))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+
+	// Reverting every migration but the baseline keeps the rows it holds
+	reverted, err := db.MigrateDown(len(migrations) - 1)
+	if err != nil {
+		t.Fatalf("MigrateDown() error = %v", err)
+	}
+	if len(reverted) != len(migrations)-1 || reverted[0] != migrations[len(migrations)-1].Version {
+		t.Fatalf("MigrateDown() reverted %v", reverted)
+	}
+	checkLegacyRows(t, db)
+
+	applied, err := db.MigrateUp(0)
+	if err != nil {
+		t.Fatalf("MigrateUp() error = %v", err)
+	}
+	if len(applied) != len(migrations)-1 {
+		t.Fatalf("MigrateUp() applied %v", applied)
+	}
+	checkLegacyRows(t, db)
+
+	// Down to nothing and back gives the schema of a new database, the
+	// adopted tables whose columns matched being created anew
+	if _, err := db.MigrateDown(len(migrations)); err != nil {
+		t.Fatalf("MigrateDown() of every migration error = %v", err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')"); n != 0 {
+		t.Errorf("%d tables left after reverting every migration", n)
+	}
+	if _, err := db.MigrateUp(0); err != nil {
+		t.Fatalf("MigrateUp() error = %v", err)
+	}
+
+	again := schemaSQL(t, db)
+	fresh := schemaSQL(t, newTestDB(t))
+	if len(again) != len(fresh) {
+		t.Errorf("schema has %d objects after the round trip, want %d", len(again), len(fresh))
+	}
+	for name, statement := range fresh {
+		if again[name] != statement {
+			t.Errorf("%s after the round trip = %q, want %q", name, again[name], statement)
+		}
+	}
+}
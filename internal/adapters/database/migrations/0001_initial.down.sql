@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS pal_drops;
+DROP TABLE IF EXISTS pal_work_suitabilities;
+DROP TABLE IF EXISTS pal_elements;
+DROP TABLE IF EXISTS breeding_combinations;
+DROP TABLE IF EXISTS pals;
+DROP TABLE IF EXISTS crafting_list_items;
+DROP TABLE IF EXISTS crafting_lists;
+DROP TABLE IF EXISTS inventory;
+DROP TABLE IF EXISTS unlocked_technologies;
+DROP TABLE IF EXISTS technology_recipes;
+DROP TABLE IF EXISTS technologies;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS recipe_resources;
+DROP TABLE IF EXISTS crafting_stations;
+DROP TABLE IF EXISTS crafting_recipes;
+DROP TABLE IF EXISTS resources;
@@ -0,0 +1,140 @@
+-- Baseline schema. Tables that already exist in a database created before
+-- migrations were introduced are rebuilt to this shape when it is adopted.
+
+CREATE TABLE IF NOT EXISTS resources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    recipe_id INTEGER,
+    FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS crafting_recipes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    station_id INTEGER,
+    yield INTEGER NOT NULL DEFAULT 1,
+    work_amount INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (station_id) REFERENCES crafting_stations(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS crafting_stations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    recipe_id INTEGER,
+    built BOOLEAN NOT NULL DEFAULT 0,
+    FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS recipe_resources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    recipe_id INTEGER NOT NULL,
+    resource_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
+    UNIQUE(recipe_id, resource_id)
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS technologies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    level INTEGER NOT NULL,
+    points INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS technology_recipes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    technology_id INTEGER NOT NULL,
+    recipe_id INTEGER NOT NULL,
+    FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
+    FOREIGN KEY (technology_id) REFERENCES technologies(id) ON DELETE CASCADE,
+    UNIQUE(technology_id, recipe_id)
+);
+
+CREATE TABLE IF NOT EXISTS unlocked_technologies (
+    technology_id INTEGER PRIMARY KEY,
+    unlocked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (technology_id) REFERENCES technologies(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS inventory (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
+    UNIQUE(resource_id)
+);
+
+CREATE TABLE IF NOT EXISTS crafting_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS crafting_list_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_id INTEGER NOT NULL,
+    recipe_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    FOREIGN KEY (list_id) REFERENCES crafting_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
+    UNIQUE(list_id, recipe_id)
+);
+
+CREATE TABLE IF NOT EXISTS pals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    paldeck_number INTEGER NOT NULL,
+    variant TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    breeding_power INTEGER NOT NULL DEFAULT 0,
+    UNIQUE(paldeck_number, variant)
+);
+
+CREATE TABLE IF NOT EXISTS breeding_combinations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent1_id INTEGER NOT NULL,
+    parent2_id INTEGER NOT NULL,
+    child_id INTEGER NOT NULL,
+    FOREIGN KEY (parent1_id) REFERENCES pals(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent2_id) REFERENCES pals(id) ON DELETE CASCADE,
+    FOREIGN KEY (child_id) REFERENCES pals(id) ON DELETE CASCADE,
+    UNIQUE(parent1_id, parent2_id)
+);
+
+CREATE TABLE IF NOT EXISTS pal_elements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pal_id INTEGER NOT NULL,
+    element TEXT NOT NULL,
+    FOREIGN KEY (pal_id) REFERENCES pals(id) ON DELETE CASCADE,
+    UNIQUE(pal_id, element)
+);
+
+CREATE TABLE IF NOT EXISTS pal_work_suitabilities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pal_id INTEGER NOT NULL,
+    work_type TEXT NOT NULL,
+    level INTEGER NOT NULL,
+    FOREIGN KEY (pal_id) REFERENCES pals(id) ON DELETE CASCADE,
+    UNIQUE(pal_id, work_type)
+);
+
+CREATE TABLE IF NOT EXISTS pal_drops (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pal_id INTEGER NOT NULL,
+    resource_id INTEGER NOT NULL,
+    min_quantity INTEGER NOT NULL DEFAULT 1,
+    max_quantity INTEGER NOT NULL DEFAULT 1,
+    drop_rate REAL NOT NULL DEFAULT 100,
+    FOREIGN KEY (pal_id) REFERENCES pals(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
+    UNIQUE(pal_id, resource_id)
+);
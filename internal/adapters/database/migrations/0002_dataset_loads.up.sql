@@ -0,0 +1,6 @@
+-- History of bundled game datasets loaded into the database
+CREATE TABLE IF NOT EXISTS dataset_loads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version TEXT NOT NULL,
+    loaded_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+ALTER TABLE dataset_loads DROP COLUMN source;
+
+DROP INDEX IF EXISTS idx_technologies_blueprint_id;
+DROP INDEX IF EXISTS idx_crafting_recipes_blueprint_id;
+DROP INDEX IF EXISTS idx_resources_blueprint_id;
+
+ALTER TABLE technologies DROP COLUMN blueprint_id;
+ALTER TABLE crafting_recipes DROP COLUMN blueprint_id;
+ALTER TABLE resources DROP COLUMN blueprint_id;
@@ -0,0 +1,12 @@
+-- Internal game identifiers such as PalSphere, used to match DataTable imports
+ALTER TABLE resources ADD COLUMN blueprint_id TEXT;
+ALTER TABLE crafting_recipes ADD COLUMN blueprint_id TEXT;
+ALTER TABLE technologies ADD COLUMN blueprint_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_blueprint_id ON resources(blueprint_id) WHERE blueprint_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_crafting_recipes_blueprint_id ON crafting_recipes(blueprint_id) WHERE blueprint_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_technologies_blueprint_id ON technologies(blueprint_id) WHERE blueprint_id IS NOT NULL;
+
+-- Imports from game files are tracked apart from the bundled dataset so that
+-- startup only reloads the bundled dataset when its own version changes
+ALTER TABLE dataset_loads ADD COLUMN source TEXT NOT NULL DEFAULT 'bundled';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS recipe_versions;
+DROP TABLE IF EXISTS game_versions;
+
+ALTER TABLE crafting_lists DROP COLUMN game_version;
+ALTER TABLE technologies DROP COLUMN game_version;
+ALTER TABLE crafting_recipes DROP COLUMN game_version;
+ALTER TABLE resources DROP COLUMN game_version;
+ALTER TABLE dataset_loads DROP COLUMN game_version;
@@ -0,0 +1,22 @@
+-- Game version each record and saved list was last written for
+ALTER TABLE dataset_loads ADD COLUMN game_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE resources ADD COLUMN game_version TEXT;
+ALTER TABLE crafting_recipes ADD COLUMN game_version TEXT;
+ALTER TABLE technologies ADD COLUMN game_version TEXT;
+ALTER TABLE crafting_lists ADD COLUMN game_version TEXT;
+
+CREATE TABLE IF NOT EXISTS game_versions (
+    version TEXT PRIMARY KEY,
+    recorded_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Recipe ingredients as they were in each game version, used to diff patches
+CREATE TABLE IF NOT EXISTS recipe_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_version TEXT NOT NULL,
+    recipe_name TEXT NOT NULL,
+    resource_name TEXT NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (game_version) REFERENCES game_versions(version) ON DELETE CASCADE,
+    UNIQUE(game_version, recipe_name, resource_name)
+);
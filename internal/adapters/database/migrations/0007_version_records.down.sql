@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS technology_versions;
+DROP TABLE IF EXISTS resource_versions;
@@ -0,0 +1,24 @@
+-- Resources and technologies present in each game version, used to diff patches
+CREATE TABLE IF NOT EXISTS resource_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_version TEXT NOT NULL,
+    resource_name TEXT NOT NULL,
+    FOREIGN KEY (game_version) REFERENCES game_versions(version) ON DELETE CASCADE,
+    UNIQUE(game_version, resource_name)
+);
+
+CREATE TABLE IF NOT EXISTS technology_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_version TEXT NOT NULL,
+    technology_name TEXT NOT NULL,
+    FOREIGN KEY (game_version) REFERENCES game_versions(version) ON DELETE CASCADE,
+    UNIQUE(game_version, technology_name)
+);
+
+-- Versions recorded before resources and technologies were tracked are
+-- assumed to hold the current ones, so diffs against them report no change
+INSERT OR IGNORE INTO resource_versions (game_version, resource_name)
+SELECT gv.version, r.name FROM game_versions gv, resources r;
+
+INSERT OR IGNORE INTO technology_versions (game_version, technology_name)
+SELECT gv.version, t.name FROM game_versions gv, technologies t;
@@ -1,29 +1,51 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 type SQLiteDB struct {
 	db *sql.DB
 }
 
-// NewSQLiteDB creates a new SQLite database connection and initializes the schema
+// NewSQLiteDB creates a new SQLite database connection and applies pending migrations
 func NewSQLiteDB(dbPath string) (*SQLiteDB, error) {
+	sqlite, err := OpenSQLiteDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlite.Migrate(); err != nil {
+		sqlite.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %v", err)
+	}
+
+	return sqlite, nil
+}
+
+// OpenSQLiteDB opens a SQLite database connection without touching the schema
+func OpenSQLiteDB(dbPath string) (*SQLiteDB, error) {
 	// Create data directory if it doesn't exist
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// Every connection enforces the foreign keys declared by the schema, such
+	// as the ON DELETE CASCADE of recipe ingredients
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %v", err)
 	}
@@ -32,14 +54,7 @@ func NewSQLiteDB(dbPath string) (*SQLiteDB, error) {
 		return nil, fmt.Errorf("failed to ping database: %v", err)
 	}
 
-	sqlite := &SQLiteDB{db: db}
-
-	// Initialize schema and sample data
-	if err := sqlite.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %v", err)
-	}
-
-	return sqlite, nil
+	return &SQLiteDB{db: db}, nil
 }
 
 // Close closes the database connection
@@ -47,145 +62,12 @@ func (s *SQLiteDB) Close() error {
 	return s.db.Close()
 }
 
-// initSchema creates the initial database schema and populates with sample data
-func (s *SQLiteDB) initSchema() error {
-	// Create tables
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS resources (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE IF NOT EXISTS crafting_recipes (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			category_id TEXT NOT NULL,
-			FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
-		)`,
-		`CREATE TABLE IF NOT EXISTS recipe_resources (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			recipe_id INTEGER NOT NULL,
-			resource_id INTEGER NOT NULL,
-			quantity INTEGER NOT NULL,
-			FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
-			FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
-			UNIQUE(recipe_id, resource_id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS categories (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE
-		)`,
-		`CREATE TABLE IF NOT EXISTS technologies (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			level INTEGER NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS technology_recipes (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			technology_id TEXT NOT NULL,
-			recipe_id INTEGER NOT NULL,
-			FOREIGN KEY (recipe_id) REFERENCES crafting_recipes(id) ON DELETE CASCADE,
-			FOREIGN KEY (technology_id) REFERENCES technologies(id) ON DELETE CASCADE,
-			UNIQUE(technology_id, recipe_id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS inventory (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			resource_id INTEGER NOT NULL,
-			quantity INTEGER NOT NULL,
-			FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %v", err)
-		}
-	}
-
-	// Check if we need to populate sample data
-	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM crafting_recipes").Scan(&count)
-	if err != nil {
-		return err
-	}
-
-	if count == 0 {
-		return s.populateSampleData()
-	}
-
-	return nil
-}
-
-// populateSampleData inserts sample crafting data
-func (s *SQLiteDB) populateSampleData() error {
-	// Insert resources
-	resources := []string{
-		"Wood", "Stone", "Cloth", "Paldium Fragment", "Metal Ore", "Coal", "Fiber",
-	}
-
-	for _, resource := range resources {
-		_, err := s.db.Exec("INSERT OR IGNORE INTO resources (name) VALUES (?)", resource)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Insert recipes
-	recipes := []struct {
-		name, category, description string
-		resources                   map[string]int
-	}{
-		{"Wooden Club", "Weapons", "A simple wooden weapon for early combat", map[string]int{"Wood": 5, "Stone": 2}},
-		{"Stone Pickaxe", "Tools", "Essential tool for mining stone and ore", map[string]int{"Wood": 5, "Stone": 5}},
-		{"Stone Axe", "Tools", "Efficient tool for cutting trees", map[string]int{"Wood": 5, "Stone": 5}},
-		{"Campfire", "Structures", "Cook food and provide warmth", map[string]int{"Wood": 10, "Stone": 5}},
-		{"Wooden Chest", "Storage", "Basic storage container", map[string]int{"Wood": 15, "Stone": 5}},
-		{"Cloth Outfit", "Armor", "Basic protection from elements", map[string]int{"Cloth": 10}},
-		{"Pal Sphere", "Pal Items", "Capture wild Pals", map[string]int{"Paldium Fragment": 3, "Wood": 3, "Stone": 3}},
-		{"Workbench", "Structures", "Craft advanced items", map[string]int{"Wood": 20, "Stone": 10}},
-		{"Wooden Foundation", "Building", "Foundation for wooden structures", map[string]int{"Wood": 8}},
-		{"Wooden Wall", "Building", "Wall for wooden structures", map[string]int{"Wood": 6}},
-	}
-
-	for _, recipe := range recipes {
-		// Insert recipe
-		result, err := s.db.Exec(
-			"INSERT INTO crafting_recipes (name, category, description) VALUES (?, ?, ?)",
-			recipe.name, recipe.category, recipe.description,
-		)
-		if err != nil {
-			return err
-		}
-
-		recipeID, _ := result.LastInsertId()
-
-		// Insert recipe resources
-		for resourceName, quantity := range recipe.resources {
-			// Get resource ID
-			var resourceID int
-			err := s.db.QueryRow("SELECT id FROM resources WHERE name = ?", resourceName).Scan(&resourceID)
-			if err != nil {
-				return err
-			}
-
-			// Insert recipe resource relationship
-			_, err = s.db.Exec(
-				"INSERT INTO recipe_resources (recipe_id, resource_id, quantity) VALUES (?, ?, ?)",
-				recipeID, resourceID, quantity,
-			)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
 // GetAllRecipes retrieves all recipes with their resources
 func (s *SQLiteDB) GetAllRecipes() ([]domain.RecipeWithResources, error) {
 	query := `
-		SELECT cr.id, cr.name, cr.category, cr.description
+		SELECT cr.id, cr.name, cr.category, cr.description, cr.station_id, cr.yield, cr.work_amount, COALESCE(cs.name, '')
 		FROM crafting_recipes cr
+		LEFT JOIN crafting_stations cs ON cs.id = cr.station_id
 		ORDER BY cr.name
 	`
 
@@ -198,10 +80,12 @@ func (s *SQLiteDB) GetAllRecipes() ([]domain.RecipeWithResources, error) {
 	var recipes []domain.RecipeWithResources
 	for rows.Next() {
 		var recipe domain.RecipeWithResources
-		err := rows.Scan(&recipe.ID, &recipe.Name, &recipe.Category, &recipe.Description)
+		var stationID sql.NullInt64
+		err := rows.Scan(&recipe.ID, &recipe.Name, &recipe.Category, &recipe.Description, &stationID, &recipe.Yield, &recipe.WorkAmount, &recipe.Station)
 		if err != nil {
 			return nil, err
 		}
+		recipe.StationID = nullIntPtr(stationID)
 
 		// Get resources for this recipe
 		resources, err := s.GetRecipeResources(recipe.ID)
@@ -219,19 +103,22 @@ func (s *SQLiteDB) GetAllRecipes() ([]domain.RecipeWithResources, error) {
 // GetRecipeByID retrieves a specific recipe by ID
 func (s *SQLiteDB) GetRecipeByID(id int) (*domain.RecipeWithResources, error) {
 	query := `
-		SELECT cr.id, cr.name, cr.category, cr.description
+		SELECT cr.id, cr.name, cr.category, cr.description, cr.station_id, cr.yield, cr.work_amount, COALESCE(cs.name, '')
 		FROM crafting_recipes cr
+		LEFT JOIN crafting_stations cs ON cs.id = cr.station_id
 		WHERE cr.id = ?
 	`
 
 	var recipe domain.RecipeWithResources
-	err := s.db.QueryRow(query, id).Scan(&recipe.ID, &recipe.Name, &recipe.Category, &recipe.Description)
+	var stationID sql.NullInt64
+	err := s.db.QueryRow(query, id).Scan(&recipe.ID, &recipe.Name, &recipe.Category, &recipe.Description, &stationID, &recipe.Yield, &recipe.WorkAmount, &recipe.Station)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	recipe.StationID = nullIntPtr(stationID)
 
 	// Get resources for this recipe
 	resources, err := s.GetRecipeResources(recipe.ID)
@@ -246,7 +133,7 @@ func (s *SQLiteDB) GetRecipeByID(id int) (*domain.RecipeWithResources, error) {
 // GetRecipeResources retrieves resources for a specific recipe
 func (s *SQLiteDB) GetRecipeResources(recipeID int) ([]domain.ResourceWithQuantity, error) {
 	query := `
-		SELECT r.id, r.name, rr.quantity
+		SELECT r.id, r.name, rr.quantity, r.recipe_id
 		FROM resources r
 		JOIN recipe_resources rr ON r.id = rr.resource_id
 		WHERE rr.recipe_id = ?
@@ -262,10 +149,12 @@ func (s *SQLiteDB) GetRecipeResources(recipeID int) ([]domain.ResourceWithQuanti
 	var resources []domain.ResourceWithQuantity
 	for rows.Next() {
 		var resource domain.ResourceWithQuantity
-		err := rows.Scan(&resource.ID, &resource.Name, &resource.Quantity)
+		var recipeID sql.NullInt64
+		err := rows.Scan(&resource.ID, &resource.Name, &resource.Quantity, &recipeID)
 		if err != nil {
 			return nil, err
 		}
+		resource.RecipeID = nullIntPtr(recipeID)
 		resources = append(resources, resource)
 	}
 
@@ -275,8 +164,8 @@ func (s *SQLiteDB) GetRecipeResources(recipeID int) ([]domain.ResourceWithQuanti
 // Implement remaining methods for CraftingRepository interface...
 func (s *SQLiteDB) CreateRecipe(recipe *domain.CraftingRecipe) error {
 	result, err := s.db.Exec(
-		"INSERT INTO crafting_recipes (name, category, description) VALUES (?, ?, ?)",
-		recipe.Name, recipe.Category, recipe.Description,
+		"INSERT INTO crafting_recipes (name, category, description, station_id, yield, work_amount) VALUES (?, ?, ?, ?, ?, ?)",
+		recipe.Name, recipe.Category, recipe.Description, recipe.StationID, max(recipe.Yield, 1), recipe.WorkAmount,
 	)
 	if err != nil {
 		return err
@@ -289,8 +178,8 @@ func (s *SQLiteDB) CreateRecipe(recipe *domain.CraftingRecipe) error {
 
 func (s *SQLiteDB) UpdateRecipe(recipe *domain.CraftingRecipe) error {
 	_, err := s.db.Exec(
-		"UPDATE crafting_recipes SET name = ?, category = ?, description = ? WHERE id = ?",
-		recipe.Name, recipe.Category, recipe.Description, recipe.ID,
+		"UPDATE crafting_recipes SET name = ?, category = ?, description = ?, station_id = ?, yield = ?, work_amount = ? WHERE id = ?",
+		recipe.Name, recipe.Category, recipe.Description, recipe.StationID, max(recipe.Yield, 1), recipe.WorkAmount, recipe.ID,
 	)
 	return err
 }
@@ -301,7 +190,7 @@ func (s *SQLiteDB) DeleteRecipe(id int) error {
 }
 
 func (s *SQLiteDB) GetAllResources() ([]domain.Resource, error) {
-	rows, err := s.db.Query("SELECT id, name FROM resources ORDER BY name")
+	rows, err := s.db.Query("SELECT id, name, recipe_id FROM resources ORDER BY name")
 	if err != nil {
 		return nil, err
 	}
@@ -310,10 +199,12 @@ func (s *SQLiteDB) GetAllResources() ([]domain.Resource, error) {
 	var resources []domain.Resource
 	for rows.Next() {
 		var resource domain.Resource
-		err := rows.Scan(&resource.ID, &resource.Name)
+		var recipeID sql.NullInt64
+		err := rows.Scan(&resource.ID, &resource.Name, &recipeID)
 		if err != nil {
 			return nil, err
 		}
+		resource.RecipeID = nullIntPtr(recipeID)
 		resources = append(resources, resource)
 	}
 
@@ -321,7 +212,7 @@ func (s *SQLiteDB) GetAllResources() ([]domain.Resource, error) {
 }
 
 func (s *SQLiteDB) CreateResource(resource *domain.Resource) error {
-	result, err := s.db.Exec("INSERT INTO resources (name) VALUES (?)", resource.Name)
+	result, err := s.db.Exec("INSERT INTO resources (name, recipe_id) VALUES (?, ?)", resource.Name, resource.RecipeID)
 	if err != nil {
 		return err
 	}
@@ -332,7 +223,10 @@ func (s *SQLiteDB) CreateResource(resource *domain.Resource) error {
 }
 
 func (s *SQLiteDB) UpdateResource(resource *domain.Resource) error {
-	_, err := s.db.Exec("UPDATE resources SET name = ? WHERE id = ?", resource.Name, resource.ID)
+	_, err := s.db.Exec(
+		"UPDATE resources SET name = ?, recipe_id = ? WHERE id = ?",
+		resource.Name, resource.RecipeID, resource.ID,
+	)
 	return err
 }
 
@@ -363,6 +257,111 @@ func (s *SQLiteDB) DeleteRecipeResource(recipeID, resourceID int) error {
 	return err
 }
 
+// GetInventory retrieves the resources currently held by the player
+func (s *SQLiteDB) GetInventory() ([]domain.InventoryItem, error) {
+	query := `
+		SELECT i.id, i.resource_id, r.name, i.quantity
+		FROM inventory i
+		JOIN resources r ON r.id = i.resource_id
+		ORDER BY r.name
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.InventoryItem
+	for rows.Next() {
+		var item domain.InventoryItem
+		if err := rows.Scan(&item.ID, &item.ResourceID, &item.ResourceName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// UpdateInventory sets the quantity of each item in one transaction, removing
+// the items held at zero
+func (s *SQLiteDB) UpdateInventory(items []domain.InventoryItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if item.Quantity == 0 {
+			if _, err := tx.Exec("DELETE FROM inventory WHERE resource_id = ?", item.ResourceID); err != nil {
+				return fmt.Errorf("failed to clear resource %d: %w", item.ResourceID, err)
+			}
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO inventory (resource_id, quantity) VALUES (?, ?)
+			ON CONFLICT(resource_id) DO UPDATE SET quantity = excluded.quantity
+		`, item.ResourceID, item.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to update resource %d: %w", item.ResourceID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteDB) DeleteInventoryItem(resourceID int) error {
+	_, err := s.db.Exec("DELETE FROM inventory WHERE resource_id = ?", resourceID)
+	return err
+}
+
+// GetAllStations retrieves all crafting stations
+func (s *SQLiteDB) GetAllStations() ([]domain.CraftingStation, error) {
+	rows, err := s.db.Query("SELECT id, name, recipe_id, built FROM crafting_stations ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []domain.CraftingStation
+	for rows.Next() {
+		var station domain.CraftingStation
+		var recipeID sql.NullInt64
+		if err := rows.Scan(&station.ID, &station.Name, &recipeID, &station.Built); err != nil {
+			return nil, err
+		}
+		station.RecipeID = nullIntPtr(recipeID)
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
+
+// SetStationBuilt records whether the player has built a crafting station
+func (s *SQLiteDB) SetStationBuilt(id int, built bool) error {
+	result, err := s.db.Exec("UPDATE crafting_stations SET built = ? WHERE id = ?", built, id)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// nullIntPtr converts a nullable integer column to an optional int
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
 // Admin interface methods
 func (s *SQLiteDB) GetTables() ([]string, error) {
 	rows, err := s.db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'")
@@ -384,7 +383,7 @@ func (s *SQLiteDB) GetTables() ([]string, error) {
 }
 
 func (s *SQLiteDB) GetTableInfo(tableName string) (*domain.TableInfo, error) {
-	query := fmt.Sprintf("PRAGMA table_info(%s)", tableName)
+	query := fmt.Sprintf("PRAGMA table_info(%s)", quoteIdentifier(tableName))
 	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -392,10 +391,12 @@ func (s *SQLiteDB) GetTableInfo(tableName string) (*domain.TableInfo, error) {
 	defer rows.Close()
 
 	var columns []domain.ColumnInfo
+	// pk is the position of the column in the primary key, 0 outside of it
+	keyColumns := make(map[int]string)
 	for rows.Next() {
-		var cid int
+		var cid, pk int
 		var name, dataType, defaultValue sql.NullString
-		var notNull, pk bool
+		var notNull bool
 
 		err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk)
 		if err != nil {
@@ -407,24 +408,326 @@ func (s *SQLiteDB) GetTableInfo(tableName string) (*domain.TableInfo, error) {
 			Type:         dataType.String,
 			NotNull:      notNull,
 			DefaultValue: defaultValue.String,
-			PrimaryKey:   pk,
+			PrimaryKey:   pk > 0,
 		}
 		columns = append(columns, column)
+		if pk > 0 {
+			keyColumns[pk] = name.String
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rowKey := make([]string, len(keyColumns))
+	for pk, name := range keyColumns {
+		rowKey[pk-1] = name
+	}
+	if len(rowKey) == 0 {
+		rowKey = []string{rowIDAlias(columns)}
+	}
+
+	foreignKeys, err := s.getForeignKeys(tableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := s.getIndexes(tableName)
+	if err != nil {
+		return nil, err
 	}
 
 	return &domain.TableInfo{
-		Name:    tableName,
-		Columns: columns,
+		Name:        tableName,
+		Columns:     columns,
+		RowKey:      rowKey,
+		ForeignKeys: foreignKeys,
+		Indexes:     indexes,
 	}, nil
 }
 
-func (s *SQLiteDB) ExecuteQuery(query string) ([]map[string]interface{}, error) {
+// rowIDAlias returns a name of the rowid that no column of the table hides
+func rowIDAlias(columns []domain.ColumnInfo) string {
+	for _, alias := range []string{"rowid", "_rowid_", "oid"} {
+		hidden := false
+		for _, column := range columns {
+			if strings.EqualFold(column.Name, alias) {
+				hidden = true
+			}
+		}
+		if !hidden {
+			return alias
+		}
+	}
+	return "rowid"
+}
+
+// getForeignKeys reads the foreign keys of a table. Keys declared without
+// parent columns reference the primary key of the parent table.
+func (s *SQLiteDB) getForeignKeys(tableName string) ([]domain.ForeignKeyInfo, error) {
+	query := fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteIdentifier(tableName))
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foreignKeys := []domain.ForeignKeyInfo{}
+	for rows.Next() {
+		var id, seq int
+		var parent, from, onUpdate, onDelete, match string
+		var to sql.NullString
+		if err := rows.Scan(&id, &seq, &parent, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+
+		// The columns of a key come in order, one row each
+		if seq == 0 {
+			foreignKeys = append(foreignKeys, domain.ForeignKeyInfo{
+				ID:       id,
+				Table:    parent,
+				OnUpdate: onUpdate,
+				OnDelete: onDelete,
+			})
+		}
+		key := &foreignKeys[len(foreignKeys)-1]
+		key.Columns = append(key.Columns, from)
+		key.References = append(key.References, to.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range foreignKeys {
+		key := &foreignKeys[i]
+		if key.References[0] != "" {
+			continue
+		}
+		parent, err := s.primaryKey(key.Table)
+		if err != nil {
+			return nil, err
+		}
+		if len(parent) == len(key.Columns) {
+			key.References = parent
+		}
+	}
+
+	return foreignKeys, nil
+}
+
+// primaryKey returns the primary key columns of a table, in key order
+func (s *SQLiteDB) primaryKey(tableName string) ([]string, error) {
+	rows, err := s.db.Query("SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// getIndexes reads the indexes of a table with their columns, including the
+// ones SQLite creates for UNIQUE and PRIMARY KEY constraints
+func (s *SQLiteDB) getIndexes(tableName string) ([]domain.IndexInfo, error) {
+	query := fmt.Sprintf("PRAGMA index_list(%s)", quoteIdentifier(tableName))
 	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	indexes := []domain.IndexInfo{}
+	for rows.Next() {
+		var seq int
+		var index domain.IndexInfo
+		if err := rows.Scan(&seq, &index.Name, &index.Unique, &index.Origin, &index.Partial); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range indexes {
+		columnRows, err := s.db.Query("SELECT name FROM pragma_index_info(?) ORDER BY seqno", indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes[i].Columns = []string{}
+		for columnRows.Next() {
+			// Columns indexed through an expression have no name
+			var column sql.NullString
+			if err := columnRows.Scan(&column); err != nil {
+				columnRows.Close()
+				return nil, err
+			}
+			indexes[i].Columns = append(indexes[i].Columns, column.String)
+		}
+		err = columnRows.Err()
+		columnRows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return indexes, nil
+}
+
+func (s *SQLiteDB) ExecuteQuery(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return executeQuery(context.Background(), s.db, query, args...)
+}
+
+// ExecuteQueryContext runs a query, interrupting it when ctx is done
+func (s *SQLiteDB) ExecuteQueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return executeQuery(ctx, s.db, query, args...)
+}
+
+// ExecuteReadOnlyQuery runs a query on a connection where SQLite refuses any
+// change to the database, returning domain.ErrForbidden when it tries one.
+// The query is interrupted when ctx is done.
+func (s *SQLiteDB) ExecuteReadOnlyQuery(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return nil, fmt.Errorf("failed to make connection read-only: %w", err)
+	}
+	defer func() {
+		// ctx may be done by now, and the connection must be reset anyway.
+		// Drop the connection rather than return it read-only to the pool.
+		if _, err := conn.ExecContext(context.Background(), "PRAGMA query_only = OFF"); err != nil {
+			dropConn(conn)
+		}
+	}()
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, readOnlyError(err)
+	}
+	defer rows.Close()
+
+	results, err := scanRows(rows)
+	if err != nil {
+		return nil, readOnlyError(err)
+	}
+	return results, nil
+}
+
+// StatementTables compiles a statement with EXPLAIN and maps the b-trees its
+// program opens back to their tables. Quoted names, schema prefixes and views
+// all resolve to the tables actually read, as they would for an authorizer.
+// Only tables of the main database are reported.
+func (s *SQLiteDB) StatementTables(ctx context.Context, statement string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "EXPLAIN "+statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Root pages of the tables and indexes opened in the main database
+	roots := make(map[int64]bool)
+	for rows.Next() {
+		var addr, p1, p2, p3, p5 int64
+		var opcode string
+		var p4, comment interface{}
+		if err := rows.Scan(&addr, &opcode, &p1, &p2, &p3, &p4, &p5, &comment); err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case "OpenRead", "OpenWrite", "ReopenIdx":
+			if p3 == 0 {
+				roots[p2] = true
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return []string{}, nil
+	}
+
+	schemaRows, err := s.db.QueryContext(ctx, "SELECT tbl_name, rootpage FROM main.sqlite_schema WHERE rootpage > 0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer schemaRows.Close()
+
+	tables := []string{}
+	seen := make(map[string]bool)
+	for schemaRows.Next() {
+		var table string
+		var rootPage int64
+		if err := schemaRows.Scan(&table, &rootPage); err != nil {
+			return nil, err
+		}
+		if roots[rootPage] && !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	return tables, schemaRows.Err()
+}
+
+// QueryEach runs a query and calls fn with the values of each row in column
+// order, without holding every row in memory. Unlike ExecuteQuery, it keeps
+// blobs as []byte so they can be told apart from text.
+func (s *SQLiteDB) QueryEach(query string, args []interface{}, fn func(values []interface{}) error) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return err
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// readOnlyError flags the errors of statements refused by query_only
+func readOnlyError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_READONLY {
+		return fmt.Errorf("%w: query tried to change the database: %v", domain.ErrForbidden, err)
+	}
+	return err
+}
+
+// scanRows reads every row as a map of column names to values
+func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -456,28 +759,240 @@ func (s *SQLiteDB) ExecuteQuery(query string) ([]map[string]interface{}, error)
 		results = append(results, row)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
 func (s *SQLiteDB) ExecuteNonQuery(query string, args ...interface{}) error {
-	result, err := s.db.Exec(query, args...)
+	return executeNonQuery(s.db, query, args...)
+}
+
+// ExecuteInsert runs an INSERT statement and returns the rowid of the new row
+func (s *SQLiteDB) ExecuteInsert(query string, args ...interface{}) (int64, error) {
+	return executeInsert(s.db, query, args...)
+}
+
+// InTransaction runs fn with statements inside one transaction, committed if
+// fn returns nil and rolled back otherwise
+func (s *SQLiteDB) InTransaction(fn func(tx ports.AdminTransaction) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(sqliteTx{tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", foreignKeyError(err))
+	}
+	return nil
+}
+
+// sqliteTx runs the statements of the admin table editor and records their
+// audit entries inside a transaction
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t sqliteTx) ExecuteQuery(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return executeQuery(context.Background(), t.tx, query, args...)
+}
+
+func (t sqliteTx) ExecuteNonQuery(query string, args ...interface{}) error {
+	return executeNonQuery(t.tx, query, args...)
+}
+
+func (t sqliteTx) ExecuteInsert(query string, args ...interface{}) (int64, error) {
+	return executeInsert(t.tx, query, args...)
+}
+
+func (t sqliteTx) CreateAuditEntry(entry *domain.AuditEntry) error {
+	return createAuditEntry(t.tx, entry)
+}
+
+// runner is the part of *sql.DB and *sql.Tx running statements
+type runner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func executeQuery(ctx context.Context, db runner, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to execute statement: %w", err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	if rowsAffected, err := result.RowsAffected(); err == nil {
-		fmt.Printf("Query executed successfully, rows affected: %d\n", rowsAffected)
+	return scanRows(rows)
+}
+
+func executeNonQuery(db runner, query string, args ...interface{}) error {
+	if _, err := db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to execute statement: %w", foreignKeyError(err))
 	}
 	return nil
 }
 
+func executeInsert(db runner, query string, args ...interface{}) (int64, error) {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute statement: %w", foreignKeyError(err))
+	}
+	return result.LastInsertId()
+}
+
+// foreignKeyError reports a write breaking a foreign key as a conflict with
+// the rows of the other table
+func foreignKeyError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY {
+		return fmt.Errorf("%w: %w", domain.ErrConflict, err)
+	}
+	return err
+}
+
 func (s *SQLiteDB) CreateTable(query string) error {
 	_, err := s.db.Exec(query)
 	return err
 }
 
 func (s *SQLiteDB) DropTable(tableName string) error {
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdentifier(tableName))
 	_, err := s.db.Exec(query)
 	return err
 }
+
+// GetTableSQL reads the statements SQLite stored for a table and for its
+// explicit indexes and triggers
+func (s *SQLiteDB) GetTableSQL(tableName string) (*domain.TableSQL, error) {
+	rows, err := s.db.Query("SELECT type, name, sql FROM sqlite_master WHERE tbl_name = ? AND sql IS NOT NULL ORDER BY rowid", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tableSQL := &domain.TableSQL{}
+	for rows.Next() {
+		var objectType string
+		var object domain.SchemaObject
+		if err := rows.Scan(&objectType, &object.Name, &object.SQL); err != nil {
+			return nil, err
+		}
+
+		switch objectType {
+		case "table":
+			tableSQL.Create = object.SQL
+		case "index":
+			tableSQL.Indexes = append(tableSQL.Indexes, object)
+		case "trigger":
+			tableSQL.Triggers = append(tableSQL.Triggers, object)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if tableSQL.Create == "" {
+		return nil, fmt.Errorf("%w: table %s does not exist", domain.ErrNotFound, tableName)
+	}
+	return tableSQL, nil
+}
+
+// ApplySchemaChange runs schema statements changing a table in a single
+// transaction. Table rebuilds run with foreign keys off and legacy_alter_table
+// on, as the SQLite rebuild procedure requires, and are rolled back if they
+// leave rows of the rebuilt table or of the tables referencing it breaking a
+// foreign key.
+func (s *SQLiteDB) ApplySchemaChange(tableName string, statements []string, rebuild bool) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if rebuild {
+		restore, err := disableForeignKeys(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer restore()
+		if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = ON"); err != nil {
+			return fmt.Errorf("failed to enable legacy_alter_table: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = OFF"); err != nil {
+				dropConn(conn)
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to run %s: %w", statement, err)
+		}
+	}
+
+	if rebuild {
+		if err := checkRebuildForeignKeys(tx, tableName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkRebuildForeignKeys looks for rows breaking a foreign key in a rebuilt
+// table and in the tables referencing it. Violations in other tables predate
+// the rebuild and don't block it.
+func checkRebuildForeignKeys(tx *sql.Tx, tableName string) error {
+	rows, err := tx.Query(`
+		SELECT DISTINCT m.name
+		FROM sqlite_master m, pragma_foreign_key_list(m.name) fk
+		WHERE m.type = 'table' AND fk."table" = ? COLLATE NOCASE AND m.name != ? COLLATE NOCASE
+	`, tableName, tableName)
+	if err != nil {
+		return fmt.Errorf("failed to list tables referencing %s: %w", tableName, err)
+	}
+	tables := []string{tableName}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		rows, err := tx.Query(fmt.Sprintf("PRAGMA foreign_key_check(%s)", quoteIdentifier(table)))
+		if err != nil {
+			return fmt.Errorf("failed to check foreign keys of %s: %w", table, err)
+		}
+		violation := rows.Next()
+		rows.Close()
+		if violation {
+			return fmt.Errorf("%w: the change leaves rows of %s breaking a foreign key", domain.ErrConflict, table)
+		}
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a table or column name for use in SQL. The services
+// and the database adapter each have a copy, as the core imports no adapter;
+// keep it in step with the one in internal/core/services/admin_sql.go.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// defaultAuditLimit caps the entries returned when the filter has no limit
+const defaultAuditLimit = 100
+
+// CreateAuditEntry appends an entry to the audit log
+func (s *SQLiteDB) CreateAuditEntry(entry *domain.AuditEntry) error {
+	return createAuditEntry(s.db, entry)
+}
+
+func createAuditEntry(db runner, entry *domain.AuditEntry) error {
+	result, err := db.Exec(`
+		INSERT INTO audit_log (user_id, username, action, table_name, row_id, query, before_data, after_data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.UserID, entry.Username, entry.Action, entry.TableName, entry.RowID, entry.Query,
+		nullJSON(entry.Before), nullJSON(entry.After),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+// GetAuditEntries retrieves the audit entries matching a filter, newest first
+func (s *SQLiteDB) GetAuditEntries(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if filter.Username != "" {
+		where("username = ? COLLATE NOCASE", filter.Username)
+	}
+	if filter.Action != "" {
+		where("action = ?", filter.Action)
+	}
+	if filter.TableName != "" {
+		where("table_name = ?", filter.TableName)
+	}
+	if filter.RowID != "" {
+		where("row_id = ?", filter.RowID)
+	}
+	if filter.Since != "" {
+		where("created_at >= ?", filter.Since)
+	}
+	if filter.Until != "" {
+		where("created_at < ?", filter.Until)
+	}
+
+	query := `
+		SELECT id, user_id, username, action, table_name, row_id, query,
+		       COALESCE(before_data, ''), COALESCE(after_data, ''), created_at
+		FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var userID sql.NullInt64
+		var before, after string
+		err := rows.Scan(
+			&entry.ID, &userID, &entry.Username, &entry.Action, &entry.TableName, &entry.RowID,
+			&entry.Query, &before, &after, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.UserID = nullIntPtr(userID)
+		if before != "" {
+			entry.Before = []byte(before)
+		}
+		if after != "" {
+			entry.After = []byte(after)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// nullJSON stores empty snapshots as NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
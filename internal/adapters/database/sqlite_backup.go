@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// restoreAttempts bounds how many times a restore waits for other connections
+// to release the database
+const restoreAttempts = 50
+
+// BackupTo writes a consistent snapshot of the live database to a new file
+func (s *SQLiteDB) BackupTo(path string) error {
+	if _, err := s.db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// CheckBackup opens a database file read-only and checks it passes the
+// SQLite integrity check and holds a schema this version can migrate
+func (s *SQLiteDB) CheckBackup(path string) error {
+	db, err := sql.Open("sqlite", "file:"+url.PathEscape(path)+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_NOTADB {
+			return fmt.Errorf("%w: file is not a SQLite database", domain.ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to check backup: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: backup failed the integrity check: %s", domain.ErrInvalidInput, result)
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return fmt.Errorf("%w: file is not a Palworld Helper database", domain.ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to read backup schema version: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if latest := migrations[len(migrations)-1].Version; int(version.Int64) > latest {
+		return fmt.Errorf("%w: backup has schema version %d, newer than the %d this server knows", domain.ErrInvalidInput, version.Int64, latest)
+	}
+
+	return nil
+}
+
+// RestoreFrom copies a database file over the live database with the SQLite
+// backup API, so open connections see either the old or the new database and
+// never a mix, then migrates it to the current schema
+func (s *SQLiteDB) RestoreFrom(path string) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(srcUri string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("the SQLite driver cannot restore backups")
+		}
+
+		restore, err := restorer.NewRestore(path)
+		if err != nil {
+			return err
+		}
+		for attempt := 1; ; attempt++ {
+			more, err := restore.Step(-1)
+			if err == nil && !more {
+				break
+			}
+			if err != nil && (!isBusy(err) || attempt == restoreAttempts) {
+				restore.Finish()
+				return err
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return restore.Finish()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	if err := s.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate restored database: %w", err)
+	}
+	return nil
+}
+
+// isBusy tells whether SQLite failed because another connection holds a lock
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
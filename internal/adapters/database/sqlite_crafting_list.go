@@ -0,0 +1,175 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// GetAllCraftingLists retrieves every saved crafting list with its items
+func (s *SQLiteDB) GetAllCraftingLists() ([]domain.CraftingList, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, COALESCE(game_version, ''), created_at, updated_at FROM crafting_lists ORDER BY name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []domain.CraftingList
+	for rows.Next() {
+		var list domain.CraftingList
+		if err := rows.Scan(&list.ID, &list.Name, &list.GameVersion, &list.CreatedAt, &list.UpdatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range lists {
+		items, err := s.getCraftingListItems(lists[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		lists[i].Items = items
+	}
+
+	return lists, nil
+}
+
+// GetCraftingListByID retrieves a specific crafting list by ID
+func (s *SQLiteDB) GetCraftingListByID(id int) (*domain.CraftingList, error) {
+	var list domain.CraftingList
+	err := s.db.QueryRow(
+		"SELECT id, name, COALESCE(game_version, ''), created_at, updated_at FROM crafting_lists WHERE id = ?", id,
+	).Scan(&list.ID, &list.Name, &list.GameVersion, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	items, err := s.getCraftingListItems(list.ID)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = items
+
+	return &list, nil
+}
+
+func (s *SQLiteDB) getCraftingListItems(listID int) ([]domain.CraftingItem, error) {
+	query := `
+		SELECT cli.recipe_id, cr.name, cli.quantity
+		FROM crafting_list_items cli
+		JOIN crafting_recipes cr ON cr.id = cli.recipe_id
+		WHERE cli.list_id = ?
+		ORDER BY cli.id
+	`
+
+	rows, err := s.db.Query(query, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.CraftingItem{}
+	for rows.Next() {
+		var item domain.CraftingItem
+		if err := rows.Scan(&item.ID, &item.Name, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// CreateCraftingList saves a new crafting list and its items
+func (s *SQLiteDB) CreateCraftingList(list *domain.CraftingList) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO crafting_lists (name, game_version) VALUES (?, NULLIF(?, ''))",
+		list.Name, list.GameVersion,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	if err := insertCraftingListItems(tx, int(id), list.Items); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	list.ID = int(id)
+	return nil
+}
+
+// UpdateCraftingList renames a crafting list, replaces its items and records
+// the game version it was planned for
+func (s *SQLiteDB) UpdateCraftingList(list *domain.CraftingList) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE crafting_lists SET name = ?, game_version = NULLIF(?, ''), updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		list.Name, list.GameVersion, list.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM crafting_list_items WHERE list_id = ?", list.ID); err != nil {
+		return err
+	}
+	if err := insertCraftingListItems(tx, list.ID, list.Items); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertCraftingListItems(tx *sql.Tx, listID int, items []domain.CraftingItem) error {
+	for _, item := range items {
+		_, err := tx.Exec(`
+			INSERT INTO crafting_list_items (list_id, recipe_id, quantity) VALUES (?, ?, ?)
+			ON CONFLICT(list_id, recipe_id) DO UPDATE SET quantity = quantity + excluded.quantity
+		`, listID, item.ID, item.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to save item %d: %w", item.ID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteCraftingList deletes a crafting list, its items going with it by ON
+// DELETE CASCADE
+func (s *SQLiteDB) DeleteCraftingList(id int) error {
+	result, err := s.db.Exec("DELETE FROM crafting_lists WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,441 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// GetDatasetVersion retrieves the version and load time of the last dataset
+// loaded from the given source
+func (s *SQLiteDB) GetDatasetVersion(source string) (string, string, error) {
+	var version, loadedAt string
+	err := s.db.QueryRow(
+		"SELECT version, COALESCE(loaded_at, '') FROM dataset_loads WHERE source = ? ORDER BY id DESC LIMIT 1",
+		source,
+	).Scan(&version, &loadedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	return version, loadedAt, nil
+}
+
+// UpsertDataset inserts or updates every record of the dataset in a single
+// transaction, matching by blueprint ID first and by name otherwise. Empty
+// categories, descriptions and stations keep their current value. Records
+// missing from the dataset are left in the database, as is player state such
+// as inventory, built stations and unlocked technologies, but are not part
+// of the snapshot of its game version.
+func (s *SQLiteDB) UpsertDataset(dataset *domain.Dataset) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, resource := range dataset.Resources {
+		if err := upsertResource(tx, resource, dataset.GameVersion); err != nil {
+			return err
+		}
+	}
+
+	for _, station := range dataset.Stations {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO crafting_stations (name) VALUES (?)", station.Name); err != nil {
+			return fmt.Errorf("failed to upsert station %s: %w", station.Name, err)
+		}
+	}
+
+	for _, recipe := range dataset.Recipes {
+		if err := upsertRecipe(tx, recipe, dataset.GameVersion); err != nil {
+			return err
+		}
+	}
+
+	// Stations are built from recipes, so they are linked once every recipe exists
+	for _, station := range dataset.Stations {
+		_, err := tx.Exec(
+			"UPDATE crafting_stations SET recipe_id = (SELECT id FROM crafting_recipes WHERE name = ?) WHERE name = ?",
+			station.Recipe, station.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to link station %s: %w", station.Name, err)
+		}
+	}
+
+	// Link intermediate resources to the recipe producing them
+	_, err = tx.Exec(`
+		UPDATE resources
+		SET recipe_id = (SELECT cr.id FROM crafting_recipes cr WHERE cr.name = resources.name)
+		WHERE recipe_id IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to link resources to recipes: %w", err)
+	}
+
+	for _, technology := range dataset.Technologies {
+		if err := upsertTechnology(tx, technology, dataset.GameVersion); err != nil {
+			return err
+		}
+	}
+
+	for _, pal := range dataset.Pals {
+		if err := upsertPal(tx, pal); err != nil {
+			return err
+		}
+	}
+
+	for _, combination := range dataset.Breeding {
+		if err := upsertBreeding(tx, combination); err != nil {
+			return err
+		}
+	}
+
+	if dataset.GameVersion != "" {
+		if err := recordGameVersion(tx, dataset); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO dataset_loads (version, source, game_version) VALUES (?, ?, ?)",
+		dataset.Version, dataset.Source, dataset.GameVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record dataset version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// recordGameVersion snapshots the recipes with their ingredients, the
+// resources and the technologies of the dataset under its game version. Only
+// the records of the dataset are recorded: records an earlier dataset loaded
+// stay in the database but not in this version, so diffs report them removed.
+func recordGameVersion(tx *sql.Tx, dataset *domain.Dataset) error {
+	gameVersion := dataset.GameVersion
+	_, err := tx.Exec("INSERT OR IGNORE INTO game_versions (version) VALUES (?)", gameVersion)
+	if err != nil {
+		return fmt.Errorf("failed to record game version %s: %w", gameVersion, err)
+	}
+
+	for _, table := range []string{"recipe_versions", "resource_versions", "technology_versions"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE game_version = ?", gameVersion); err != nil {
+			return fmt.Errorf("failed to clear %s of game version %s: %w", table, gameVersion, err)
+		}
+	}
+
+	recordResource := func(name string) error {
+		_, err := tx.Exec("INSERT OR IGNORE INTO resource_versions (game_version, resource_name) VALUES (?, ?)", gameVersion, name)
+		if err != nil {
+			return fmt.Errorf("failed to record resource %s of game version %s: %w", name, gameVersion, err)
+		}
+		return nil
+	}
+
+	for _, resource := range dataset.Resources {
+		if err := recordResource(resource.Name); err != nil {
+			return err
+		}
+	}
+
+	// Recipes without ingredients are recorded with an empty resource name
+	for _, recipe := range dataset.Recipes {
+		ingredients := recipe.Resources
+		if len(ingredients) == 0 {
+			ingredients = map[string]int{"": 0}
+		}
+		for resourceName, quantity := range ingredients {
+			_, err := tx.Exec(
+				"INSERT OR IGNORE INTO recipe_versions (game_version, recipe_name, resource_name, quantity) VALUES (?, ?, ?, ?)",
+				gameVersion, recipe.Name, resourceName, quantity,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record recipe %s of game version %s: %w", recipe.Name, gameVersion, err)
+			}
+			if resourceName == "" {
+				continue
+			}
+			if err := recordResource(resourceName); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, technology := range dataset.Technologies {
+		_, err := tx.Exec(
+			"INSERT OR IGNORE INTO technology_versions (game_version, technology_name) VALUES (?, ?)",
+			gameVersion, technology.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record technology %s of game version %s: %w", technology.Name, gameVersion, err)
+		}
+	}
+
+	return nil
+}
+
+// upsertResource updates the matching resource or inserts it
+func upsertResource(tx *sql.Tx, resource domain.DatasetResource, gameVersion string) error {
+	resourceID, err := findID(tx, "resources", resource.BlueprintID, resource.Name)
+	if err != nil {
+		return err
+	}
+
+	if resourceID == 0 {
+		_, err = tx.Exec(
+			"INSERT INTO resources (name, blueprint_id, game_version) VALUES (?, NULLIF(?, ''), NULLIF(?, ''))",
+			resource.Name, resource.BlueprintID, gameVersion,
+		)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE resources
+			SET name = ?,
+				blueprint_id = COALESCE(NULLIF(?, ''), blueprint_id),
+				game_version = COALESCE(NULLIF(?, ''), game_version)
+			WHERE id = ?
+		`, resource.Name, resource.BlueprintID, gameVersion, resourceID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upsert resource %s: %w", resource.Name, err)
+	}
+	return nil
+}
+
+// upsertRecipe updates the matching recipe or inserts it, then replaces its
+// ingredients
+func upsertRecipe(tx *sql.Tx, recipe domain.DatasetRecipe, gameVersion string) error {
+	var stationID sql.NullInt64
+	if recipe.Station != "" {
+		err := tx.QueryRow("SELECT id FROM crafting_stations WHERE name = ?", recipe.Station).Scan(&stationID)
+		if err != nil {
+			return fmt.Errorf("failed to find station %s for %s: %w", recipe.Station, recipe.Name, err)
+		}
+	}
+
+	recipeID, err := findID(tx, "crafting_recipes", recipe.BlueprintID, recipe.Name)
+	if err != nil {
+		return err
+	}
+
+	if recipeID == 0 {
+		result, err := tx.Exec(`
+			INSERT INTO crafting_recipes (name, blueprint_id, category, description, station_id, yield, work_amount, game_version)
+			VALUES (?, NULLIF(?, ''), ?, ?, ?, ?, ?, NULLIF(?, ''))
+		`, recipe.Name, recipe.BlueprintID, recipe.Category, recipe.Description, stationID,
+			max(recipe.Yield, 1), recipe.WorkAmount, gameVersion)
+		if err != nil {
+			return fmt.Errorf("failed to insert recipe %s: %w", recipe.Name, err)
+		}
+		recipeID, _ = result.LastInsertId()
+	} else {
+		_, err := tx.Exec(`
+			UPDATE crafting_recipes
+			SET name = ?,
+				blueprint_id = COALESCE(NULLIF(?, ''), blueprint_id),
+				category = COALESCE(NULLIF(?, ''), category),
+				description = COALESCE(NULLIF(?, ''), description),
+				station_id = COALESCE(?, station_id),
+				yield = ?,
+				work_amount = ?,
+				game_version = COALESCE(NULLIF(?, ''), game_version)
+			WHERE id = ?
+		`, recipe.Name, recipe.BlueprintID, recipe.Category, recipe.Description, stationID,
+			max(recipe.Yield, 1), recipe.WorkAmount, gameVersion, recipeID)
+		if err != nil {
+			return fmt.Errorf("failed to update recipe %s: %w", recipe.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM recipe_resources WHERE recipe_id = ?", recipeID); err != nil {
+		return fmt.Errorf("failed to clear ingredients of %s: %w", recipe.Name, err)
+	}
+
+	for resourceName, quantity := range recipe.Resources {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO resources (name) VALUES (?)", resourceName); err != nil {
+			return fmt.Errorf("failed to upsert resource %s: %w", resourceName, err)
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO recipe_resources (recipe_id, resource_id, quantity)
+			SELECT ?, id, ? FROM resources WHERE name = ?
+		`, recipeID, quantity, resourceName)
+		if err != nil {
+			return fmt.Errorf("failed to insert ingredient %s of %s: %w", resourceName, recipe.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// upsertTechnology updates the matching technology or inserts it, then
+// replaces the recipes it unlocks
+func upsertTechnology(tx *sql.Tx, technology domain.DatasetTechnology, gameVersion string) error {
+	technologyID, err := findID(tx, "technologies", technology.BlueprintID, technology.Name)
+	if err != nil {
+		return err
+	}
+
+	if technologyID == 0 {
+		result, err := tx.Exec(
+			"INSERT INTO technologies (name, blueprint_id, level, points, game_version) VALUES (?, NULLIF(?, ''), ?, ?, NULLIF(?, ''))",
+			technology.Name, technology.BlueprintID, technology.Level, max(technology.Points, 1), gameVersion,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert technology %s: %w", technology.Name, err)
+		}
+		technologyID, _ = result.LastInsertId()
+	} else {
+		_, err := tx.Exec(`
+			UPDATE technologies
+			SET name = ?,
+				blueprint_id = COALESCE(NULLIF(?, ''), blueprint_id),
+				level = ?,
+				points = ?,
+				game_version = COALESCE(NULLIF(?, ''), game_version)
+			WHERE id = ?
+		`, technology.Name, technology.BlueprintID, technology.Level, max(technology.Points, 1), gameVersion, technologyID)
+		if err != nil {
+			return fmt.Errorf("failed to update technology %s: %w", technology.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM technology_recipes WHERE technology_id = ?", technologyID); err != nil {
+		return fmt.Errorf("failed to clear recipes of %s: %w", technology.Name, err)
+	}
+
+	for _, recipeName := range technology.Recipes {
+		_, err := tx.Exec(`
+			INSERT INTO technology_recipes (technology_id, recipe_id)
+			SELECT ?, id FROM crafting_recipes WHERE name = ?
+		`, technologyID, recipeName)
+		if err != nil {
+			return fmt.Errorf("failed to link %s to %s: %w", recipeName, technology.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// upsertPal updates the pal with the same name or inserts it, then replaces
+// its elements, work suitabilities and drops
+func upsertPal(tx *sql.Tx, pal domain.DatasetPal) error {
+	_, err := tx.Exec(`
+		INSERT INTO pals (paldeck_number, variant, name, description, breeding_power) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			paldeck_number = excluded.paldeck_number,
+			variant = excluded.variant,
+			description = excluded.description,
+			breeding_power = excluded.breeding_power
+	`, pal.PaldeckNumber, pal.Variant, pal.Name, pal.Description, pal.BreedingPower)
+	if err != nil {
+		return fmt.Errorf("failed to upsert pal %s: %w", pal.Name, err)
+	}
+
+	palID, err := idByName(tx, "pals", pal.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"pal_elements", "pal_work_suitabilities", "pal_drops"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE pal_id = ?", palID); err != nil {
+			return fmt.Errorf("failed to clear %s of %s: %w", table, pal.Name, err)
+		}
+	}
+
+	for _, element := range pal.Elements {
+		if _, err := tx.Exec("INSERT INTO pal_elements (pal_id, element) VALUES (?, ?)", palID, element); err != nil {
+			return fmt.Errorf("failed to insert element of %s: %w", pal.Name, err)
+		}
+	}
+
+	for workType, level := range pal.WorkSuitabilities {
+		_, err := tx.Exec(
+			"INSERT INTO pal_work_suitabilities (pal_id, work_type, level) VALUES (?, ?, ?)",
+			palID, workType, level,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert work suitability of %s: %w", pal.Name, err)
+		}
+	}
+
+	for _, drop := range pal.Drops {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO resources (name) VALUES (?)", drop.Resource); err != nil {
+			return fmt.Errorf("failed to upsert resource %s: %w", drop.Resource, err)
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO pal_drops (pal_id, resource_id, min_quantity, max_quantity, drop_rate)
+			SELECT ?, id, ?, ?, ? FROM resources WHERE name = ?
+		`, palID, drop.MinQuantity, drop.MaxQuantity, drop.DropRate, drop.Resource)
+		if err != nil {
+			return fmt.Errorf("failed to insert drop of %s: %w", pal.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// upsertBreeding sets the child of a special breeding combination. The parents
+// are stored in id order, the order the breeding service looks them up in.
+func upsertBreeding(tx *sql.Tx, combination domain.DatasetBreeding) error {
+	ids := make([]int64, 3)
+	for i, name := range []string{combination.Parent1, combination.Parent2, combination.Child} {
+		id, err := idByName(tx, "pals", name)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			return fmt.Errorf("failed to upsert breeding combination %s + %s: unknown pal %s",
+				combination.Parent1, combination.Parent2, name)
+		}
+		ids[i] = id
+	}
+	parent1, parent2, child := min(ids[0], ids[1]), max(ids[0], ids[1]), ids[2]
+
+	// A combination entered by hand may list the parents the other way round
+	if _, err := tx.Exec("DELETE FROM breeding_combinations WHERE parent1_id = ? AND parent2_id = ?", parent2, parent1); err != nil {
+		return fmt.Errorf("failed to upsert breeding combination %s + %s: %w", combination.Parent1, combination.Parent2, err)
+	}
+	_, err := tx.Exec(`
+		INSERT INTO breeding_combinations (parent1_id, parent2_id, child_id) VALUES (?, ?, ?)
+		ON CONFLICT(parent1_id, parent2_id) DO UPDATE SET child_id = excluded.child_id
+	`, parent1, parent2, child)
+	if err != nil {
+		return fmt.Errorf("failed to upsert breeding combination %s + %s: %w", combination.Parent1, combination.Parent2, err)
+	}
+	return nil
+}
+
+// findID returns the id of the row with the given blueprint ID, or of the row
+// with the given name when no row has that blueprint ID yet, or zero if
+// neither matches
+func findID(tx *sql.Tx, table, blueprintID, name string) (int64, error) {
+	if blueprintID != "" {
+		id, err := lookupID(tx, "SELECT id FROM "+table+" WHERE blueprint_id = ?", blueprintID)
+		if err != nil || id != 0 {
+			return id, err
+		}
+	}
+	return lookupID(tx, "SELECT id FROM "+table+" WHERE name = ? ORDER BY id LIMIT 1", name)
+}
+
+// idByName returns the id of the row with the given name, or zero if there is none
+func idByName(tx *sql.Tx, table, name string) (int64, error) {
+	return findID(tx, table, "", name)
+}
+
+func lookupID(tx *sql.Tx, query, key string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(query, key).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to look up %s: %w", key, err)
+	}
+	return id, nil
+}
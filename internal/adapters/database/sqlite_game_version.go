@@ -0,0 +1,113 @@
+package database
+
+import (
+	"database/sql"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// GetGameVersions retrieves every recorded game version, oldest first
+func (s *SQLiteDB) GetGameVersions() ([]domain.GameVersion, error) {
+	rows, err := s.db.Query("SELECT version, COALESCE(recorded_at, '') FROM game_versions ORDER BY recorded_at, rowid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []domain.GameVersion
+	for rows.Next() {
+		var version domain.GameVersion
+		if err := rows.Scan(&version.Version, &version.RecordedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetCurrentGameVersion retrieves the game version of the last loaded data,
+// or an empty string if no data was tagged with a game version
+func (s *SQLiteDB) GetCurrentGameVersion() (string, error) {
+	var version string
+	err := s.db.QueryRow(
+		"SELECT game_version FROM dataset_loads WHERE game_version != '' ORDER BY id DESC LIMIT 1",
+	).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return version, nil
+}
+
+// GetVersionSnapshot retrieves the recipe ingredients, resources and
+// technologies recorded for a game version, or nil if the version is unknown
+func (s *SQLiteDB) GetVersionSnapshot(version string) (*domain.VersionSnapshot, error) {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM game_versions WHERE version = ?)", version).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	snapshot := &domain.VersionSnapshot{Recipes: make(map[string]map[string]int)}
+
+	rows, err := s.db.Query(
+		"SELECT recipe_name, resource_name, quantity FROM recipe_versions WHERE game_version = ?", version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipe, resource string
+		var quantity int
+		if err := rows.Scan(&recipe, &resource, &quantity); err != nil {
+			return nil, err
+		}
+		if snapshot.Recipes[recipe] == nil {
+			snapshot.Recipes[recipe] = make(map[string]int)
+		}
+		if resource != "" {
+			snapshot.Recipes[recipe][resource] = quantity
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	snapshot.Resources, err = s.versionNames("SELECT resource_name FROM resource_versions WHERE game_version = ?", version)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Technologies, err = s.versionNames("SELECT technology_name FROM technology_versions WHERE game_version = ?", version)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// versionNames reads the record names a query selects for a game version
+func (s *SQLiteDB) versionNames(query, version string) (map[string]bool, error) {
+	rows, err := s.db.Query(query, version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// GetAllPals retrieves every pal ordered by paldeck number
+func (s *SQLiteDB) GetAllPals() ([]domain.Pal, error) {
+	return s.SearchPals(domain.PalQuery{})
+}
+
+// GetPalByID retrieves a specific pal by ID
+func (s *SQLiteDB) GetPalByID(id int) (*domain.Pal, error) {
+	var pal domain.Pal
+	err := s.db.QueryRow(
+		"SELECT id, paldeck_number, variant, name, description, breeding_power FROM pals WHERE id = ?", id,
+	).Scan(&pal.ID, &pal.PaldeckNumber, &pal.Variant, &pal.Name, &pal.Description, &pal.BreedingPower)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pals := []domain.Pal{pal}
+	if err := s.loadPalDetails(pals); err != nil {
+		return nil, err
+	}
+
+	return &pals[0], nil
+}
+
+// SearchPals retrieves the pals matching every non-empty filter of the query
+func (s *SQLiteDB) SearchPals(query domain.PalQuery) ([]domain.Pal, error) {
+	var conditions []string
+	var args []interface{}
+
+	if query.Name != "" {
+		conditions = append(conditions, "p.name LIKE ?")
+		args = append(args, "%"+query.Name+"%")
+	}
+	if query.Element != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM pal_elements pe WHERE pe.pal_id = p.id AND pe.element = ? COLLATE NOCASE)")
+		args = append(args, query.Element)
+	}
+	if query.WorkType != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM pal_work_suitabilities pw
+			WHERE pw.pal_id = p.id AND pw.work_type = ? COLLATE NOCASE AND pw.level >= ?)`)
+		args = append(args, query.WorkType, max(query.MinWorkLevel, 1))
+	}
+	if query.Drop != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM pal_drops pd JOIN resources r ON r.id = pd.resource_id
+			WHERE pd.pal_id = p.id AND r.name LIKE ?)`)
+		args = append(args, "%"+query.Drop+"%")
+	}
+
+	sqlQuery := "SELECT p.id, p.paldeck_number, p.variant, p.name, p.description, p.breeding_power FROM pals p"
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY p.paldeck_number, p.variant"
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pals []domain.Pal
+	for rows.Next() {
+		var pal domain.Pal
+		if err := rows.Scan(&pal.ID, &pal.PaldeckNumber, &pal.Variant, &pal.Name, &pal.Description, &pal.BreedingPower); err != nil {
+			return nil, err
+		}
+		pals = append(pals, pal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPalDetails(pals); err != nil {
+		return nil, err
+	}
+
+	return pals, nil
+}
+
+// GetBreedingCombinations retrieves the special breeding combinations
+func (s *SQLiteDB) GetBreedingCombinations() ([]domain.BreedingCombination, error) {
+	rows, err := s.db.Query("SELECT parent1_id, parent2_id, child_id FROM breeding_combinations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var combinations []domain.BreedingCombination
+	for rows.Next() {
+		var combination domain.BreedingCombination
+		if err := rows.Scan(&combination.Parent1ID, &combination.Parent2ID, &combination.ChildID); err != nil {
+			return nil, err
+		}
+		combinations = append(combinations, combination)
+	}
+
+	return combinations, rows.Err()
+}
+
+// loadPalDetails attaches elements, work suitabilities and drops to the given pals
+func (s *SQLiteDB) loadPalDetails(pals []domain.Pal) error {
+	if len(pals) == 0 {
+		return nil
+	}
+
+	index := make(map[int]*domain.Pal)
+	for i := range pals {
+		pals[i].Elements = []string{}
+		pals[i].WorkSuitabilities = []domain.WorkSuitability{}
+		pals[i].Drops = []domain.PalDrop{}
+		index[pals[i].ID] = &pals[i]
+	}
+
+	rows, err := s.db.Query("SELECT pal_id, element FROM pal_elements ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var palID int
+		var element string
+		if err := rows.Scan(&palID, &element); err != nil {
+			return err
+		}
+		if pal, ok := index[palID]; ok {
+			pal.Elements = append(pal.Elements, element)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	workRows, err := s.db.Query("SELECT pal_id, work_type, level FROM pal_work_suitabilities ORDER BY level DESC, work_type")
+	if err != nil {
+		return err
+	}
+	defer workRows.Close()
+
+	for workRows.Next() {
+		var palID int
+		var work domain.WorkSuitability
+		if err := workRows.Scan(&palID, &work.Type, &work.Level); err != nil {
+			return err
+		}
+		if pal, ok := index[palID]; ok {
+			pal.WorkSuitabilities = append(pal.WorkSuitabilities, work)
+		}
+	}
+	if err := workRows.Err(); err != nil {
+		return err
+	}
+
+	dropRows, err := s.db.Query(`
+		SELECT pd.pal_id, pd.resource_id, r.name, pd.min_quantity, pd.max_quantity, pd.drop_rate
+		FROM pal_drops pd
+		JOIN resources r ON r.id = pd.resource_id
+		ORDER BY pd.drop_rate DESC, r.name
+	`)
+	if err != nil {
+		return err
+	}
+	defer dropRows.Close()
+
+	for dropRows.Next() {
+		var palID int
+		var drop domain.PalDrop
+		if err := dropRows.Scan(&palID, &drop.ResourceID, &drop.Name, &drop.MinQuantity, &drop.MaxQuantity, &drop.DropRate); err != nil {
+			return err
+		}
+		if pal, ok := index[palID]; ok {
+			pal.Drops = append(pal.Drops, drop)
+		}
+	}
+
+	return dropRows.Err()
+}
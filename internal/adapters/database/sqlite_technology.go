@@ -0,0 +1,75 @@
+package database
+
+import (
+	"palworld-helper/internal/core/domain"
+)
+
+// GetAllTechnologies retrieves the technology tree with the recipes each
+// technology unlocks and whether the player has unlocked it
+func (s *SQLiteDB) GetAllTechnologies() ([]domain.Technology, error) {
+	query := `
+		SELECT t.id, t.name, t.level, t.points, ut.technology_id IS NOT NULL
+		FROM technologies t
+		LEFT JOIN unlocked_technologies ut ON ut.technology_id = t.id
+		ORDER BY t.level, t.name
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var technologies []domain.Technology
+	index := make(map[int]int)
+	for rows.Next() {
+		var technology domain.Technology
+		err := rows.Scan(&technology.ID, &technology.Name, &technology.Level, &technology.Points, &technology.Unlocked)
+		if err != nil {
+			return nil, err
+		}
+		index[technology.ID] = len(technologies)
+		technologies = append(technologies, technology)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Attach the recipes unlocked by each technology
+	recipeRows, err := s.db.Query("SELECT technology_id, recipe_id FROM technology_recipes ORDER BY recipe_id")
+	if err != nil {
+		return nil, err
+	}
+	defer recipeRows.Close()
+
+	for recipeRows.Next() {
+		var technologyID, recipeID int
+		if err := recipeRows.Scan(&technologyID, &recipeID); err != nil {
+			return nil, err
+		}
+		if i, ok := index[technologyID]; ok {
+			technologies[i].RecipeIDs = append(technologies[i].RecipeIDs, recipeID)
+		}
+	}
+
+	return technologies, recipeRows.Err()
+}
+
+// SetTechnologyUnlocked marks a technology as unlocked or locked for the player
+func (s *SQLiteDB) SetTechnologyUnlocked(id int, unlocked bool) error {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM technologies WHERE id = ?)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+
+	if unlocked {
+		_, err := s.db.Exec("INSERT OR IGNORE INTO unlocked_technologies (technology_id) VALUES (?)", id)
+		return err
+	}
+
+	_, err := s.db.Exec("DELETE FROM unlocked_technologies WHERE technology_id = ?", id)
+	return err
+}
@@ -0,0 +1,368 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func newTestDB(t *testing.T) *SQLiteDB {
+	t.Helper()
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// countRows counts the rows of a table matching a condition
+func countRows(t *testing.T, db *SQLiteDB, query string, args ...any) int {
+	t.Helper()
+	var count int
+	if err := db.db.QueryRow(query, args...).Scan(&count); err != nil {
+		t.Fatalf("counting rows: %v", err)
+	}
+	return count
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	db := newTestDB(t)
+
+	user := &domain.User{Username: "target", PasswordHash: "hash", Role: domain.RoleViewer}
+	if err := db.CreateUser(user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := db.CreateSession("token", user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	if err := db.DeleteUser(user.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM sessions WHERE user_id = ?", user.ID); n != 0 {
+		t.Errorf("%d sessions left after DeleteUser()", n)
+	}
+	if err := db.DeleteUser(user.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteUser() of a deleted user error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestDeleteCraftingListCascades(t *testing.T) {
+	db := newTestDB(t)
+
+	recipe := &domain.CraftingRecipe{Name: "Test Recipe", Category: "test"}
+	if err := db.CreateRecipe(recipe); err != nil {
+		t.Fatalf("CreateRecipe() error = %v", err)
+	}
+	list := &domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: recipe.ID, Quantity: 2}}}
+	if err := db.CreateCraftingList(list); err != nil {
+		t.Fatalf("CreateCraftingList() error = %v", err)
+	}
+
+	if err := db.DeleteCraftingList(list.ID); err != nil {
+		t.Fatalf("DeleteCraftingList() error = %v", err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM crafting_list_items WHERE list_id = ?", list.ID); n != 0 {
+		t.Errorf("%d items left after DeleteCraftingList()", n)
+	}
+	if err := db.DeleteCraftingList(list.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteCraftingList() of a deleted list error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestUpdateInventoryRollsBack(t *testing.T) {
+	db := newTestDB(t)
+
+	wood := &domain.Resource{Name: "Wood"}
+	if err := db.CreateResource(wood); err != nil {
+		t.Fatalf("CreateResource() error = %v", err)
+	}
+	if err := db.UpdateInventory([]domain.InventoryItem{{ResourceID: wood.ID, Quantity: 5}}); err != nil {
+		t.Fatalf("UpdateInventory() error = %v", err)
+	}
+
+	// The second item breaks the foreign key to resources
+	err := db.UpdateInventory([]domain.InventoryItem{{ResourceID: wood.ID, Quantity: 9}, {ResourceID: wood.ID + 100, Quantity: 1}})
+	if err == nil {
+		t.Fatal("UpdateInventory() of a missing resource succeeded")
+	}
+
+	inventory, err := db.GetInventory()
+	if err != nil {
+		t.Fatalf("GetInventory() error = %v", err)
+	}
+	if len(inventory) != 1 || inventory[0].Quantity != 5 {
+		t.Errorf("inventory = %+v, want Wood held at 5", inventory)
+	}
+
+	if err := db.UpdateInventory([]domain.InventoryItem{{ResourceID: wood.ID, Quantity: 0}}); err != nil {
+		t.Fatalf("UpdateInventory() error = %v", err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM inventory"); n != 0 {
+		t.Errorf("%d inventory rows left after setting the quantity to 0", n)
+	}
+}
+
+func TestKeepLastAdmin(t *testing.T) {
+	db := newTestDB(t)
+
+	first := &domain.User{Username: "first", PasswordHash: "hash", Role: domain.RoleAdmin}
+	second := &domain.User{Username: "second", PasswordHash: "hash", Role: domain.RoleAdmin}
+	for _, user := range []*domain.User{first, second} {
+		if err := db.CreateUser(user); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+	}
+
+	// Two requests which both counted two admins before writing
+	first.Role = domain.RoleViewer
+	if err := db.UpdateUser(first); err != nil {
+		t.Fatalf("UpdateUser() of one of two admins error = %v", err)
+	}
+	second.Role = domain.RoleEditor
+	if err := db.UpdateUser(second); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("UpdateUser() of the last admin error = %v, want domain.ErrConflict", err)
+	}
+	if err := db.DeleteUser(second.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("DeleteUser() of the last admin error = %v, want domain.ErrConflict", err)
+	}
+
+	// The last admin can still change their password
+	second.Role = domain.RoleAdmin
+	second.PasswordHash = "other hash"
+	if err := db.UpdateUser(second); err != nil {
+		t.Errorf("UpdateUser() of the password of the last admin error = %v", err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM users WHERE role = 'admin'"); n != 1 {
+		t.Errorf("%d admins left, want 1", n)
+	}
+
+	if err := db.DeleteUser(first.ID); err != nil {
+		t.Errorf("DeleteUser() of a viewer error = %v", err)
+	}
+	if err := db.UpdateUser(first); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UpdateUser() of a deleted user error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestGetVersionSnapshot(t *testing.T) {
+	db := newTestDB(t)
+
+	patches := []*domain.Dataset{
+		{
+			Version:      "1",
+			GameVersion:  "0.5.0",
+			Resources:    []domain.DatasetResource{{Name: "Wood"}},
+			Recipes:      []domain.DatasetRecipe{{Name: "Torch", Resources: map[string]int{"Wood": 2}}},
+			Technologies: []domain.DatasetTechnology{{Name: "Torch", Level: 1, Points: 1, Recipes: []string{"Torch"}}},
+		},
+		{
+			Version:      "2",
+			GameVersion:  "0.6.0",
+			Resources:    []domain.DatasetResource{{Name: "Paldium"}},
+			Recipes:      []domain.DatasetRecipe{{Name: "Pal Sphere", Resources: map[string]int{"Paldium": 1}}},
+			Technologies: []domain.DatasetTechnology{{Name: "Pal Sphere", Level: 2, Points: 1, Recipes: []string{"Pal Sphere"}}},
+		},
+	}
+	for _, patch := range patches {
+		if err := db.UpsertDataset(patch); err != nil {
+			t.Fatalf("UpsertDataset(%s) error = %v", patch.GameVersion, err)
+		}
+	}
+
+	before, err := db.GetVersionSnapshot("0.5.0")
+	if err != nil {
+		t.Fatalf("GetVersionSnapshot() error = %v", err)
+	}
+	after, err := db.GetVersionSnapshot("0.6.0")
+	if err != nil {
+		t.Fatalf("GetVersionSnapshot() error = %v", err)
+	}
+
+	// Records of 0.5.0 missing from the 0.6.0 dataset stay in the database
+	// but not in the 0.6.0 snapshot
+	if !before.Resources["Wood"] || before.Resources["Paldium"] || !after.Resources["Paldium"] || after.Resources["Wood"] {
+		t.Errorf("resources = %v before and %v after, want Wood replaced by Paldium in 0.6.0", before.Resources, after.Resources)
+	}
+	if !before.Technologies["Torch"] || before.Technologies["Pal Sphere"] || !after.Technologies["Pal Sphere"] || after.Technologies["Torch"] {
+		t.Errorf("technologies = %v before and %v after, want Torch replaced by Pal Sphere in 0.6.0", before.Technologies, after.Technologies)
+	}
+	_, torchAfter := after.Recipes["Torch"]
+	if _, ok := before.Recipes["Pal Sphere"]; ok || torchAfter || before.Recipes["Torch"]["Wood"] != 2 || after.Recipes["Pal Sphere"]["Paldium"] != 1 {
+		t.Errorf("recipes = %v before and %v after, want Torch replaced by Pal Sphere in 0.6.0", before.Recipes, after.Recipes)
+	}
+
+	unknown, err := db.GetVersionSnapshot("0.4.0")
+	if err != nil || unknown != nil {
+		t.Errorf("GetVersionSnapshot() of an unknown version = %v, %v, want nil", unknown, err)
+	}
+}
+
+func TestApplySchemaChangeForeignKeyCheck(t *testing.T) {
+	db := newTestDB(t)
+	for _, statement := range []string{
+		"CREATE TABLE parents (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents(id))",
+		"CREATE TABLE lones (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE orphans (id INTEGER PRIMARY KEY, lone_id INTEGER REFERENCES lones(id))",
+		"INSERT INTO parents (id, name) VALUES (1, 'a'), (2, 'b')",
+		"INSERT INTO children (parent_id) VALUES (1)",
+	} {
+		if _, err := db.db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	// An older violation in a table unrelated to the rebuilt ones
+	ctx := context.Background()
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		"PRAGMA foreign_keys = OFF",
+		"INSERT INTO orphans (lone_id) VALUES (42)",
+		"PRAGMA foreign_keys = ON",
+	} {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	conn.Close()
+
+	rebuild := func(table, columns, copy string) []string {
+		return []string{
+			"CREATE TABLE rebuilt (" + columns + ")",
+			"INSERT INTO rebuilt SELECT " + copy + " FROM " + table,
+			"DROP TABLE " + table,
+			"ALTER TABLE rebuilt RENAME TO " + table,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		table      string
+		statements []string
+		wantErr    bool
+	}{
+		{
+			name:       "rows kept",
+			table:      "parents",
+			statements: rebuild("parents", "id INTEGER PRIMARY KEY, name TEXT NOT NULL", "id, name"),
+		},
+		{
+			name:       "referenced row dropped",
+			table:      "parents",
+			statements: append(rebuild("parents", "id INTEGER PRIMARY KEY, name TEXT", "id, name"), "DELETE FROM parents WHERE id = 1"),
+			wantErr:    true,
+		},
+		{
+			name:       "rebuilt rows pointing at a missing parent",
+			table:      "children",
+			statements: rebuild("children", "id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents(id)", "id, parent_id + 10"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.ApplySchemaChange(tt.table, tt.statements, true)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrConflict) {
+					t.Errorf("ApplySchemaChange() error = %v, want domain.ErrConflict", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ApplySchemaChange() error = %v", err)
+			}
+		})
+	}
+
+	if n := countRows(t, db, "SELECT count(*) FROM children WHERE parent_id = 1"); n != 1 {
+		t.Errorf("%d children of the first parent after the rolled back rebuilds, want 1", n)
+	}
+}
+
+func TestGetTableInfoKeysAndIndexes(t *testing.T) {
+	db := newTestDB(t)
+	for _, statement := range []string{
+		"CREATE TABLE regions (code TEXT, zone INTEGER, name TEXT, PRIMARY KEY (code, zone))",
+		"CREATE TABLE species (id INTEGER PRIMARY KEY, name TEXT)",
+		`CREATE TABLE spawns (
+			id INTEGER PRIMARY KEY,
+			code TEXT,
+			zone INTEGER,
+			pal TEXT UNIQUE,
+			species_id INTEGER REFERENCES species ON DELETE CASCADE,
+			FOREIGN KEY (code, zone) REFERENCES regions (code, zone) ON UPDATE CASCADE
+		)`,
+		"CREATE INDEX spawns_zone ON spawns (zone, code) WHERE zone > 0",
+		"CREATE INDEX spawns_lower_pal ON spawns (lower(pal))",
+	} {
+		if _, err := db.db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	info, err := db.GetTableInfo("spawns")
+	if err != nil {
+		t.Fatalf("GetTableInfo() error = %v", err)
+	}
+
+	keys := map[string]domain.ForeignKeyInfo{}
+	for _, key := range info.ForeignKeys {
+		keys[key.Table] = key
+	}
+	wantKeys := map[string]domain.ForeignKeyInfo{
+		// Declared without parent columns, the key references the primary key
+		"species": {Columns: []string{"species_id"}, Table: "species", References: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+		"regions": {Columns: []string{"code", "zone"}, Table: "regions", References: []string{"code", "zone"}, OnUpdate: "CASCADE", OnDelete: "NO ACTION"},
+	}
+	if len(info.ForeignKeys) != len(wantKeys) {
+		t.Errorf("foreign keys = %+v, want %d", info.ForeignKeys, len(wantKeys))
+	}
+	for table, want := range wantKeys {
+		got := keys[table]
+		want.ID = got.ID
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("foreign key to %s = %+v, want %+v", table, got, want)
+		}
+	}
+
+	indexes := map[string]domain.IndexInfo{}
+	for _, index := range info.Indexes {
+		indexes[index.Name] = index
+	}
+	wantIndexes := []domain.IndexInfo{
+		{Name: "sqlite_autoindex_spawns_1", Unique: true, Origin: "u", Columns: []string{"pal"}},
+		{Name: "spawns_zone", Origin: "c", Partial: true, Columns: []string{"zone", "code"}},
+		// Columns indexed through an expression have no name
+		{Name: "spawns_lower_pal", Origin: "c", Columns: []string{""}},
+	}
+	if len(info.Indexes) != len(wantIndexes) {
+		t.Errorf("indexes = %+v, want %d", info.Indexes, len(wantIndexes))
+	}
+	for _, want := range wantIndexes {
+		if got := indexes[want.Name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("index %s = %+v, want %+v", want.Name, got, want)
+		}
+	}
+
+	regions, err := db.GetTableInfo("regions")
+	if err != nil {
+		t.Fatalf("GetTableInfo() error = %v", err)
+	}
+	want := []domain.IndexInfo{{Name: "sqlite_autoindex_regions_1", Unique: true, Origin: "pk", Columns: []string{"code", "zone"}}}
+	if !reflect.DeepEqual(regions.Indexes, want) {
+		t.Errorf("regions indexes = %+v, want %+v", regions.Indexes, want)
+	}
+	if len(regions.ForeignKeys) != 0 {
+		t.Errorf("regions foreign keys = %+v, want none", regions.ForeignKeys)
+	}
+}
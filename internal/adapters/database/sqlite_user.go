@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// sessionTimeFormat matches the format of SQLite's datetime('now')
+const sessionTimeFormat = "2006-01-02 15:04:05"
+
+// GetAllUsers retrieves every admin user ordered by username
+func (s *SQLiteDB) GetAllUsers() ([]domain.User, error) {
+	rows, err := s.db.Query("SELECT id, username, password_hash, role, created_at FROM users ORDER BY username")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetUserByID retrieves a specific user by ID
+func (s *SQLiteDB) GetUserByID(id int) (*domain.User, error) {
+	return s.getUser("SELECT id, username, password_hash, role, created_at FROM users WHERE id = ?", id)
+}
+
+// GetUserByUsername retrieves a specific user by username, ignoring case
+func (s *SQLiteDB) GetUserByUsername(username string) (*domain.User, error) {
+	return s.getUser("SELECT id, username, password_hash, role, created_at FROM users WHERE username = ?", username)
+}
+
+func (s *SQLiteDB) getUser(query string, arg interface{}) (*domain.User, error) {
+	var user domain.User
+	err := s.db.QueryRow(query, arg).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser saves a new user
+func (s *SQLiteDB) CreateUser(user *domain.User) error {
+	result, err := s.db.Exec(
+		"INSERT INTO users (username, password_hash, role) VALUES (?, ?, ?)",
+		user.Username, user.PasswordHash, user.Role,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	user.ID = int(id)
+	return nil
+}
+
+// UpdateUser replaces the password hash and role of a user
+func (s *SQLiteDB) UpdateUser(user *domain.User) error {
+	result, err := s.db.Exec(
+		"UPDATE users SET password_hash = ?, role = ? WHERE id = ? AND (? = 'admin' OR "+otherAdmin+")",
+		user.PasswordHash, user.Role, user.ID, user.Role,
+	)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return s.unchangedUser(user.ID)
+	}
+	return nil
+}
+
+// DeleteUser deletes a user, its sessions going with it by ON DELETE CASCADE
+func (s *SQLiteDB) DeleteUser(id int) error {
+	result, err := s.db.Exec("DELETE FROM users WHERE id = ? AND "+otherAdmin, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return s.unchangedUser(id)
+	}
+	return nil
+}
+
+// otherAdmin holds for a user who is not an admin or not the last one. Writes
+// conditioned on it count the admins in the statement changing the user, so
+// that two requests demoting or deleting the last two admins cannot both
+// succeed.
+const otherAdmin = "(role <> 'admin' OR (SELECT COUNT(*) FROM users WHERE role = 'admin') > 1)"
+
+// unchangedUser tells why a conditioned write changed no user
+func (s *SQLiteDB) unchangedUser(id int) error {
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+	return fmt.Errorf("%w: user %d is the last admin", domain.ErrConflict, id)
+}
+
+// CountUsersWithRole counts the users having the given role
+func (s *SQLiteDB) CountUsersWithRole(role domain.Role) (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE role = ?", role).Scan(&count)
+	return count, err
+}
+
+// CreateSession stores a session by the hash of its token
+func (s *SQLiteDB) CreateSession(tokenHash string, userID int, expiresAt time.Time) error {
+	_, err := s.db.Exec(
+		"INSERT INTO sessions (token_hash, user_id, expires_at) VALUES (?, ?, ?)",
+		tokenHash, userID, expiresAt.UTC().Format(sessionTimeFormat),
+	)
+	return err
+}
+
+// GetSessionUser retrieves the user of an unexpired session, or nil if there is none
+func (s *SQLiteDB) GetSessionUser(tokenHash string) (*domain.User, error) {
+	return s.getUser(`
+		SELECT u.id, u.username, u.password_hash, u.role, u.created_at
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = ? AND s.expires_at > datetime('now')
+	`, tokenHash)
+}
+
+// DeleteSession deletes a session by the hash of its token
+func (s *SQLiteDB) DeleteSession(tokenHash string) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+// DeleteUserSessions deletes every session of a user
+func (s *SQLiteDB) DeleteUserSessions(userID int) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+// DeleteExpiredSessions deletes every expired session
+func (s *SQLiteDB) DeleteExpiredSessions() error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE expires_at <= datetime('now')")
+	return err
+}
@@ -0,0 +1,66 @@
+package dataset
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// The bundled dataset is a small stub of the game data, not the full game.
+// Recipes and technologies are imported from the game files with the
+// import-datatables command.
+//
+//go:embed sample/*.json
+var files embed.FS
+
+// EmbeddedSource reads the stub game dataset bundled in the binary
+type EmbeddedSource struct{}
+
+// NewEmbeddedSource creates a dataset source backed by the embedded stub files
+func NewEmbeddedSource() *EmbeddedSource {
+	return &EmbeddedSource{}
+}
+
+// LoadDataset decodes every data file into a single dataset
+func (s *EmbeddedSource) LoadDataset() (*domain.Dataset, error) {
+	var manifest struct {
+		Version     string `json:"version"`
+		GameVersion string `json:"game_version"`
+	}
+	if err := decode("manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+
+	dataset := &domain.Dataset{Version: manifest.Version, GameVersion: manifest.GameVersion}
+	parts := []struct {
+		file   string
+		target interface{}
+	}{
+		{"resources.json", &dataset.Resources},
+		{"stations.json", &dataset.Stations},
+		{"recipes.json", &dataset.Recipes},
+		{"technologies.json", &dataset.Technologies},
+		{"pals.json", &dataset.Pals},
+		{"breeding.json", &dataset.Breeding},
+	}
+	for _, part := range parts {
+		if err := decode(part.file, part.target); err != nil {
+			return nil, err
+		}
+	}
+
+	return dataset, nil
+}
+
+func decode(name string, target interface{}) error {
+	data, err := files.ReadFile("sample/" + name)
+	if err != nil {
+		return fmt.Errorf("failed to read dataset file %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode dataset file %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package dataset
+
+import "testing"
+
+func TestLoadDataset(t *testing.T) {
+	dataset, err := NewEmbeddedSource().LoadDataset()
+	if err != nil {
+		t.Fatalf("LoadDataset() error = %v", err)
+	}
+	if dataset.Version == "" {
+		t.Error("LoadDataset() returned no version")
+	}
+	if len(dataset.Recipes) == 0 || len(dataset.Pals) == 0 || len(dataset.Breeding) == 0 {
+		t.Errorf("LoadDataset() returned %d recipes, %d pals and %d breeding combinations, want some of each",
+			len(dataset.Recipes), len(dataset.Pals), len(dataset.Breeding))
+	}
+}
@@ -0,0 +1,247 @@
+package datatable
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// Text table keys used by the game for item names and descriptions
+const (
+	itemNamePrefix        = "ITEM_NAME_"
+	itemDescriptionPrefix = "ITEM_DESC_"
+	maxRecipeMaterials    = 5
+)
+
+type row map[string]interface{}
+
+// Tables collects the DataTable rows read from game exports, keyed by row name
+// which is the blueprint ID of the record
+type Tables struct {
+	recipes      map[string]row
+	technologies map[string]row
+	items        map[string]row
+	texts        map[string]string
+}
+
+// NewTables creates an empty set of DataTables
+func NewTables() *Tables {
+	return &Tables{
+		recipes:      make(map[string]row),
+		technologies: make(map[string]row),
+		items:        make(map[string]row),
+		texts:        make(map[string]string),
+	}
+}
+
+// ReadFile reads a JSON DataTable export. Files holding several exports are
+// supported, and the kind of each table is detected from the shape of its rows:
+// item recipes, technologies, item data or localized text.
+func (t *Tables) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	type export struct {
+		Rows map[string]row `json:"Rows"`
+	}
+
+	var exports []export
+	if err := json.Unmarshal(data, &exports); err != nil {
+		var single export
+		if err := json.Unmarshal(data, &single); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		exports = []export{single}
+	}
+
+	found := false
+	for _, e := range exports {
+		if len(e.Rows) > 0 {
+			found = true
+			t.add(e.Rows)
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s does not contain any DataTable rows", path)
+	}
+
+	return nil
+}
+
+func (t *Tables) add(rows map[string]row) {
+	for key, r := range rows {
+		switch {
+		case r["Product_Id"] != nil:
+			t.recipes[key] = r
+		case r["UnlockItemRecipes"] != nil:
+			t.technologies[key] = r
+		case r["TextData"] != nil:
+			if text, ok := r["TextData"].(map[string]interface{}); ok {
+				if value := stringField(text, "LocalizedString"); value != "" {
+					t.texts[key] = value
+				} else if value := stringField(text, "SourceString"); value != "" {
+					t.texts[key] = value
+				}
+			}
+		case r["TypeA"] != nil:
+			t.items[key] = r
+		}
+	}
+}
+
+// Dataset converts the collected rows into a dataset for the given game
+// version. Blueprint IDs are kept on every record and display names come from
+// the text tables, falling back to the blueprint ID split into words when a
+// name is missing.
+func (t *Tables) Dataset(version string) (*domain.Dataset, error) {
+	if len(t.recipes) == 0 && len(t.technologies) == 0 {
+		return nil, fmt.Errorf("no item recipe or technology table was found")
+	}
+
+	dataset := &domain.Dataset{Version: version, GameVersion: version}
+	resources := make(map[string]bool)
+	addResource := func(blueprintID string) string {
+		name := t.itemName(blueprintID)
+		if !resources[blueprintID] {
+			resources[blueprintID] = true
+			dataset.Resources = append(dataset.Resources, domain.DatasetResource{Name: name, BlueprintID: blueprintID})
+		}
+		return name
+	}
+
+	// Recipes are referenced by technologies through their product ID
+	recipeNames := make(map[string]string)
+	for _, key := range sortedKeys(t.recipes) {
+		r := t.recipes[key]
+		productID := stringField(r, "Product_Id")
+		if _, seen := recipeNames[productID]; seen || productID == "" || productID == "None" {
+			continue
+		}
+
+		recipe := domain.DatasetRecipe{
+			Name:        t.itemName(productID),
+			BlueprintID: productID,
+			Category:    t.itemCategory(productID),
+			Description: t.texts[itemDescriptionPrefix+productID],
+			Yield:       max(intField(r, "Product_Count"), 1),
+			WorkAmount:  intField(r, "WorkAmount"),
+			Resources:   make(map[string]int),
+		}
+
+		for i := 1; i <= maxRecipeMaterials; i++ {
+			materialID := stringField(r, fmt.Sprintf("Material%d_Id", i))
+			count := intField(r, fmt.Sprintf("Material%d_Count", i))
+			if materialID == "" || materialID == "None" || count <= 0 {
+				continue
+			}
+			recipe.Resources[addResource(materialID)] += count
+		}
+
+		recipeNames[productID] = recipe.Name
+		dataset.Recipes = append(dataset.Recipes, recipe)
+	}
+
+	for _, key := range sortedKeys(t.technologies) {
+		r := t.technologies[key]
+		technology := domain.DatasetTechnology{
+			Name:        t.technologyName(key, r),
+			BlueprintID: key,
+			Level:       intField(r, "LevelCap"),
+			Points:      max(intField(r, "Cost"), 1),
+		}
+
+		for _, itemID := range stringList(r, "UnlockItemRecipes") {
+			name, ok := recipeNames[itemID]
+			if !ok {
+				name = t.itemName(itemID)
+			}
+			technology.Recipes = append(technology.Recipes, name)
+		}
+
+		dataset.Technologies = append(dataset.Technologies, technology)
+	}
+
+	return dataset, nil
+}
+
+func (t *Tables) itemName(blueprintID string) string {
+	if name := t.texts[itemNamePrefix+blueprintID]; name != "" {
+		return name
+	}
+	return humanize(blueprintID)
+}
+
+// itemCategory uses the item type, such as EPalItemTypeA::Weapon, as category
+func (t *Tables) itemCategory(blueprintID string) string {
+	typeA := stringField(t.items[blueprintID], "TypeA")
+	if i := strings.LastIndex(typeA, "::"); i >= 0 {
+		typeA = typeA[i+2:]
+	}
+	return humanize(typeA)
+}
+
+// technologyName resolves the text key stored in the Name column of the row
+func (t *Tables) technologyName(key string, r row) string {
+	textKey := stringField(r, "Name")
+	if name := t.texts[textKey]; name != "" {
+		return name
+	}
+	if textKey != "" {
+		return humanize(textKey)
+	}
+	return humanize(key)
+}
+
+// humanize splits a blueprint ID such as PalSphere or Pal_crystal_S into words
+func humanize(id string) string {
+	var b strings.Builder
+	runes := []rune(id)
+	for i, r := range runes {
+		if r == '_' {
+			b.WriteRune(' ')
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func sortedKeys(rows map[string]row) []string {
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringField(r map[string]interface{}, key string) string {
+	value, _ := r[key].(string)
+	return value
+}
+
+func intField(r map[string]interface{}, key string) int {
+	value, _ := r[key].(float64)
+	return int(value)
+}
+
+func stringList(r map[string]interface{}, key string) []string {
+	values, _ := r[key].([]interface{})
+	var list []string
+	for _, value := range values {
+		if s, ok := value.(string); ok && s != "" && s != "None" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
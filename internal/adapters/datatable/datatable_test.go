@@ -0,0 +1,166 @@
+package datatable
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func readTables(t *testing.T, files ...string) *Tables {
+	t.Helper()
+	tables := NewTables()
+	for _, file := range files {
+		if err := tables.ReadFile(filepath.Join("testdata", file)); err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", file, err)
+		}
+	}
+	return tables
+}
+
+func TestDataset(t *testing.T) {
+	tables := readTables(t,
+		"DT_ItemRecipeDataTable.json",
+		"DT_TechnologyRecipeUnlock.json",
+		"DT_ItemDataTable.json",
+		"DT_ItemNameText.json",
+	)
+
+	dataset, err := tables.Dataset("0.6.0")
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+	if dataset.Version != "0.6.0" || dataset.GameVersion != "0.6.0" {
+		t.Errorf("versions = %q, %q, want 0.6.0", dataset.Version, dataset.GameVersion)
+	}
+
+	recipes := []domain.DatasetRecipe{
+		{
+			Name:        "Arrow",
+			BlueprintID: "Arrow",
+			Category:    "Ammo",
+			Yield:       5,
+			WorkAmount:  100,
+			Resources:   map[string]int{"Wood": 1, "Stone": 1},
+		},
+		{
+			Name:        "Pal Sphere",
+			BlueprintID: "PalSphere",
+			Category:    "Special Weapon",
+			Description: "A device used to capture Pals.",
+			Yield:       1,
+			WorkAmount:  300,
+			Resources:   map[string]int{"Paldium Fragment": 1, "Wood": 3, "Stone": 3},
+		},
+		{
+			// Fields of the wrong type are ignored
+			Name:        "Pal crystal S",
+			BlueprintID: "Pal_crystal_S",
+			Yield:       1,
+			Resources:   map[string]int{},
+		},
+	}
+	if !reflect.DeepEqual(dataset.Recipes, recipes) {
+		t.Errorf("recipes = %+v, want %+v", dataset.Recipes, recipes)
+	}
+
+	resources := []domain.DatasetResource{
+		{Name: "Wood", BlueprintID: "Wood"},
+		{Name: "Stone", BlueprintID: "Stone"},
+		{Name: "Paldium Fragment", BlueprintID: "PalCrystal_Ex"},
+	}
+	if !reflect.DeepEqual(dataset.Resources, resources) {
+		t.Errorf("resources = %+v, want %+v", dataset.Resources, resources)
+	}
+
+	technologies := []domain.DatasetTechnology{
+		{
+			Name:        "Golden Sphere Tech",
+			BlueprintID: "GoldenSphereTech",
+			Points:      1,
+			Recipes:     []string{"Golden Sphere"},
+		},
+		{
+			Name:        "Pal Sphere Crafting",
+			BlueprintID: "Tech_PalSphere",
+			Level:       2,
+			Points:      1,
+			Recipes:     []string{"Pal Sphere", "Arrow"},
+		},
+	}
+	if !reflect.DeepEqual(dataset.Technologies, technologies) {
+		t.Errorf("technologies = %+v, want %+v", dataset.Technologies, technologies)
+	}
+}
+
+func TestDatasetWithoutTexts(t *testing.T) {
+	dataset, err := readTables(t, "DT_ItemRecipeDataTable.json", "DT_TechnologyRecipeUnlock.json").Dataset("0.6.0")
+	if err != nil {
+		t.Fatalf("Dataset() error = %v", err)
+	}
+
+	names := make(map[string]string)
+	for _, recipe := range dataset.Recipes {
+		names[recipe.BlueprintID] = recipe.Name
+	}
+	if names["PalSphere"] != "Pal Sphere" {
+		t.Errorf("PalSphere name = %q, want Pal Sphere", names["PalSphere"])
+	}
+	if want := []string{"Pal Sphere", "Arrow"}; !reflect.DeepEqual(dataset.Technologies[1].Recipes, want) {
+		t.Errorf("technology recipes = %v, want %v", dataset.Technologies[1].Recipes, want)
+	}
+	if dataset.Technologies[1].Name != "TECH NAME Pal Sphere" {
+		t.Errorf("technology name = %q, want the humanized text key", dataset.Technologies[1].Name)
+	}
+}
+
+func TestDatasetWithoutRecipes(t *testing.T) {
+	if _, err := readTables(t, "DT_ItemDataTable.json", "DT_ItemNameText.json").Dataset("0.6.0"); err == nil {
+		t.Error("Dataset() without recipe or technology table succeeded, want an error")
+	}
+}
+
+func TestReadFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not JSON", `DataTable`},
+		{"no rows", `{"Type": "DataTable", "Name": "DT_Empty"}`},
+		{"empty array", `[]`},
+		{"row that is not an object", `{"Rows": {"PalSphere": "Pal Sphere"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "table.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewTables().ReadFile(path); err == nil {
+				t.Error("ReadFile() succeeded, want an error")
+			}
+		})
+	}
+
+	if err := NewTables().ReadFile(filepath.Join("testdata", "missing.json")); err == nil {
+		t.Error("ReadFile() of a missing file succeeded, want an error")
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	tests := map[string]string{
+		"PalSphere":     "Pal Sphere",
+		"Pal_crystal_S": "Pal crystal S",
+		"SpecialWeapon": "Special Weapon",
+		"HP2Potion":     "HP2 Potion",
+		"__Wood__":      "Wood",
+		"":              "",
+	}
+	for id, want := range tests {
+		if got := humanize(id); got != want {
+			t.Errorf("humanize(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -12,6 +16,13 @@ import (
 	"palworld-helper/web/templates"
 )
 
+// maxTableImportSize caps the size of a file imported into a table
+const maxTableImportSize = 32 << 20
+
+// statusClientClosedRequest reports a request its client cancelled, as nginx
+// does, rather than a server error
+const statusClientClosedRequest = 499
+
 type AdminHandler struct {
 	service ports.AdminService
 }
@@ -27,12 +38,20 @@ func (h *AdminHandler) AdminPage(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(templates.AdminPageHTML))
 }
 
-func (h *AdminHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
+// HandleSchema returns the schema (GET) or changes a table (POST). With
+// preview=true a change only returns the statements it would run.
+func (h *AdminHandler) HandleSchema(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.getSchema(w, r)
+	case "POST":
+		h.changeSchema(w, r)
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
 	}
+}
 
+func (h *AdminHandler) getSchema(w http.ResponseWriter, r *http.Request) {
 	schema, err := h.service.GetDatabaseSchema()
 	if err != nil {
 		http.Error(w, "Failed to get schema: "+err.Error(), http.StatusInternalServerError)
@@ -43,10 +62,46 @@ func (h *AdminHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(schema)
 }
 
+func (h *AdminHandler) changeSchema(w http.ResponseWriter, r *http.Request) {
+	var change domain.SchemaChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var plan *domain.SchemaPlan
+	var err error
+	if r.URL.Query().Get("preview") == "true" {
+		plan, err = h.service.PlanSchemaChange(change)
+	} else {
+		plan, err = h.service.ApplySchemaChange(r.Context(), change)
+	}
+	if err != nil {
+		http.Error(w, "Failed to change schema: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// HandleTableOperations serves /admin/api/table/{name} to read (GET) and add
+// (POST) rows, its export, import and lookup/{column} paths, and the rows
+// addressed as /admin/api/table/{name}/{key...} to update (PUT) and delete
+// (DELETE) them. The key holds the escaped values of the primary key columns
+// in key order, or the rowid of tables without a primary key.
 func (h *AdminHandler) HandleTableOperations(w http.ResponseWriter, r *http.Request) {
-	// Extract table name from URL path
-	path := strings.TrimPrefix(r.URL.Path, "/admin/api/table/")
+	// Extract table name from URL path, keeping escaped slashes in key values
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/admin/api/table/")
 	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			http.Error(w, "Invalid path: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		parts[i] = unescaped
+	}
 
 	if len(parts) < 1 || parts[0] == "" {
 		http.Error(w, "Table name required", http.StatusBadRequest)
@@ -57,53 +112,216 @@ func (h *AdminHandler) HandleTableOperations(w http.ResponseWriter, r *http.Requ
 
 	switch r.Method {
 	case "GET":
-		h.getTableData(w, r, tableName)
+		switch {
+		case len(parts) == 1:
+			h.getTableData(w, r, tableName)
+		case len(parts) == 2 && parts[1] == "export":
+			h.exportTable(w, r, tableName)
+		case len(parts) == 3 && parts[1] == "lookup":
+			h.lookupForeignKey(w, r, tableName, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
 	case "POST":
-		h.insertTableData(w, r, tableName)
+		switch {
+		case len(parts) == 1:
+			h.insertTableData(w, r, tableName)
+		case len(parts) == 2 && parts[1] == "import":
+			h.importTable(w, r, tableName)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
 	case "PUT":
 		if len(parts) < 2 {
-			http.Error(w, "Record ID required for update", http.StatusBadRequest)
-			return
-		}
-		id, err := strconv.Atoi(parts[1])
-		if err != nil {
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			http.Error(w, "Row key required for update", http.StatusBadRequest)
 			return
 		}
-		h.updateTableData(w, r, tableName, id)
+		h.updateTableData(w, r, tableName, parts[1:])
 	case "DELETE":
 		if len(parts) < 2 {
-			http.Error(w, "Record ID required for delete", http.StatusBadRequest)
-			return
-		}
-		id, err := strconv.Atoi(parts[1])
-		if err != nil {
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			http.Error(w, "Row key required for delete", http.StatusBadRequest)
 			return
 		}
-		h.deleteTableData(w, r, tableName, id)
+		h.deleteTableData(w, r, tableName, parts[1:])
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
+// getTableData returns a page of rows. The query accepts limit, offset, sort,
+// order (asc or desc) and filter parameters of the form column:operator:value,
+// such as filter=name:contains:ore.
 func (h *AdminHandler) getTableData(w http.ResponseWriter, r *http.Request, tableName string) {
-	// Log pour débugger
-	fmt.Printf("Getting data from table: %s\n", tableName)
+	query, err := parseTableQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Failed to get table data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetTableData(tableName, query)
+	if err != nil {
+		http.Error(w, "Failed to get table data: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func parseTableQuery(values url.Values) (domain.TableQuery, error) {
+	var query domain.TableQuery
+	var err error
 
-	data, err := h.service.GetTableData(tableName)
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			return query, fmt.Errorf("invalid limit")
+		}
+	}
+	if offset := values.Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			return query, fmt.Errorf("invalid offset")
+		}
+	}
+
+	query.Sort = values.Get("sort")
+	switch strings.ToLower(values.Get("order")) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, fmt.Errorf("invalid order, expected asc or desc")
+	}
+
+	for _, filter := range values["filter"] {
+		parts := strings.SplitN(filter, ":", 3)
+		if len(parts) < 2 {
+			return query, fmt.Errorf("invalid filter %q, expected column:operator:value", filter)
+		}
+		columnFilter := domain.ColumnFilter{Column: parts[0], Operator: parts[1]}
+		if len(parts) == 3 {
+			columnFilter.Value = parts[2]
+		}
+		query.Filters = append(query.Filters, columnFilter)
+	}
+
+	return query, nil
+}
+
+// lookupForeignKey returns the values a foreign key column accepts, labelled
+// by the parent rows. The query accepts q to search the labels and limit.
+func (h *AdminHandler) lookupForeignKey(w http.ResponseWriter, r *http.Request, tableName, column string) {
+	var limit int
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			http.Error(w, "Failed to look up foreign key: invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	lookup, err := h.service.LookupForeignKey(tableName, column, r.URL.Query().Get("q"), limit)
 	if err != nil {
-		// Log l'erreur complète
-		fmt.Printf("Get table data error: %v\n", err)
-		http.Error(w, "Failed to get table data: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to look up foreign key: "+err.Error(), adminErrorStatus(err))
 		return
 	}
 
-	// Log pour débugger
-	fmt.Printf("Retrieved %d rows from table %s\n", len(data), tableName)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lookup)
+}
+
+// exportTable streams a table in the format given by the format parameter:
+// csv, json (the default) or ndjson
+func (h *AdminHandler) exportTable(w http.ResponseWriter, r *http.Request, tableName string) {
+	format := domain.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = domain.FormatJSON
+	}
+
+	export := &tableExportWriter{w: w, table: tableName, format: format}
+	if err := h.service.ExportTable(export, tableName, format); err != nil {
+		if export.started {
+			// Headers are gone, the client gets a truncated file
+			log.Printf("Export of table %s failed: %v", tableName, err)
+			return
+		}
+		http.Error(w, "Failed to export table: "+err.Error(), adminErrorStatus(err))
+	}
+}
+
+// tableExportWriter sets the headers of a table export on the first write, so
+// errors found before any row is written still get an error response
+type tableExportWriter struct {
+	w       http.ResponseWriter
+	table   string
+	format  domain.ExportFormat
+	started bool
+}
+
+func (e *tableExportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		contentType := "application/json"
+		switch e.format {
+		case domain.FormatCSV:
+			contentType = "text/csv; charset=utf-8"
+		case domain.FormatNDJSON:
+			contentType = "application/x-ndjson"
+		}
+		e.w.Header().Set("Content-Type", contentType)
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, e.table, e.format))
+	}
+	return e.w.Write(p)
+}
+
+// importTable loads a CSV, JSON or NDJSON file into a table. The format
+// parameter falls back to the Content-Type header, mode is insert (the
+// default), upsert or replace, and dry_run=true reports the row errors
+// without writing anything.
+func (h *AdminHandler) importTable(w http.ResponseWriter, r *http.Request, tableName string) {
+	query := r.URL.Query()
+
+	format := domain.ExportFormat(query.Get("format"))
+	if format == "" {
+		format = domain.FormatJSON
+		switch contentType := r.Header.Get("Content-Type"); {
+		case strings.Contains(contentType, "csv"):
+			format = domain.FormatCSV
+		case strings.Contains(contentType, "ndjson"):
+			format = domain.FormatNDJSON
+		}
+	}
+
+	mode := domain.ImportMode(query.Get("mode"))
+	if mode == "" {
+		mode = domain.ImportInsert
+	}
+
+	var dryRun bool
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxTableImportSize)
+	result, err := h.service.ImportTable(r.Context(), body, tableName, format, mode, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Failed to import table: file is larger than "+strconv.Itoa(maxTableImportSize>>20)+" MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to import table: "+err.Error(), adminErrorStatus(err))
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	if len(result.Errors) > 0 && !dryRun {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(result)
 }
 
 func (h *AdminHandler) insertTableData(w http.ResponseWriter, r *http.Request, tableName string) {
@@ -113,13 +331,9 @@ func (h *AdminHandler) insertTableData(w http.ResponseWriter, r *http.Request, t
 		return
 	}
 
-	// Log pour débugger
-	fmt.Printf("Inserting data into table %s: %+v\n", tableName, data)
-
-	if err := h.service.InsertData(tableName, data); err != nil {
-		// Log l'erreur complète
-		fmt.Printf("Insert error: %v\n", err)
-		http.Error(w, "Failed to insert data: "+err.Error(), http.StatusInternalServerError)
+	if err := h.service.InsertData(r.Context(), tableName, data); err != nil {
+		log.Printf("Insert into table %s failed: %v", tableName, err)
+		http.Error(w, "Failed to insert data: "+err.Error(), adminErrorStatus(err))
 		return
 	}
 
@@ -128,20 +342,16 @@ func (h *AdminHandler) insertTableData(w http.ResponseWriter, r *http.Request, t
 	w.Write([]byte(`{"message": "Data inserted successfully"}`))
 }
 
-func (h *AdminHandler) updateTableData(w http.ResponseWriter, r *http.Request, tableName string, id int) {
+func (h *AdminHandler) updateTableData(w http.ResponseWriter, r *http.Request, tableName string, key []string) {
 	var data map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Log pour débugger
-	fmt.Printf("Updating data in table %s, id %d: %+v\n", tableName, id, data)
-
-	if err := h.service.UpdateData(tableName, id, data); err != nil {
-		// Log l'erreur complète
-		fmt.Printf("Update error: %v\n", err)
-		http.Error(w, "Failed to update data: "+err.Error(), http.StatusInternalServerError)
+	if err := h.service.UpdateData(r.Context(), tableName, key, data); err != nil {
+		log.Printf("Update of table %s failed: %v", tableName, err)
+		http.Error(w, "Failed to update data: "+err.Error(), adminErrorStatus(err))
 		return
 	}
 
@@ -149,15 +359,17 @@ func (h *AdminHandler) updateTableData(w http.ResponseWriter, r *http.Request, t
 	w.Write([]byte(`{"message": "Data updated successfully"}`))
 }
 
-func (h *AdminHandler) deleteTableData(w http.ResponseWriter, r *http.Request, tableName string, id int) {
-	if err := h.service.DeleteData(tableName, id); err != nil {
-		http.Error(w, "Failed to delete data: "+err.Error(), http.StatusInternalServerError)
+func (h *AdminHandler) deleteTableData(w http.ResponseWriter, r *http.Request, tableName string, key []string) {
+	if err := h.service.DeleteData(r.Context(), tableName, key); err != nil {
+		http.Error(w, "Failed to delete data: "+err.Error(), adminErrorStatus(err))
 		return
 	}
 
 	w.Write([]byte(`{"message": "Data deleted successfully"}`))
 }
 
+// ExecuteQuery runs the query of a {"query": ...} body, or returns its plan
+// when the body has "mode": "plan". Closing the request interrupts the query.
 func (h *AdminHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -166,6 +378,7 @@ func (h *AdminHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
 		Query string `json:"query"`
+		Mode  string `json:"mode"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -178,9 +391,19 @@ func (h *AdminHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := h.service.ExecuteQuery(req.Query)
+	switch req.Mode {
+	case "", "run":
+	case "plan":
+		h.explainQuery(w, r, req.Query)
+		return
+	default:
+		http.Error(w, "Unknown query mode "+req.Mode+", expected run or plan", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.ExecuteQuery(r.Context(), req.Query)
 	if err != nil {
-		http.Error(w, "Query execution failed: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Query execution failed: "+err.Error(), adminErrorStatus(err))
 		return
 	}
 
@@ -193,6 +416,47 @@ func (h *AdminHandler) ExecuteQuery(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *AdminHandler) explainQuery(w http.ResponseWriter, r *http.Request, query string) {
+	plan, err := h.service.ExplainQuery(r.Context(), query)
+	if err != nil {
+		http.Error(w, "Query plan failed: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// ApplyBatch applies the operations of a {"operations": [...]} body in one
+// transaction. A failed operation rolls the batch back and is reported with a
+// 422 status.
+func (h *AdminHandler) ApplyBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Operations []domain.BatchOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.ApplyBatch(r.Context(), req.Operations)
+	if err != nil {
+		http.Error(w, "Failed to apply batch: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Failed != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
 func (h *AdminHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -219,11 +483,65 @@ func (h *AdminHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.CreateTable(req.TableName, req.Columns); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create table: %v", err), http.StatusInternalServerError)
+	if err := h.service.CreateTable(r.Context(), req.TableName, req.Columns); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create table: %v", err), adminErrorStatus(err))
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte(`{"message": "Table created successfully"}`))
 }
+
+// GetAuditLog lists audit entries, filtered by the user, action, table,
+// row_id, since, until and limit query parameters
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Username:  query.Get("user"),
+		Action:    domain.AuditAction(query.Get("action")),
+		TableName: query.Get("table"),
+		RowID:     query.Get("row_id"),
+		Since:     query.Get("since"),
+		Until:     query.Get("until"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.service.GetAuditLog(filter)
+	if err != nil {
+		http.Error(w, "Failed to get audit log: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// adminErrorStatus maps errors of the admin service to status codes
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/services"
+)
+
+func TestParseTableQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.TableQuery
+		wantErr bool
+	}{
+		{name: "defaults", query: ""},
+		{
+			name:  "page",
+			query: "limit=25&offset=50",
+			want:  domain.TableQuery{Limit: 25, Offset: 50},
+		},
+		{
+			name:  "sort",
+			query: "sort=name&order=DESC",
+			want:  domain.TableQuery{Sort: "name", Desc: true},
+		},
+		{
+			name:  "ascending",
+			query: "sort=name&order=asc",
+			want:  domain.TableQuery{Sort: "name"},
+		},
+		{
+			name:  "filters",
+			query: "filter=level:gte:10&filter=note:null&filter=" + url.QueryEscape("name:contains:a:b"),
+			want: domain.TableQuery{Filters: []domain.ColumnFilter{
+				{Column: "level", Operator: domain.FilterGreaterEqual, Value: "10"},
+				{Column: "note", Operator: domain.FilterNull},
+				// Only the first two colons separate the parts
+				{Column: "name", Operator: domain.FilterContains, Value: "a:b"},
+			}},
+		},
+		{name: "zero limit", query: "limit=0", wantErr: true},
+		{name: "negative limit", query: "limit=-5", wantErr: true},
+		{name: "limit not a number", query: "limit=ten", wantErr: true},
+		{name: "negative offset", query: "offset=-1", wantErr: true},
+		{name: "offset not a number", query: "offset=1.5", wantErr: true},
+		{name: "unknown order", query: "order=random", wantErr: true},
+		{name: "filter without operator", query: "filter=level", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseTableQuery(values)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTableQuery(%q) = %+v, want an error", tt.query, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTableQuery(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTableQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteQueryInterruptedStatus(t *testing.T) {
+	const endless = `{"query": "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT count(*) FROM n"}`
+
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+	policy := domain.DefaultQueryPolicy
+	policy.Timeout = 50 * time.Millisecond
+	user := &domain.User{ID: 1, Username: "admin", Role: domain.RoleAdmin}
+
+	tests := []struct {
+		name   string
+		policy domain.QueryPolicy
+		cancel bool
+		want   int
+	}{
+		{"timeout", policy, false, http.StatusGatewayTimeout},
+		{"cancelled by the client", domain.DefaultQueryPolicy, true, statusClientClosedRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(services.NewAdminService(db, db, tt.policy))
+			ctx, cancel := context.WithCancel(domain.WithUser(context.Background(), user))
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+
+			r := httptest.NewRequest("POST", "/admin/api/query", strings.NewReader(endless)).WithContext(ctx)
+			w := httptest.NewRecorder()
+			handler.ExecuteQuery(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
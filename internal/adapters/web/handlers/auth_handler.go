@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// sessionCookie holds the session token of the admin interface
+const sessionCookie = "palworld_session"
+
+type AuthHandler struct {
+	service ports.AuthService
+}
+
+func NewAuthHandler(service ports.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// Require wraps an admin route so it needs a session. Reads (GET and HEAD)
+// need the read role and every other method the write role.
+func (h *AuthHandler) Require(read, write domain.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var token string
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			token = cookie.Value
+		}
+
+		user, err := h.service.Authenticate(token)
+		if err != nil {
+			writeAuthError(w, "Authentication failed", err)
+			return
+		}
+
+		required := write
+		if r.Method == "GET" || r.Method == "HEAD" {
+			required = read
+		}
+		if !user.Role.Allows(required) {
+			http.Error(w, "Forbidden: "+string(required)+" role required", http.StatusForbidden)
+			return
+		}
+
+		next(w, r.WithContext(domain.WithUser(r.Context(), user)))
+	}
+}
+
+// Login opens a session and sets its cookie
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var credentials domain.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.service.Login(credentials)
+	if err != nil {
+		writeAuthError(w, "Failed to log in", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.Token,
+		Path:     "/admin",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// Logout closes the current session and clears its cookie
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := h.service.Logout(cookie.Value); err != nil {
+			http.Error(w, "Failed to log out: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     "/admin",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me returns the user of the current session. It is meant to be wrapped by
+// Require, which puts the user in the request context.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domain.UserFromContext(r.Context()))
+}
+
+func (h *AuthHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from URL path: /admin/api/users/{id}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/api/users"), "/")
+
+	if path == "" {
+		switch r.Method {
+		case "GET":
+			h.getUsers(w, r)
+		case "POST":
+			h.createUser(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(path)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		h.updateUser(w, r, id)
+	case "DELETE":
+		h.deleteUser(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *AuthHandler) getUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.GetUsers()
+	if err != nil {
+		http.Error(w, "Failed to get users: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func (h *AuthHandler) createUser(w http.ResponseWriter, r *http.Request) {
+	var credentials domain.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.CreateUser(credentials)
+	if err != nil {
+		writeAuthError(w, "Failed to create user", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *AuthHandler) updateUser(w http.ResponseWriter, r *http.Request, id int) {
+	var credentials domain.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.UpdateUser(id, credentials)
+	if err != nil {
+		writeAuthError(w, "Failed to update user", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *AuthHandler) deleteUser(w http.ResponseWriter, r *http.Request, id int) {
+	if current := domain.UserFromContext(r.Context()); current != nil && current.ID == id {
+		http.Error(w, "Failed to delete user: you cannot delete your own account", http.StatusConflict)
+		return
+	}
+
+	if err := h.service.DeleteUser(id); err != nil {
+		writeAuthError(w, "Failed to delete user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAuthError maps authentication and user management errors to status codes
+func writeAuthError(w http.ResponseWriter, message string, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		code = http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		code = http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		code = http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict):
+		code = http.StatusConflict
+	}
+	http.Error(w, message+": "+err.Error(), code)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// tokenAuthService authenticates the tokens of its map. Its other methods are
+// not used by Require.
+type tokenAuthService struct {
+	ports.AuthService
+	users map[string]*domain.User
+}
+
+func (s tokenAuthService) Authenticate(token string) (*domain.User, error) {
+	if user, ok := s.users[token]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("%w: session expired", domain.ErrUnauthorized)
+}
+
+func TestRequire(t *testing.T) {
+	handler := NewAuthHandler(tokenAuthService{users: map[string]*domain.User{
+		"viewer": {ID: 1, Username: "viewer", Role: domain.RoleViewer},
+		"editor": {ID: 2, Username: "editor", Role: domain.RoleEditor},
+		"admin":  {ID: 3, Username: "admin", Role: domain.RoleAdmin},
+	}})
+
+	routes := []struct {
+		read, write domain.Role
+	}{
+		{domain.RoleViewer, domain.RoleViewer},
+		{domain.RoleViewer, domain.RoleEditor},
+		{domain.RoleViewer, domain.RoleAdmin},
+		{domain.RoleEditor, domain.RoleEditor},
+		{domain.RoleAdmin, domain.RoleAdmin},
+	}
+	tokens := []string{"", "expired", "viewer", "editor", "admin"}
+	ranks := map[domain.Role]int{domain.RoleViewer: 1, domain.RoleEditor: 2, domain.RoleAdmin: 3}
+	methods := []string{"GET", "HEAD", "POST", "PUT", "DELETE"}
+
+	for _, route := range routes {
+		for _, token := range tokens {
+			for _, method := range methods {
+				name := fmt.Sprintf("%s-%s/%s as %q", method, route.read, route.write, token)
+				t.Run(name, func(t *testing.T) {
+					var user *domain.User
+					next := func(w http.ResponseWriter, r *http.Request) {
+						user = domain.UserFromContext(r.Context())
+					}
+
+					r := httptest.NewRequest(method, "/admin/api/test", nil)
+					if token != "" {
+						r.AddCookie(&http.Cookie{Name: sessionCookie, Value: token})
+					}
+					w := httptest.NewRecorder()
+					handler.Require(route.read, route.write, next)(w, r)
+
+					required := route.write
+					if method == "GET" || method == "HEAD" {
+						required = route.read
+					}
+					want := http.StatusOK
+					switch {
+					case token == "" || token == "expired":
+						want = http.StatusUnauthorized
+					case ranks[domain.Role(token)] < ranks[required]:
+						want = http.StatusForbidden
+					}
+
+					if w.Code != want {
+						t.Fatalf("status = %d, want %d", w.Code, want)
+					}
+					if want == http.StatusOK && (user == nil || user.Username != token) {
+						t.Errorf("next got user %v, want %s", user, token)
+					}
+					if want != http.StatusOK && user != nil {
+						t.Error("next was called for a refused request")
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// maxRestoreSize caps the size of a database uploaded for a restore
+const maxRestoreSize = 512 << 20
+
+type BackupHandler struct {
+	service ports.BackupService
+}
+
+func NewBackupHandler(service ports.BackupService) *BackupHandler {
+	return &BackupHandler{
+		service: service,
+	}
+}
+
+// HandleBackups serves /admin/api/backups to list (GET) and take (POST)
+// backups, /admin/api/backups/{name} to download (GET) or delete (DELETE) one
+// and /admin/api/backups/{name}/restore to restore one (POST)
+func (h *BackupHandler) HandleBackups(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/api/backups"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "" && r.Method == "GET":
+		h.listBackups(w, r)
+	case path == "" && r.Method == "POST":
+		h.createBackup(w, r)
+	case len(parts) == 1 && r.Method == "GET":
+		h.downloadBackup(w, r, parts[0])
+	case len(parts) == 1 && r.Method == "DELETE":
+		h.deleteBackup(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "restore" && r.Method == "POST":
+		h.restoreBackup(w, r, parts[0])
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *BackupHandler) listBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.service.ListBackups()
+	if err != nil {
+		http.Error(w, "Failed to list backups: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(backups)
+}
+
+func (h *BackupHandler) createBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.service.CreateBackup(r.Context(), domain.BackupManual)
+	if err != nil {
+		http.Error(w, "Failed to create backup: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(backup)
+}
+
+func (h *BackupHandler) downloadBackup(w http.ResponseWriter, r *http.Request, name string) {
+	file, backup, err := h.service.OpenBackup(name)
+	if err != nil {
+		http.Error(w, "Failed to download backup: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+backup.Name+`"`)
+	http.ServeContent(w, r, backup.Name, backup.CreatedAt, file)
+}
+
+func (h *BackupHandler) deleteBackup(w http.ResponseWriter, r *http.Request, name string) {
+	if err := h.service.DeleteBackup(r.Context(), name); err != nil {
+		http.Error(w, "Failed to delete backup: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BackupHandler) restoreBackup(w http.ResponseWriter, r *http.Request, name string) {
+	if err := h.service.RestoreBackup(r.Context(), name); err != nil {
+		http.Error(w, "Failed to restore backup: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Restore replaces the database with the SQLite file sent as request body
+func (h *BackupHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxRestoreSize)
+	if err := h.service.RestoreUpload(r.Context(), body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Failed to restore backup: file is larger than "+strconv.Itoa(maxRestoreSize>>20)+" MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to restore backup: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type BreedingHandler struct {
+	service ports.BreedingService
+}
+
+func NewBreedingHandler(service ports.BreedingService) *BreedingHandler {
+	return &BreedingHandler{
+		service: service,
+	}
+}
+
+func (h *BreedingHandler) GetChild(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parent1, err1 := strconv.Atoi(r.URL.Query().Get("parent1"))
+	parent2, err2 := strconv.Atoi(r.URL.Query().Get("parent2"))
+	if err1 != nil || err2 != nil {
+		http.Error(w, "parent1 and parent2 must be pal IDs", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.Breed(parent1, parent2)
+	if err != nil {
+		writeBreedingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *BreedingHandler) FindPath(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req domain.BreedingPathRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Owned) == 0 {
+		http.Error(w, "At least one owned pal is required", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.service.FindPath(req)
+	if err != nil {
+		writeBreedingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(path)
+}
+
+func writeBreedingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrNoBreedingPath):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Breeding calculation failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"palworld-helper/internal/core/domain"
 	"palworld-helper/internal/core/ports"
@@ -30,7 +33,16 @@ func (h *CraftingHandler) GetRecipes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	recipes, err := h.service.GetAllRecipes()
+	// ?locked=flag marks locked recipes, ?locked=hide leaves them out
+	var query domain.RecipeQuery
+	switch r.URL.Query().Get("locked") {
+	case "flag":
+		query.FlagLocked = true
+	case "hide":
+		query.HideLocked = true
+	}
+
+	recipes, err := h.service.GetAllRecipes(query)
 	if err != nil {
 		http.Error(w, "Failed to get recipes: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -52,12 +64,121 @@ func (h *CraftingHandler) CalculateResources(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	resourceTotals, err := h.service.CalculateResources(req)
+	result, err := h.service.CalculateResources(req)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrCraftingCycle) {
+		http.Error(w, "Failed to calculate resources: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to calculate resources: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resourceTotals)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *CraftingHandler) HandleInventory(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.getInventory(w, r)
+	case "PUT", "POST":
+		h.updateInventory(w, r)
+	case "DELETE":
+		resourceID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/inventory/"))
+		if err != nil {
+			http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+			return
+		}
+		h.deleteInventoryItem(w, r, resourceID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CraftingHandler) getInventory(w http.ResponseWriter, r *http.Request) {
+	inventory, err := h.service.GetInventory()
+	if err != nil {
+		http.Error(w, "Failed to get inventory: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inventory)
+}
+
+func (h *CraftingHandler) updateInventory(w http.ResponseWriter, r *http.Request) {
+	var items []domain.InventoryItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UpdateInventory(items); err != nil {
+		http.Error(w, "Failed to update inventory: "+err.Error(), adminErrorStatus(err))
+		return
+	}
+
+	h.getInventory(w, r)
+}
+
+func (h *CraftingHandler) deleteInventoryItem(w http.ResponseWriter, r *http.Request, resourceID int) {
+	if err := h.service.DeleteInventoryItem(resourceID); err != nil {
+		http.Error(w, "Failed to delete inventory item: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Inventory item deleted successfully"}`))
+}
+
+func (h *CraftingHandler) HandleStations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		stations, err := h.service.GetStations()
+		if err != nil {
+			http.Error(w, "Failed to get stations: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stations)
+	case "PUT":
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/stations/"))
+		if err != nil {
+			http.Error(w, "Invalid station ID", http.StatusBadRequest)
+			return
+		}
+		h.setStationBuilt(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CraftingHandler) setStationBuilt(w http.ResponseWriter, r *http.Request, id int) {
+	var req struct {
+		Built bool `json:"built"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.SetStationBuilt(id, req.Built)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update station: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Station updated successfully"}`))
 }
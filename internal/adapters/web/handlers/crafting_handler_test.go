@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// inventoryService fails inventory updates with its error. Its other methods
+// are not used by the tests.
+type inventoryService struct {
+	ports.CraftingService
+	err error
+}
+
+func (s inventoryService) UpdateInventory(items []domain.InventoryItem) error {
+	return s.err
+}
+
+func (s inventoryService) GetInventory() ([]domain.InventoryItem, error) {
+	return []domain.InventoryItem{}, nil
+}
+
+func TestUpdateInventoryStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"updated", nil, http.StatusOK},
+		{"invalid item", fmt.Errorf("%w: invalid quantity -1 for resource 1", domain.ErrInvalidInput), http.StatusBadRequest},
+		{"database failure", errors.New("database is locked"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCraftingHandler(inventoryService{err: tt.err})
+			r := httptest.NewRequest("PUT", "/api/inventory", strings.NewReader(`[{"resource_id": 1, "quantity": 2}]`))
+			w := httptest.NewRecorder()
+			handler.HandleInventory(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
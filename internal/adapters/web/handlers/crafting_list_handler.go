@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type CraftingListHandler struct {
+	service       ports.CraftingListService
+	exportService ports.ExportService
+}
+
+func NewCraftingListHandler(service ports.CraftingListService, exportService ports.ExportService) *CraftingListHandler {
+	return &CraftingListHandler{
+		service:       service,
+		exportService: exportService,
+	}
+}
+
+func (h *CraftingListHandler) HandleLists(w http.ResponseWriter, r *http.Request) {
+	// Extract list ID and action from URL path: /api/lists/{id}[/calculate|/export]
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/lists"), "/")
+	parts := strings.Split(path, "/")
+
+	if path == "" {
+		switch r.Method {
+		case "GET":
+			h.getLists(w, r)
+		case "POST":
+			h.createList(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "calculate" {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.calculateList(w, r, id)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "export" {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.exportList(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		h.getList(w, r, id)
+	case "PUT":
+		h.updateList(w, r, id)
+	case "DELETE":
+		h.deleteList(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CraftingListHandler) getLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.GetAllLists()
+	if err != nil {
+		http.Error(w, "Failed to get crafting lists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+func (h *CraftingListHandler) getList(w http.ResponseWriter, r *http.Request, id int) {
+	list, err := h.service.GetList(id)
+	if err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *CraftingListHandler) createList(w http.ResponseWriter, r *http.Request) {
+	var list domain.CraftingList
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateList(&list); err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *CraftingListHandler) updateList(w http.ResponseWriter, r *http.Request, id int) {
+	var list domain.CraftingList
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	list.ID = id
+
+	if err := h.service.UpdateList(&list); err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	h.getList(w, r, id)
+}
+
+func (h *CraftingListHandler) deleteList(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.DeleteList(id); err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Crafting list deleted successfully"}`))
+}
+
+func (h *CraftingListHandler) calculateList(w http.ResponseWriter, r *http.Request, id int) {
+	// Calculation options are optional, an empty body uses the defaults
+	var options domain.CraftingRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := h.service.CalculateList(id, options)
+	if err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *CraftingListHandler) exportList(w http.ResponseWriter, r *http.Request, id int) {
+	format := exportFormat(r)
+
+	var buf bytes.Buffer
+	if err := h.exportService.ExportList(&buf, id, format); err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	writeExport(w, &buf, format)
+}
+
+func (h *CraftingListHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+		domain.CraftingRequest
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := exportFormat(r)
+
+	var buf bytes.Buffer
+	if err := h.exportService.Export(&buf, req.Name, req.CraftingRequest, format); err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	writeExport(w, &buf, format)
+}
+
+func (h *CraftingListHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Without an explicit format, CSV uploads are recognized by their content type
+	format := domain.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = domain.FormatJSON
+		if strings.Contains(r.Header.Get("Content-Type"), "csv") {
+			format = domain.FormatCSV
+		}
+	}
+
+	result, err := h.exportService.Import(r.Body, format)
+	if err != nil {
+		writeCraftingListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// exportFormat reads the ?format= parameter, defaulting to JSON
+func exportFormat(r *http.Request) domain.ExportFormat {
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		return domain.FormatJSON
+	case "md":
+		return domain.FormatMarkdown
+	default:
+		return domain.ExportFormat(format)
+	}
+}
+
+func writeExport(w http.ResponseWriter, buf *bytes.Buffer, format domain.ExportFormat) {
+	contentType, extension := "application/json", "json"
+	switch format {
+	case domain.FormatCSV:
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	case domain.FormatMarkdown:
+		contentType, extension = "text/markdown; charset=utf-8", "md"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="crafting-list.%s"`, extension))
+	buf.WriteTo(w)
+}
+
+func writeCraftingListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, "Crafting list not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrCraftingCycle):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "Crafting list operation failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
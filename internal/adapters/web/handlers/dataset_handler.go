@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type DatasetHandler struct {
+	service ports.DatasetService
+}
+
+func NewDatasetHandler(service ports.DatasetService) *DatasetHandler {
+	return &DatasetHandler{
+		service: service,
+	}
+}
+
+// HandleDataset reports the dataset status on GET and reloads the bundled
+// dataset on POST
+func (h *DatasetHandler) HandleDataset(w http.ResponseWriter, r *http.Request) {
+	var status *domain.DatasetStatus
+	var err error
+
+	switch r.Method {
+	case "GET":
+		status, err = h.service.GetStatus()
+	case "POST":
+		status, err = h.service.Load()
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidInput) {
+			code = http.StatusUnprocessableEntity
+		}
+		http.Error(w, "Failed to load dataset: "+err.Error(), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
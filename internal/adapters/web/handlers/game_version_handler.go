@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type GameVersionHandler struct {
+	service ports.GameVersionService
+}
+
+func NewGameVersionHandler(service ports.GameVersionService) *GameVersionHandler {
+	return &GameVersionHandler{
+		service: service,
+	}
+}
+
+func (h *GameVersionHandler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	versions, err := h.service.GetVersions()
+	if err != nil {
+		http.Error(w, "Failed to get game versions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// GetDiff compares the recipes of the from and to query parameters, to
+// defaulting to the current game version
+func (h *GameVersionHandler) GetDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	diff, err := h.service.Diff(query.Get("from"), query.Get("to"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, domain.ErrInvalidInput):
+			code = http.StatusBadRequest
+		}
+		http.Error(w, "Failed to compare game versions: "+err.Error(), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
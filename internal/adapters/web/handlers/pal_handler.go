@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type PalHandler struct {
+	service ports.PalService
+}
+
+func NewPalHandler(service ports.PalService) *PalHandler {
+	return &PalHandler{
+		service: service,
+	}
+}
+
+func (h *PalHandler) HandlePals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/pals"), "/")
+
+	switch path {
+	case "":
+		h.getPals(w, r)
+	case "search":
+		h.searchPals(w, r)
+	default:
+		id, err := strconv.Atoi(path)
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+		h.getPal(w, r, id)
+	}
+}
+
+func (h *PalHandler) getPals(w http.ResponseWriter, r *http.Request) {
+	pals, err := h.service.GetAllPals()
+	if err != nil {
+		http.Error(w, "Failed to get pals: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pals)
+}
+
+func (h *PalHandler) getPal(w http.ResponseWriter, r *http.Request, id int) {
+	pal, err := h.service.GetPal(id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Pal not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get pal: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pal)
+}
+
+func (h *PalHandler) searchPals(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := domain.PalQuery{
+		Name:     params.Get("name"),
+		Element:  params.Get("element"),
+		WorkType: params.Get("work"),
+		Drop:     params.Get("drop"),
+	}
+
+	if level := params.Get("min_level"); level != "" {
+		minLevel, err := strconv.Atoi(level)
+		if err != nil {
+			http.Error(w, "Invalid min_level", http.StatusBadRequest)
+			return
+		}
+		query.MinWorkLevel = minLevel
+	}
+
+	pals, err := h.service.SearchPals(query)
+	if err != nil {
+		http.Error(w, "Failed to search pals: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pals)
+}
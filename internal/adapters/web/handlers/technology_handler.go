@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type TechnologyHandler struct {
+	service ports.TechnologyService
+}
+
+func NewTechnologyHandler(service ports.TechnologyService) *TechnologyHandler {
+	return &TechnologyHandler{
+		service: service,
+	}
+}
+
+func (h *TechnologyHandler) HandleTechnologies(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/technologies"), "/")
+
+	switch r.Method {
+	case "GET":
+		h.getTechnologyTree(w, r)
+	case "PUT":
+		if path == "" {
+			http.Error(w, "Technology ID required", http.StatusBadRequest)
+			return
+		}
+		id, err := strconv.Atoi(path)
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+		h.setUnlocked(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *TechnologyHandler) getTechnologyTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.GetTechnologyTree()
+	if err != nil {
+		http.Error(w, "Failed to get technologies: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+func (h *TechnologyHandler) setUnlocked(w http.ResponseWriter, r *http.Request, id int) {
+	var req struct {
+		Unlocked bool `json:"unlocked"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.SetUnlocked(id, req.Unlocked)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Technology not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update technology: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Technology updated successfully"}`))
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "encoding/json"
+
+// AuditAction is the kind of change recorded in the audit log
+type AuditAction string
+
+const (
+	AuditInsert      AuditAction = "insert"
+	AuditUpdate      AuditAction = "update"
+	AuditDelete      AuditAction = "delete"
+	AuditCreateTable AuditAction = "create_table"
+	AuditAlterSchema AuditAction = "alter_schema"
+	AuditQuery       AuditAction = "query"
+	AuditImport      AuditAction = "import"
+	AuditBackup      AuditAction = "backup"
+	AuditRestore     AuditAction = "restore"
+)
+
+// AuditEntry records who changed what from the admin interface. Row changes
+// keep JSON snapshots of the row before and after the change.
+type AuditEntry struct {
+	ID        int             `json:"id" db:"id"`
+	UserID    *int            `json:"user_id,omitempty" db:"user_id"`
+	Username  string          `json:"username" db:"username"`
+	Action    AuditAction     `json:"action" db:"action"`
+	TableName string          `json:"table_name,omitempty" db:"table_name"`
+	RowID     string          `json:"row_id,omitempty" db:"row_id"`
+	Query     string          `json:"query,omitempty" db:"query"`
+	Before    json.RawMessage `json:"before,omitempty" db:"before_data"`
+	After     json.RawMessage `json:"after,omitempty" db:"after_data"`
+	CreatedAt string          `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter selects audit entries. Empty fields match everything. Since and
+// Until take a date or a UTC timestamp.
+type AuditFilter struct {
+	Username  string      `json:"username,omitempty"`
+	Action    AuditAction `json:"action,omitempty"`
+	TableName string      `json:"table_name,omitempty"`
+	RowID     string      `json:"row_id,omitempty"`
+	Since     string      `json:"since,omitempty"`
+	Until     string      `json:"until,omitempty"`
+	Limit     int         `json:"limit,omitempty"`
+}
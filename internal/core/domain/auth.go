@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Role grants access to the admin interface. Each role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can browse the schema and table data
+	RoleViewer Role = "viewer"
+	// RoleEditor can also insert, update and delete rows
+	RoleEditor Role = "editor"
+	// RoleAdmin can also run SQL, change the schema and manage users
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// Valid reports whether the role is one of the known roles
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// Allows reports whether the role grants the permissions of the required role
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// QueryPolicy decides who may run SQL from the admin query page
+type QueryPolicy struct {
+	// ReadRole may run read-only statements
+	ReadRole Role
+	// WriteRole may also run statements that change data or touch the account
+	// tables. Empty makes every query read-only.
+	WriteRole Role
+	// Timeout interrupts queries running longer. Zero lets them run until
+	// they finish or the request is cancelled.
+	Timeout time.Duration
+}
+
+// DefaultQueryPolicy lets every user read and only admins change data, and
+// stops queries after 30 seconds
+var DefaultQueryPolicy = QueryPolicy{ReadRole: RoleViewer, WriteRole: RoleAdmin, Timeout: 30 * time.Second}
+
+// User is a local account of the admin interface
+type User struct {
+	ID           int    `json:"id" db:"id"`
+	Username     string `json:"username" db:"username"`
+	PasswordHash string `json:"-" db:"password_hash"`
+	Role         Role   `json:"role" db:"role"`
+	CreatedAt    string `json:"created_at" db:"created_at"`
+}
+
+// Session is a logged in user. Only a hash of the token is stored.
+type Session struct {
+	Token     string    `json:"-"`
+	User      User      `json:"user"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Credentials is a login or user creation request
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     Role   `json:"role,omitempty"`
+}
+
+type userContextKey struct{}
+
+// WithUser returns a context carrying the authenticated user
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user of a context, or nil
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey{}).(*User)
+	return user
+}
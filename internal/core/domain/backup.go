@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// BackupKind tells why a backup was taken
+type BackupKind string
+
+const (
+	BackupManual     BackupKind = "manual"
+	BackupAutomatic  BackupKind = "auto"
+	BackupPreRestore BackupKind = "pre-restore"
+)
+
+// Backup is a snapshot of the database kept in the backup directory
+type Backup struct {
+	Name      string     `json:"name"`
+	Kind      BackupKind `json:"kind"`
+	Size      int64      `json:"size"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+// Dataset is the game data shipped with the application or imported from game
+// files. Records are matched by blueprint ID when they have one and by name
+// otherwise, so a newer dataset can be upserted over an existing database.
+type Dataset struct {
+	Version      string              `json:"version"`
+	GameVersion  string              `json:"game_version,omitempty"`
+	Source       string              `json:"source,omitempty"`
+	Resources    []DatasetResource   `json:"resources"`
+	Stations     []DatasetStation    `json:"stations"`
+	Recipes      []DatasetRecipe     `json:"recipes"`
+	Technologies []DatasetTechnology `json:"technologies"`
+	Pals         []DatasetPal        `json:"pals"`
+	// Breeding holds the special breeding combinations, which take
+	// precedence over the breeding power rule
+	Breeding []DatasetBreeding `json:"breeding_combinations"`
+}
+
+// Dataset sources, recorded with every load
+const (
+	DatasetSourceBundled   = "bundled"
+	DatasetSourceDataTable = "datatable"
+)
+
+// DatasetResource is a gatherable or craftable resource
+type DatasetResource struct {
+	Name        string `json:"name"`
+	BlueprintID string `json:"blueprint_id,omitempty"`
+}
+
+// DatasetStation is a crafting station and the recipe that builds it
+type DatasetStation struct {
+	Name   string `json:"name"`
+	Recipe string `json:"recipe,omitempty"`
+}
+
+// DatasetRecipe is a craftable item with its ingredients by resource name
+type DatasetRecipe struct {
+	Name        string         `json:"name"`
+	BlueprintID string         `json:"blueprint_id,omitempty"`
+	Category    string         `json:"category"`
+	Description string         `json:"description"`
+	Station     string         `json:"station,omitempty"`
+	Yield       int            `json:"yield,omitempty"`
+	WorkAmount  int            `json:"work_amount,omitempty"`
+	Resources   map[string]int `json:"resources"`
+}
+
+// DatasetTechnology is a technology tree entry and the recipes it unlocks
+type DatasetTechnology struct {
+	Name        string   `json:"name"`
+	BlueprintID string   `json:"blueprint_id,omitempty"`
+	Level       int      `json:"level"`
+	Points      int      `json:"points"`
+	Recipes     []string `json:"recipes"`
+}
+
+// DatasetPal is a paldeck entry
+type DatasetPal struct {
+	PaldeckNumber     int            `json:"paldeck_number"`
+	Variant           string         `json:"variant,omitempty"`
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	BreedingPower     int            `json:"breeding_power"`
+	Elements          []string       `json:"elements"`
+	WorkSuitabilities map[string]int `json:"work_suitabilities"`
+	Drops             []DatasetDrop  `json:"drops"`
+}
+
+// DatasetDrop is an item a pal drops, by resource name
+type DatasetDrop struct {
+	Resource    string  `json:"resource"`
+	MinQuantity int     `json:"min_quantity"`
+	MaxQuantity int     `json:"max_quantity"`
+	DropRate    float64 `json:"drop_rate"`
+}
+
+// DatasetBreeding is a special breeding combination, by pal name. The order
+// of the parents does not matter.
+type DatasetBreeding struct {
+	Parent1 string `json:"parent1"`
+	Parent2 string `json:"parent2"`
+	Child   string `json:"child"`
+}
+
+// DatasetStatus compares the bundled dataset with the one loaded in the database
+type DatasetStatus struct {
+	Source        string `json:"source"`
+	Version       string `json:"version"`
+	LoadedVersion string `json:"loaded_version,omitempty"`
+	LoadedAt      string `json:"loaded_at,omitempty"`
+	UpToDate      bool   `json:"up_to_date"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "errors"
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidInput is returned when a request contains values that cannot be used
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrCraftingCycle is returned when recipes reference each other in a loop
+var ErrCraftingCycle = errors.New("crafting cycle detected")
+
+// ErrNoBreedingPath is returned when a pal cannot be bred from the owned pals
+var ErrNoBreedingPath = errors.New("no breeding path found")
+
+// ErrUnauthorized is returned when a request has no valid session or credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden is returned when a user's role does not allow an operation
+var ErrForbidden = errors.New("forbidden")
+
+// ErrConflict is returned when a record clashes with an existing one
+var ErrConflict = errors.New("conflict")
+
+// ErrTimeout is returned when an operation runs longer than it is allowed to
+var ErrTimeout = errors.New("timeout")
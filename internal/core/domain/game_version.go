@@ -0,0 +1,48 @@
+package domain
+
+// GameVersion is a game version whose recipes were recorded when its data was loaded
+type GameVersion struct {
+	Version    string `json:"version"`
+	RecordedAt string `json:"recorded_at"`
+	Current    bool   `json:"current"`
+}
+
+// VersionDiff lists the recipes added, removed or rebalanced between two game
+// versions, and the resources and technologies added or removed
+type VersionDiff struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	Added        []string       `json:"added"`
+	Removed      []string       `json:"removed"`
+	Changed      []RecipeChange `json:"changed"`
+	Resources    RecordDiff     `json:"resources"`
+	Technologies RecordDiff     `json:"technologies"`
+}
+
+// RecordDiff lists the records added or removed between two game versions, by name
+type RecordDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// RecipeChange lists the ingredients of a recipe whose quantity changed
+type RecipeChange struct {
+	Recipe      string             `json:"recipe"`
+	Ingredients []IngredientChange `json:"ingredients"`
+}
+
+// IngredientChange is the quantity of an ingredient before and after a patch.
+// A quantity of zero means the ingredient was added or removed.
+type IngredientChange struct {
+	Resource string `json:"resource"`
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+}
+
+// VersionSnapshot holds the records of a game version: the ingredient
+// quantities of every recipe, and the resources and technologies, by name
+type VersionSnapshot struct {
+	Recipes      map[string]map[string]int
+	Resources    map[string]bool
+	Technologies map[string]bool
+}
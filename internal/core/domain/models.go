@@ -6,6 +6,23 @@ type CraftingRecipe struct {
 	Name        string `json:"name" db:"name"`
 	Category    string `json:"category" db:"category"`
 	Description string `json:"description" db:"description"`
+	StationID   *int   `json:"station_id,omitempty" db:"station_id"` // nil when crafted by hand or in build mode
+	Yield       int    `json:"yield" db:"yield"`                     // items produced by a single craft
+	WorkAmount  int    `json:"work_amount" db:"work_amount"`         // work needed for a single craft
+}
+
+// CraftingStation represents a structure where recipes are crafted
+type CraftingStation struct {
+	ID       int    `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	RecipeID *int   `json:"recipe_id,omitempty" db:"recipe_id"` // recipe building the station itself
+	Built    bool   `json:"built" db:"built"`
+}
+
+// StationRequirement represents a station needed by a crafting request
+type StationRequirement struct {
+	CraftingStation
+	Recipes []string `json:"recipes"`
 }
 
 // Resource represents a crafting resource
@@ -13,6 +30,7 @@ type Resource struct {
 	ID       int    `json:"id" db:"id"`
 	Name     string `json:"name" db:"name"`
 	Quantity int    `json:"quantity" db:"quantity"`
+	RecipeID *int   `json:"recipe_id,omitempty" db:"recipe_id"` // recipe producing this resource, nil for raw resources
 }
 
 // RecipeResource represents the relationship between recipes and resources
@@ -27,6 +45,14 @@ type RecipeResource struct {
 type RecipeWithResources struct {
 	CraftingRecipe
 	Resources []ResourceWithQuantity `json:"resources"`
+	Station   string                 `json:"station,omitempty"`
+	Locked    bool                   `json:"locked,omitempty"`
+}
+
+// RecipeQuery represents the options used when listing recipes
+type RecipeQuery struct {
+	FlagLocked bool // mark recipes whose technology is not unlocked yet
+	HideLocked bool // leave out recipes whose technology is not unlocked yet
 }
 
 // ResourceWithQuantity represents a resource with its required quantity
@@ -34,29 +60,156 @@ type ResourceWithQuantity struct {
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
 	Quantity int    `json:"quantity"`
+	RecipeID *int   `json:"recipe_id,omitempty"`
 }
 
 // CraftingRequest represents a request to calculate resources
 type CraftingRequest struct {
 	Items []CraftingItem `json:"items"`
+	// StopAtIntermediates keeps craftable resources (e.g. Ingot) in the
+	// totals instead of expanding them down to raw resources
+	StopAtIntermediates bool `json:"stop_at_intermediates"`
+	// UseInventory subtracts the resources held in the inventory table
+	UseInventory bool `json:"use_inventory"`
+	// WorkSpeed describes who crafts, used to estimate the crafting time
+	WorkSpeed *WorkSpeed `json:"work_speed,omitempty"`
+}
+
+// WorkSpeed represents the workers crafting a request
+type WorkSpeed struct {
+	PlayerSpeed float64       `json:"player_speed"`
+	Pals        []AssignedPal `json:"pals"`
+}
+
+// AssignedPal represents a pal assigned to crafting
+type AssignedPal struct {
+	Name           string `json:"name"`
+	HandiworkLevel int    `json:"handiwork_level"`
+}
+
+// Workload represents the estimated crafting effort of a request
+type Workload struct {
+	TotalWork int     `json:"total_work"`
+	WorkSpeed float64 `json:"work_speed"`
+	Seconds   float64 `json:"seconds"`
 }
 
 // CraftingItem represents an item in a crafting request
 type CraftingItem struct {
-	ID       int `json:"id"`
-	Quantity int `json:"quantity"`
+	ID       int    `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// CraftingList represents a named crafting list saved between sessions
+type CraftingList struct {
+	ID           int            `json:"id" db:"id"`
+	Name         string         `json:"name" db:"name"`
+	Items        []CraftingItem `json:"items"`
+	GameVersion  string         `json:"game_version,omitempty" db:"game_version"`
+	Stale        bool           `json:"stale"`
+	StaleRecipes []string       `json:"stale_recipes,omitempty"`
+	CreatedAt    string         `json:"created_at" db:"created_at"`
+	UpdatedAt    string         `json:"updated_at" db:"updated_at"`
+}
+
+// CraftingResult represents the outcome of a resource calculation
+type CraftingResult struct {
+	Resources  []ResourceTotal       `json:"resources"`
+	Crafts     []CraftingBatch       `json:"crafts"`
+	Workload   Workload              `json:"workload"`
+	Stations   []StationRequirement  `json:"stations"`
+	Technology TechnologyRequirement `json:"technology"`
+}
+
+// CraftingBatch represents how many times a recipe must be crafted
+type CraftingBatch struct {
+	RecipeID       int    `json:"recipe_id"`
+	Name           string `json:"name"`
+	Quantity       int    `json:"quantity"`
+	Yield          int    `json:"yield"`
+	Batches        int    `json:"batches"`
+	Produced       int    `json:"produced"`
+	Overproduction int    `json:"overproduction"`
+	Work           int    `json:"work"`
 }
 
 // ResourceTotal represents the total quantity needed for a resource
 type ResourceTotal struct {
-	Name  string `json:"name"`
-	Total int    `json:"total"`
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Owned     int    `json:"owned"`
+	Missing   int    `json:"missing"`
+	Craftable bool   `json:"craftable"`
+}
+
+// InventoryItem represents the quantity of a resource held by the player
+type InventoryItem struct {
+	ID           int    `json:"id" db:"id"`
+	ResourceID   int    `json:"resource_id" db:"resource_id"`
+	ResourceName string `json:"resource_name"`
+	Quantity     int    `json:"quantity" db:"quantity"`
+}
+
+// Technology represents an entry of the technology tree
+type Technology struct {
+	ID        int    `json:"id" db:"id"`
+	Name      string `json:"name" db:"name"`
+	Level     int    `json:"level" db:"level"`
+	Points    int    `json:"points" db:"points"`
+	RecipeIDs []int  `json:"recipe_ids"`
+	Unlocked  bool   `json:"unlocked"`
+}
+
+// TechnologyLevel groups the technologies available at a player level
+type TechnologyLevel struct {
+	Level        int          `json:"level"`
+	Technologies []Technology `json:"technologies"`
+}
+
+// TechnologyRequirement summarizes what must be unlocked to craft a set of recipes
+type TechnologyRequirement struct {
+	PlayerLevel  int          `json:"player_level"`
+	Points       int          `json:"points"`
+	Technologies []Technology `json:"technologies"`
+}
+
+// ExportFormat represents a file format used to import or export crafting
+// lists and admin tables
+type ExportFormat string
+
+const (
+	FormatJSON     ExportFormat = "json"
+	FormatCSV      ExportFormat = "csv"
+	FormatMarkdown ExportFormat = "markdown"
+	FormatNDJSON   ExportFormat = "ndjson"
+)
+
+// CraftingExport represents a crafting list together with its calculated resources
+type CraftingExport struct {
+	Name      string          `json:"name,omitempty"`
+	Items     []CraftingItem  `json:"items"`
+	Unknown   []CraftingItem  `json:"unknown,omitempty"` // items whose recipe no longer exists
+	Resources []ResourceTotal `json:"resources"`
+}
+
+// ImportResult represents the crafting items read from an imported file
+type ImportResult struct {
+	Items     []CraftingItem `json:"items"`
+	Unknown   []string       `json:"unknown"`   // recipe names not found in the database
+	Ambiguous []string       `json:"ambiguous"` // recipe names shared by several recipes
+	Invalid   []string       `json:"invalid"`   // lines that could not be read
 }
 
 // TableInfo represents database table information
 type TableInfo struct {
 	Name    string       `json:"name"`
 	Columns []ColumnInfo `json:"columns"`
+	// RowKey holds the columns addressing a row: the primary key in key
+	// order, or the rowid for tables without one
+	RowKey      []string         `json:"row_key"`
+	ForeignKeys []ForeignKeyInfo `json:"foreign_keys"`
+	Indexes     []IndexInfo      `json:"indexes"`
 }
 
 // ColumnInfo represents database column information
@@ -67,3 +220,216 @@ type ColumnInfo struct {
 	DefaultValue string `json:"default_value"`
 	PrimaryKey   bool   `json:"primary_key"`
 }
+
+// ForeignKeyInfo represents a foreign key from columns of a table to the
+// columns of a parent table
+type ForeignKeyInfo struct {
+	ID         int      `json:"id"`
+	Columns    []string `json:"columns"`
+	Table      string   `json:"table"`
+	References []string `json:"references"`
+	OnUpdate   string   `json:"on_update"`
+	OnDelete   string   `json:"on_delete"`
+}
+
+// IndexInfo represents a table index. Origin is "c" for indexes created with
+// CREATE INDEX, "u" for UNIQUE constraints and "pk" for primary keys.
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Origin  string   `json:"origin"`
+	Partial bool     `json:"partial"`
+	Columns []string `json:"columns"`
+}
+
+// ForeignKeyLookup lists the values a foreign key column accepts: the keys of
+// the parent table rows, labelled by one of their text columns
+type ForeignKeyLookup struct {
+	Table       string         `json:"table"`
+	Column      string         `json:"column"`
+	Parent      string         `json:"parent"`
+	References  string         `json:"references"`
+	LabelColumn string         `json:"label_column"`
+	Options     []LookupOption `json:"options"`
+	// Truncated tells that more parent rows match than the options hold
+	Truncated bool `json:"truncated"`
+}
+
+// LookupOption is a value of a foreign key column with its label
+type LookupOption struct {
+	Value interface{} `json:"value"`
+	Label string      `json:"label"`
+}
+
+// Schema change actions of the admin API
+const (
+	SchemaAddColumn    = "add_column"
+	SchemaRenameColumn = "rename_column"
+	SchemaAlterColumn  = "alter_column"
+	SchemaDropColumn   = "drop_column"
+	SchemaRenameTable  = "rename_table"
+	SchemaDropTable    = "drop_table"
+)
+
+// SchemaChange is a change to an existing table. ColumnName names the column
+// renamed, altered or dropped, Column defines the column added or the new
+// definition of the altered one, and NewName is the new name of a renamed
+// column or table.
+type SchemaChange struct {
+	Action     string      `json:"action"`
+	Table      string      `json:"table"`
+	ColumnName string      `json:"column_name,omitempty"`
+	Column     *ColumnInfo `json:"column,omitempty"`
+	NewName    string      `json:"new_name,omitempty"`
+}
+
+// SchemaPlan holds the statements applying a schema change. Changes SQLite
+// cannot make in place rebuild the table: its rows are copied to a new table
+// which then takes its name, and its indexes and triggers are created again.
+type SchemaPlan struct {
+	Change     SchemaChange `json:"change"`
+	Statements []string     `json:"statements"`
+	Rebuild    bool         `json:"rebuild"`
+	Warnings   []string     `json:"warnings"`
+	Applied    bool         `json:"applied"`
+}
+
+// TableSQL holds the statements defining a table and the indexes and
+// triggers attached to it, as stored by SQLite
+type TableSQL struct {
+	Create   string         `json:"create"`
+	Indexes  []SchemaObject `json:"indexes"`
+	Triggers []SchemaObject `json:"triggers"`
+}
+
+// SchemaObject is a named index or trigger with the statement creating it
+type SchemaObject struct {
+	Name string `json:"name"`
+	SQL  string `json:"sql"`
+}
+
+// Filter operators of admin table queries
+const (
+	FilterEqual        = "eq"
+	FilterNotEqual     = "ne"
+	FilterLess         = "lt"
+	FilterLessEqual    = "lte"
+	FilterGreater      = "gt"
+	FilterGreaterEqual = "gte"
+	FilterContains     = "contains"
+	FilterLike         = "like"
+	FilterNull         = "null"
+	FilterNotNull      = "notnull"
+)
+
+// ColumnFilter keeps the rows whose column matches a value with an operator
+type ColumnFilter struct {
+	Column   string `json:"column"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// TableQuery selects a page of table rows
+type TableQuery struct {
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Sort    string         `json:"sort,omitempty"`
+	Desc    bool           `json:"desc,omitempty"`
+	Filters []ColumnFilter `json:"filters,omitempty"`
+}
+
+// ImportMode tells how imported rows are written to a table
+type ImportMode string
+
+const (
+	// ImportInsert adds the rows, failing on rows whose key already exists
+	ImportInsert ImportMode = "insert"
+	// ImportUpsert adds the rows and updates those whose primary key exists
+	ImportUpsert ImportMode = "upsert"
+	// ImportReplace upserts the rows and deletes those whose primary key is
+	// not among them. A table without a primary key is emptied first, unless
+	// other tables reference it.
+	ImportReplace ImportMode = "replace"
+)
+
+// TableImportResult reports an import into an admin table. Rows are written in
+// a single transaction, committed only when no row failed and it is not a dry run.
+type TableImportResult struct {
+	Table     string     `json:"table"`
+	Mode      ImportMode `json:"mode"`
+	DryRun    bool       `json:"dry_run"`
+	Rows      int        `json:"rows"`
+	Imported  int        `json:"imported"`
+	Committed bool       `json:"committed"`
+	Errors    []RowError `json:"errors"`
+}
+
+// RowError is the reason a row of an imported file was rejected, rows being
+// numbered from 1
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// Batch operations of the admin API
+const (
+	BatchInsert = "insert"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one row edit of a batch. Key holds the values of the
+// primary key columns of the row to update or delete, in key order, or its
+// rowid for tables without a primary key.
+type BatchOperation struct {
+	Op    string                 `json:"op"`
+	Table string                 `json:"table"`
+	Key   []interface{}          `json:"key,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// BatchResult reports a batch. Its operations run in a single transaction,
+// committed only when none failed; Keys then holds the key of each edited row.
+type BatchResult struct {
+	Operations int           `json:"operations"`
+	Committed  bool          `json:"committed"`
+	Keys       []string      `json:"keys"`
+	Failed     *BatchFailure `json:"failed,omitempty"`
+}
+
+// BatchFailure is the operation that rolled a batch back, numbered from 0
+type BatchFailure struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	Table string `json:"table"`
+	Error string `json:"error"`
+}
+
+// Statement is a SQL statement with its arguments
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// TablePage is a page of table rows with the number of rows matching the filters
+type TablePage struct {
+	Rows   []map[string]interface{} `json:"rows"`
+	Total  int                      `json:"total"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+}
+
+// QueryPlan is the plan SQLite chose for a query, as reported by EXPLAIN
+// QUERY PLAN, with each step nested in the step it belongs to
+type QueryPlan struct {
+	Query string           `json:"query"`
+	Steps []*QueryPlanStep `json:"steps"`
+}
+
+// QueryPlanStep is a step of a query plan, such as a table scan or an index
+// search, with the steps it runs
+type QueryPlanStep struct {
+	ID       int              `json:"id"`
+	Detail   string           `json:"detail"`
+	Children []*QueryPlanStep `json:"children"`
+}
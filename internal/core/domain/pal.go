@@ -0,0 +1,105 @@
+package domain
+
+import "fmt"
+
+// Work suitability types a pal can have
+const (
+	WorkKindling     = "Kindling"
+	WorkWatering     = "Watering"
+	WorkPlanting     = "Planting"
+	WorkElectricity  = "Generating Electricity"
+	WorkHandiwork    = "Handiwork"
+	WorkGathering    = "Gathering"
+	WorkLumbering    = "Lumbering"
+	WorkMining       = "Mining"
+	WorkMedicine     = "Medicine Production"
+	WorkCooling      = "Cooling"
+	WorkTransporting = "Transporting"
+	WorkFarming      = "Farming"
+)
+
+// Pal represents a paldeck entry
+type Pal struct {
+	ID                int               `json:"id" db:"id"`
+	PaldeckNumber     int               `json:"paldeck_number" db:"paldeck_number"`
+	Variant           string            `json:"variant,omitempty" db:"variant"` // e.g. "B" for element variants such as 012B
+	Name              string            `json:"name" db:"name"`
+	Description       string            `json:"description" db:"description"`
+	BreedingPower     int               `json:"breeding_power,omitempty" db:"breeding_power"` // 0 when the pal cannot be bred
+	Elements          []string          `json:"elements"`
+	WorkSuitabilities []WorkSuitability `json:"work_suitabilities"`
+	Drops             []PalDrop         `json:"drops"`
+	ImagePath         string            `json:"image_path"`
+}
+
+// PaldeckCode returns the paldeck number as displayed in game, e.g. "085" or "012B"
+func (p Pal) PaldeckCode() string {
+	return fmt.Sprintf("%03d%s", p.PaldeckNumber, p.Variant)
+}
+
+// WorkSuitability represents a kind of base work a pal can do and its level
+type WorkSuitability struct {
+	Type  string `json:"type" db:"work_type"`
+	Level int    `json:"level" db:"level"`
+}
+
+// PalDrop represents an item dropped by a pal
+type PalDrop struct {
+	ResourceID  int     `json:"resource_id" db:"resource_id"`
+	Name        string  `json:"name"`
+	MinQuantity int     `json:"min_quantity" db:"min_quantity"`
+	MaxQuantity int     `json:"max_quantity" db:"max_quantity"`
+	DropRate    float64 `json:"drop_rate" db:"drop_rate"` // percentage between 0 and 100
+}
+
+// PalQuery represents the filters used when searching pals
+type PalQuery struct {
+	Name         string `json:"name"`
+	Element      string `json:"element"`
+	WorkType     string `json:"work_type"`
+	MinWorkLevel int    `json:"min_work_level"`
+	Drop         string `json:"drop"`
+}
+
+// PalRef represents a short reference to a pal
+type PalRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// BreedingCombination represents a special pair of parents overriding the breeding power rule
+type BreedingCombination struct {
+	Parent1ID int `json:"parent1_id" db:"parent1_id"`
+	Parent2ID int `json:"parent2_id" db:"parent2_id"`
+	ChildID   int `json:"child_id" db:"child_id"`
+}
+
+// BreedingResult represents the child of two parents
+type BreedingResult struct {
+	Parent1 PalRef `json:"parent1"`
+	Parent2 PalRef `json:"parent2"`
+	Child   Pal    `json:"child"`
+	Special bool   `json:"special"` // true when the child comes from a special combination
+}
+
+// BreedingPathRequest represents a request to find how to breed a pal
+type BreedingPathRequest struct {
+	Owned    []int `json:"owned"`
+	TargetID int   `json:"target_id"`
+}
+
+// BreedingStep represents a single breeding in a path
+type BreedingStep struct {
+	Generation int    `json:"generation"`
+	Parent1    PalRef `json:"parent1"`
+	Parent2    PalRef `json:"parent2"`
+	Child      PalRef `json:"child"`
+}
+
+// BreedingPath represents the breedings needed to obtain a pal, ordered so
+// every parent is owned or bred before it is used
+type BreedingPath struct {
+	Target      PalRef         `json:"target"`
+	Generations int            `json:"generations"`
+	Steps       []BreedingStep `json:"steps"`
+}
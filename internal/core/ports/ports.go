@@ -1,6 +1,12 @@
 package ports
 
-import "palworld-helper/internal/core/domain"
+import (
+	"context"
+	"io"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+)
 
 // CraftingRepository defines the interface for crafting data operations
 type CraftingRepository interface {
@@ -18,32 +24,236 @@ type CraftingRepository interface {
 	CreateRecipeResource(recipeResource *domain.RecipeResource) error
 	DeleteRecipeResource(recipeID, resourceID int) error
 	GetRecipeResources(recipeID int) ([]domain.ResourceWithQuantity, error)
+
+	GetInventory() ([]domain.InventoryItem, error)
+	// UpdateInventory sets the quantity of each item in one transaction,
+	// removing the items held at zero
+	UpdateInventory(items []domain.InventoryItem) error
+	DeleteInventoryItem(resourceID int) error
+
+	GetAllStations() ([]domain.CraftingStation, error)
+	SetStationBuilt(id int, built bool) error
+}
+
+// CraftingListRepository defines the interface for saved crafting list data operations
+type CraftingListRepository interface {
+	GetAllCraftingLists() ([]domain.CraftingList, error)
+	GetCraftingListByID(id int) (*domain.CraftingList, error)
+	CreateCraftingList(list *domain.CraftingList) error
+	UpdateCraftingList(list *domain.CraftingList) error
+	DeleteCraftingList(id int) error
+}
+
+// TechnologyRepository defines the interface for technology tree data operations
+type TechnologyRepository interface {
+	GetAllTechnologies() ([]domain.Technology, error)
+	SetTechnologyUnlocked(id int, unlocked bool) error
+}
+
+// PalRepository defines the interface for paldeck data operations
+type PalRepository interface {
+	GetAllPals() ([]domain.Pal, error)
+	GetPalByID(id int) (*domain.Pal, error)
+	SearchPals(query domain.PalQuery) ([]domain.Pal, error)
+	GetBreedingCombinations() ([]domain.BreedingCombination, error)
+}
+
+// DatasetSource defines the interface for reading the bundled game dataset
+type DatasetSource interface {
+	LoadDataset() (*domain.Dataset, error)
+}
+
+// DatasetRepository defines the interface for storing the game dataset
+type DatasetRepository interface {
+	GetDatasetVersion(source string) (version string, loadedAt string, err error)
+	UpsertDataset(dataset *domain.Dataset) error
+}
+
+// GameVersionRepository defines the interface for recorded game version data operations
+type GameVersionRepository interface {
+	GetGameVersions() ([]domain.GameVersion, error)
+	GetCurrentGameVersion() (string, error)
+	GetVersionSnapshot(version string) (*domain.VersionSnapshot, error)
+}
+
+// UserRepository defines the interface for admin user and session data operations
+type UserRepository interface {
+	GetAllUsers() ([]domain.User, error)
+	GetUserByID(id int) (*domain.User, error)
+	GetUserByUsername(username string) (*domain.User, error)
+	CreateUser(user *domain.User) error
+	// UpdateUser and DeleteUser return domain.ErrConflict rather than leave
+	// no admin, checking it in the same statement as the write
+	UpdateUser(user *domain.User) error
+	DeleteUser(id int) error
+	CountUsersWithRole(role domain.Role) (int, error)
+	CreateSession(tokenHash string, userID int, expiresAt time.Time) error
+	GetSessionUser(tokenHash string) (*domain.User, error)
+	DeleteSession(tokenHash string) error
+	DeleteUserSessions(userID int) error
+	DeleteExpiredSessions() error
+}
+
+// BackupRepository defines the interface for database snapshots
+type BackupRepository interface {
+	BackupTo(path string) error
+	CheckBackup(path string) error
+	RestoreFrom(path string) error
+}
+
+// AuditWriter appends entries to the audit log, on the database or inside a
+// transaction
+type AuditWriter interface {
+	CreateAuditEntry(entry *domain.AuditEntry) error
+}
+
+// AuditRepository defines the interface for audit log data operations
+type AuditRepository interface {
+	AuditWriter
+	GetAuditEntries(filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AdminStatements runs the statements of the admin table editor, on the
+// database or inside a transaction
+type AdminStatements interface {
+	ExecuteQuery(query string, args ...interface{}) ([]map[string]interface{}, error)
+	ExecuteNonQuery(query string, args ...interface{}) error
+	ExecuteInsert(query string, args ...interface{}) (int64, error)
+}
+
+// AdminTransaction runs statements inside a transaction and records their
+// audit entries in it, so that both are committed or rolled back together
+type AdminTransaction interface {
+	AdminStatements
+	AuditWriter
 }
 
 // AdminRepository defines the interface for admin operations
 type AdminRepository interface {
+	AdminStatements
 	GetTables() ([]string, error)
 	GetTableInfo(tableName string) (*domain.TableInfo, error)
-	ExecuteQuery(query string) ([]map[string]interface{}, error)
-	ExecuteNonQuery(query string, args ...interface{}) error
+	// ExecuteQueryContext and ExecuteReadOnlyQuery interrupt the query when
+	// the context is cancelled or reaches its deadline
+	ExecuteQueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+	ExecuteReadOnlyQuery(ctx context.Context, query string) ([]map[string]interface{}, error)
+	// StatementTables lists the tables a statement reads or writes, as SQLite
+	// compiles it, without running it
+	StatementTables(ctx context.Context, statement string) ([]string, error)
+	// InTransaction runs fn with statements inside one transaction, committed
+	// if fn returns nil and rolled back otherwise
+	InTransaction(fn func(tx AdminTransaction) error) error
+	QueryEach(query string, args []interface{}, fn func(values []interface{}) error) error
 	CreateTable(query string) error
 	DropTable(tableName string) error
+	GetTableSQL(tableName string) (*domain.TableSQL, error)
+	ApplySchemaChange(tableName string, statements []string, rebuild bool) error
 }
 
 // CraftingService defines the interface for crafting business logic
 type CraftingService interface {
-	GetAllRecipes() ([]domain.RecipeWithResources, error)
-	CalculateResources(request domain.CraftingRequest) ([]domain.ResourceTotal, error)
+	GetAllRecipes(query domain.RecipeQuery) ([]domain.RecipeWithResources, error)
+	CalculateResources(request domain.CraftingRequest) (*domain.CraftingResult, error)
 	GetCategories() ([]string, error)
+
+	GetInventory() ([]domain.InventoryItem, error)
+	UpdateInventory(items []domain.InventoryItem) error
+	DeleteInventoryItem(resourceID int) error
+
+	GetStations() ([]domain.CraftingStation, error)
+	SetStationBuilt(id int, built bool) error
+}
+
+// CraftingListService defines the interface for saved crafting list business logic
+type CraftingListService interface {
+	GetAllLists() ([]domain.CraftingList, error)
+	GetList(id int) (*domain.CraftingList, error)
+	CreateList(list *domain.CraftingList) error
+	UpdateList(list *domain.CraftingList) error
+	DeleteList(id int) error
+	CalculateList(id int, options domain.CraftingRequest) (*domain.CraftingResult, error)
+}
+
+// ExportService defines the interface for importing and exporting crafting lists
+type ExportService interface {
+	Export(w io.Writer, name string, request domain.CraftingRequest, format domain.ExportFormat) error
+	ExportList(w io.Writer, id int, format domain.ExportFormat) error
+	Import(r io.Reader, format domain.ExportFormat) (*domain.ImportResult, error)
+}
+
+// TechnologyService defines the interface for technology tree business logic
+type TechnologyService interface {
+	GetTechnologyTree() ([]domain.TechnologyLevel, error)
+	SetUnlocked(id int, unlocked bool) error
+}
+
+// PalService defines the interface for paldeck business logic
+type PalService interface {
+	GetAllPals() ([]domain.Pal, error)
+	GetPal(id int) (*domain.Pal, error)
+	SearchPals(query domain.PalQuery) ([]domain.Pal, error)
+}
+
+// BreedingService defines the interface for pal breeding business logic
+type BreedingService interface {
+	Breed(parent1ID, parent2ID int) (*domain.BreedingResult, error)
+	FindPath(request domain.BreedingPathRequest) (*domain.BreedingPath, error)
+}
+
+// DatasetService defines the interface for loading the game dataset
+type DatasetService interface {
+	GetStatus() (*domain.DatasetStatus, error)
+	Sync() (*domain.DatasetStatus, error)
+	Load() (*domain.DatasetStatus, error)
+	Import(dataset *domain.Dataset) (*domain.DatasetStatus, error)
+}
+
+// GameVersionService defines the interface for comparing game versions
+type GameVersionService interface {
+	GetVersions() ([]domain.GameVersion, error)
+	GetCurrentVersion() (string, error)
+	Diff(from, to string) (*domain.VersionDiff, error)
+}
+
+// AuthService defines the interface for admin authentication and user management
+type AuthService interface {
+	Login(credentials domain.Credentials) (*domain.Session, error)
+	Logout(token string) error
+	Authenticate(token string) (*domain.User, error)
+	GetUsers() ([]domain.User, error)
+	CreateUser(credentials domain.Credentials) (*domain.User, error)
+	UpdateUser(id int, credentials domain.Credentials) (*domain.User, error)
+	DeleteUser(id int) error
 }
 
 // AdminService defines the interface for admin business logic
+// Methods taking a context record the change in the audit log under the user
+// the context carries.
 type AdminService interface {
 	GetDatabaseSchema() ([]domain.TableInfo, error)
-	ExecuteQuery(query string) ([]map[string]interface{}, error)
-	GetTableData(tableName string) ([]map[string]interface{}, error)
-	CreateTable(tableName string, columns []domain.ColumnInfo) error
-	InsertData(tableName string, data map[string]interface{}) error
-	UpdateData(tableName string, id int, data map[string]interface{}) error
-	DeleteData(tableName string, id int) error
+	ExecuteQuery(ctx context.Context, query string) ([]map[string]interface{}, error)
+	ExplainQuery(ctx context.Context, query string) (*domain.QueryPlan, error)
+	GetTableData(tableName string, query domain.TableQuery) (*domain.TablePage, error)
+	LookupForeignKey(tableName, column, search string, limit int) (*domain.ForeignKeyLookup, error)
+	CreateTable(ctx context.Context, tableName string, columns []domain.ColumnInfo) error
+	PlanSchemaChange(change domain.SchemaChange) (*domain.SchemaPlan, error)
+	ApplySchemaChange(ctx context.Context, change domain.SchemaChange) (*domain.SchemaPlan, error)
+	InsertData(ctx context.Context, tableName string, data map[string]interface{}) error
+	UpdateData(ctx context.Context, tableName string, key []string, data map[string]interface{}) error
+	DeleteData(ctx context.Context, tableName string, key []string) error
+	ApplyBatch(ctx context.Context, operations []domain.BatchOperation) (*domain.BatchResult, error)
+	GetAuditLog(filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	ExportTable(w io.Writer, tableName string, format domain.ExportFormat) error
+	ImportTable(ctx context.Context, r io.Reader, tableName string, format domain.ExportFormat, mode domain.ImportMode, dryRun bool) (*domain.TableImportResult, error)
+}
+
+// BackupService defines the interface for database backups. Methods taking a
+// context record the operation in the audit log.
+type BackupService interface {
+	CreateBackup(ctx context.Context, kind domain.BackupKind) (*domain.Backup, error)
+	ListBackups() ([]domain.Backup, error)
+	OpenBackup(name string) (io.ReadSeekCloser, *domain.Backup, error)
+	DeleteBackup(ctx context.Context, name string) error
+	RestoreBackup(ctx context.Context, name string) error
+	RestoreUpload(ctx context.Context, r io.Reader) error
 }
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// maxBatchOperations caps the number of operations of a batch
+const maxBatchOperations = 1000
+
+// ApplyBatch applies insert, update and delete operations in order inside a
+// single transaction. The first operation failing rolls the whole batch back
+// and is reported in the result; the operations are recorded in the audit log
+// inside the same transaction, so that a batch rolled back leaves no entries.
+func (s *adminService) ApplyBatch(ctx context.Context, operations []domain.BatchOperation) (*domain.BatchResult, error) {
+	if len(operations) == 0 || len(operations) > maxBatchOperations {
+		return nil, fmt.Errorf("%w: a batch holds between 1 and %d operations", domain.ErrInvalidInput, maxBatchOperations)
+	}
+
+	result := &domain.BatchResult{Operations: len(operations), Keys: []string{}}
+	fail := func(i int, err error) (*domain.BatchResult, error) {
+		result.Keys = []string{}
+		result.Failed = &domain.BatchFailure{
+			Index: i,
+			Op:    operations[i].Op,
+			Table: operations[i].Table,
+			Error: err.Error(),
+		}
+		return result, nil
+	}
+
+	// Tables are looked up before the transaction starts, so that a batch
+	// naming a missing table does not write anything
+	tables := make([]*domain.TableInfo, len(operations))
+	for i, operation := range operations {
+		table, err := s.getTable(operation.Table)
+		if err == nil {
+			err = checkBatchOperation(operation)
+		}
+		if err != nil {
+			return fail(i, err)
+		}
+		tables[i] = table
+	}
+
+	failed := -1
+	err := s.repo.InTransaction(func(tx ports.AdminTransaction) error {
+		for i, operation := range operations {
+			var entry *domain.AuditEntry
+			var err error
+			switch operation.Op {
+			case domain.BatchInsert:
+				entry, err = insertRow(tx, tables[i], operation.Data)
+			case domain.BatchUpdate:
+				entry, err = updateRow(tx, tables[i], operation.Key, operation.Data)
+			case domain.BatchDelete:
+				entry, err = deleteRow(tx, tables[i], operation.Key)
+			}
+			if err != nil {
+				failed = i
+				return err
+			}
+			if err := recordAudit(ctx, tx, entry); err != nil {
+				return err
+			}
+			result.Keys = append(result.Keys, entry.RowID)
+		}
+		return nil
+	})
+	if failed >= 0 {
+		return fail(failed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+// checkBatchOperation checks an operation has the key and data its kind needs
+func checkBatchOperation(operation domain.BatchOperation) error {
+	switch operation.Op {
+	case domain.BatchInsert:
+		if len(operation.Key) > 0 {
+			return fmt.Errorf("%w: insert operations take data, not a key", domain.ErrInvalidInput)
+		}
+	case domain.BatchUpdate:
+		if len(operation.Key) == 0 {
+			return fmt.Errorf("%w: update operations need the key of the row", domain.ErrInvalidInput)
+		}
+	case domain.BatchDelete:
+		if len(operation.Key) == 0 {
+			return fmt.Errorf("%w: delete operations need the key of the row", domain.ErrInvalidInput)
+		}
+		if len(operation.Data) > 0 {
+			return fmt.Errorf("%w: delete operations take a key, not data", domain.ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operation %q, expected insert, update or delete", domain.ErrInvalidInput, operation.Op)
+	}
+	return nil
+}
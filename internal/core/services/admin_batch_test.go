@@ -0,0 +1,115 @@
+package services
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// countAudit counts the audit entries of an action
+func countAudit(t *testing.T, service *adminService, action domain.AuditAction) int {
+	t.Helper()
+	entries, err := service.GetAuditLog(domain.AuditFilter{Action: action})
+	if err != nil {
+		t.Fatalf("GetAuditLog() error = %v", err)
+	}
+	return len(entries)
+}
+
+// countResources counts the rows of the resources table
+func countResources(t *testing.T, service *adminService) int64 {
+	t.Helper()
+	rows, err := service.repo.ExecuteQuery("SELECT count(*) AS n FROM resources")
+	if err != nil {
+		t.Fatalf("counting resources: %v", err)
+	}
+	n, _ := rows[0]["n"].(int64)
+	return n
+}
+
+// refuseAudit makes every write to the audit log fail
+func refuseAudit(t *testing.T, service *adminService) {
+	t.Helper()
+	err := service.repo.ExecuteNonQuery("CREATE TRIGGER refuse_audit BEFORE INSERT ON audit_log BEGIN SELECT RAISE(ABORT, 'audit refused'); END")
+	if err != nil {
+		t.Fatalf("creating trigger: %v", err)
+	}
+}
+
+func TestApplyBatchAudit(t *testing.T) {
+	insert := func(name string) domain.BatchOperation {
+		return domain.BatchOperation{Op: domain.BatchInsert, Table: "resources", Data: map[string]interface{}{"name": name}}
+	}
+
+	t.Run("committed", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		result, err := service.ApplyBatch(userContext(domain.RoleAdmin), []domain.BatchOperation{insert("Wood"), insert("Stone")})
+		if err != nil {
+			t.Fatalf("ApplyBatch() error = %v", err)
+		}
+		if !result.Committed || len(result.Keys) != 2 {
+			t.Fatalf("ApplyBatch() = %+v, want 2 rows committed", result)
+		}
+		if n := countAudit(t, service, domain.AuditInsert); n != 2 {
+			t.Errorf("%d insert entries in the audit log, want 2", n)
+		}
+	})
+
+	t.Run("rolled back", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		result, err := service.ApplyBatch(userContext(domain.RoleAdmin), []domain.BatchOperation{insert("Wood"), insert("Wood")})
+		if err != nil {
+			t.Fatalf("ApplyBatch() error = %v", err)
+		}
+		if result.Committed || result.Failed == nil || result.Failed.Index != 1 {
+			t.Fatalf("ApplyBatch() = %+v, want the second operation failed", result)
+		}
+		if n := countAudit(t, service, domain.AuditInsert); n != 0 {
+			t.Errorf("%d insert entries in the audit log of a rolled back batch", n)
+		}
+	})
+
+	t.Run("audit refused", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		refuseAudit(t, service)
+		if _, err := service.ApplyBatch(userContext(domain.RoleAdmin), []domain.BatchOperation{insert("Wood")}); err == nil {
+			t.Fatal("ApplyBatch() succeeded without its audit entries")
+		}
+		if n := countResources(t, service); n != 0 {
+			t.Errorf("%d resources written without their audit entries", n)
+		}
+	})
+	t.Run("quiet", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		inserted, err := service.ApplyBatch(userContext(domain.RoleAdmin), []domain.BatchOperation{insert("Wood"), insert("Stone")})
+		if err != nil || !inserted.Committed {
+			t.Fatalf("ApplyBatch() = %+v, %v", inserted, err)
+		}
+		operations := []domain.BatchOperation{
+			insert("Fiber"),
+			{Op: domain.BatchUpdate, Table: "resources", Key: []interface{}{inserted.Keys[0]}, Data: map[string]interface{}{"name": "Lumber"}},
+			{Op: domain.BatchDelete, Table: "resources", Key: []interface{}{inserted.Keys[1]}},
+		}
+
+		// Writes are recorded in the audit log, not printed once per row
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout = w
+		result, err := service.ApplyBatch(userContext(domain.RoleAdmin), operations)
+		os.Stdout = stdout
+		w.Close()
+		if err != nil || !result.Committed {
+			t.Fatalf("ApplyBatch() = %+v, %v", result, err)
+		}
+
+		printed, _ := io.ReadAll(r)
+		if len(printed) > 0 {
+			t.Errorf("ApplyBatch() printed %q", printed)
+		}
+	})
+}
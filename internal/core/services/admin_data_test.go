@@ -0,0 +1,477 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// firstResourceKey returns the key of the first row of the resources table
+func firstResourceKey(t *testing.T, service *adminService) []string {
+	t.Helper()
+	rows, err := service.repo.ExecuteQuery("SELECT id FROM resources ORDER BY id LIMIT 1")
+	if err != nil || len(rows) == 0 {
+		t.Fatalf("reading a resource key: %v", err)
+	}
+	id, _ := rows[0]["id"].(int64)
+	return []string{strconv.FormatInt(id, 10)}
+}
+
+func TestWriteDataAudit(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+
+	t.Run("recorded", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		if err := service.InsertData(ctx, "resources", map[string]interface{}{"name": "Wood"}); err != nil {
+			t.Fatalf("InsertData() error = %v", err)
+		}
+		key := firstResourceKey(t, service)
+		if err := service.UpdateData(ctx, "resources", key, map[string]interface{}{"name": "Lumber"}); err != nil {
+			t.Fatalf("UpdateData() error = %v", err)
+		}
+		if err := service.DeleteData(ctx, "resources", key); err != nil {
+			t.Fatalf("DeleteData() error = %v", err)
+		}
+		for _, action := range []domain.AuditAction{domain.AuditInsert, domain.AuditUpdate, domain.AuditDelete} {
+			if n := countAudit(t, service, action); n != 1 {
+				t.Errorf("%d %s entries in the audit log, want 1", n, action)
+			}
+		}
+	})
+
+	t.Run("audit refused", func(t *testing.T) {
+		service := newTestAdminService(t, domain.DefaultQueryPolicy)
+		if err := service.InsertData(ctx, "resources", map[string]interface{}{"name": "Wood"}); err != nil {
+			t.Fatalf("InsertData() error = %v", err)
+		}
+		key := firstResourceKey(t, service)
+		refuseAudit(t, service)
+
+		if err := service.InsertData(ctx, "resources", map[string]interface{}{"name": "Stone"}); err == nil {
+			t.Error("InsertData() succeeded without its audit entry")
+		}
+		if n := countResources(t, service); n != 1 {
+			t.Errorf("%d resources after a refused insert, want 1", n)
+		}
+
+		if err := service.UpdateData(ctx, "resources", key, map[string]interface{}{"name": "Lumber"}); err == nil {
+			t.Error("UpdateData() succeeded without its audit entry")
+		}
+		rows, err := service.repo.ExecuteQuery("SELECT name FROM resources")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := rows[0]["name"]; name != "Wood" {
+			t.Errorf("name = %v after a refused update, want Wood", name)
+		}
+
+		if err := service.DeleteData(ctx, "resources", key); err == nil {
+			t.Error("DeleteData() succeeded without its audit entry")
+		}
+		if n := countResources(t, service); n != 1 {
+			t.Errorf("%d resources after a refused delete, want 1", n)
+		}
+
+		columns := []domain.ColumnInfo{{Name: "id", Type: "INTEGER", PrimaryKey: true}, {Name: "label", Type: "TEXT"}}
+		if err := service.CreateTable(ctx, "notes", columns); err == nil {
+			t.Error("CreateTable() succeeded without its audit entry")
+		}
+		if _, err := service.getTable("notes"); err == nil {
+			t.Error("table notes created without its audit entry")
+		}
+	})
+}
+
+// newKeyTestService returns an admin service over a database holding a table
+// created and filled by the given statements
+func newKeyTestService(t *testing.T, statements ...string) *adminService {
+	t.Helper()
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+	for _, statement := range statements {
+		if err := service.repo.ExecuteNonQuery(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return service
+}
+
+// queryValues reads the first column of every row of a query
+func queryValues(t *testing.T, service *adminService, query string) []interface{} {
+	t.Helper()
+	rows, err := service.repo.ExecuteQuery(query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	values := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		for _, value := range row {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// lastRowID returns the row id recorded by the newest audit entry of an action
+func lastRowID(t *testing.T, service *adminService, action domain.AuditAction) string {
+	t.Helper()
+	entries, err := service.GetAuditLog(domain.AuditFilter{Action: action, Limit: 1})
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("GetAuditLog(%s) = %v, %v", action, entries, err)
+	}
+	return entries[0].RowID
+}
+
+func TestWriteDataCompositeKey(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+	service := newKeyTestService(t,
+		"CREATE TABLE drops (pal TEXT, item TEXT, rate INTEGER, PRIMARY KEY (pal, item))",
+		"INSERT INTO drops VALUES ('Lamball', 'Wool', 1), ('Lamball', 'Meat', 2), ('Cattiva', 'Wool', 3)",
+	)
+
+	if err := service.UpdateData(ctx, "drops", []string{"Lamball", "Wool"}, map[string]interface{}{"rate": 5}); err != nil {
+		t.Fatalf("UpdateData() error = %v", err)
+	}
+	got := queryValues(t, service, "SELECT rate FROM drops ORDER BY pal, item")
+	if !reflect.DeepEqual(got, []interface{}{int64(3), int64(2), int64(5)}) {
+		t.Errorf("rates = %v, want only Lamball/Wool updated", got)
+	}
+	if id := lastRowID(t, service, domain.AuditUpdate); id != "Lamball/Wool" {
+		t.Errorf("audit row id = %q, want Lamball/Wool", id)
+	}
+
+	if err := service.DeleteData(ctx, "drops", []string{"Lamball", "Meat"}); err != nil {
+		t.Fatalf("DeleteData() error = %v", err)
+	}
+	got = queryValues(t, service, "SELECT pal || '/' || item FROM drops ORDER BY pal, item")
+	if !reflect.DeepEqual(got, []interface{}{"Cattiva/Wool", "Lamball/Wool"}) {
+		t.Errorf("rows = %v, want Lamball/Meat deleted", got)
+	}
+
+	if err := service.UpdateData(ctx, "drops", []string{"Lamball"}, map[string]interface{}{"rate": 1}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("UpdateData() with half a key error = %v, want ErrInvalidInput", err)
+	}
+	if err := service.DeleteData(ctx, "drops", []string{"Cattiva", "Meat"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteData() of a missing row error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWriteDataTextKey(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+	service := newKeyTestService(t,
+		"CREATE TABLE codes (code TEXT PRIMARY KEY, label TEXT)",
+		"INSERT INTO codes VALUES ('010', 'padded'), ('10', 'plain')",
+	)
+
+	// The key is compared as text, so 010 does not match 10
+	if err := service.UpdateData(ctx, "codes", []string{"010"}, map[string]interface{}{"label": "renamed"}); err != nil {
+		t.Fatalf("UpdateData() error = %v", err)
+	}
+	got := queryValues(t, service, "SELECT label FROM codes ORDER BY code")
+	if !reflect.DeepEqual(got, []interface{}{"renamed", "plain"}) {
+		t.Errorf("labels = %v, want only 010 updated", got)
+	}
+
+	if err := service.DeleteData(ctx, "codes", []string{"10"}); err != nil {
+		t.Fatalf("DeleteData() error = %v", err)
+	}
+	got = queryValues(t, service, "SELECT code FROM codes")
+	if !reflect.DeepEqual(got, []interface{}{"010"}) {
+		t.Errorf("codes = %v, want only 10 deleted", got)
+	}
+	if id := lastRowID(t, service, domain.AuditDelete); id != "10" {
+		t.Errorf("audit row id = %q, want 10", id)
+	}
+
+	if err := service.DeleteData(ctx, "codes", []string{"0010"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteData() of a missing row error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWriteDataRowID(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+	service := newKeyTestService(t,
+		"CREATE TABLE notes (body TEXT)",
+		"INSERT INTO notes VALUES ('first'), ('second'), ('third')",
+	)
+
+	if err := service.UpdateData(ctx, "notes", []string{"2"}, map[string]interface{}{"body": "edited"}); err != nil {
+		t.Fatalf("UpdateData() error = %v", err)
+	}
+	got := queryValues(t, service, "SELECT body FROM notes ORDER BY rowid")
+	if !reflect.DeepEqual(got, []interface{}{"first", "edited", "third"}) {
+		t.Errorf("bodies = %v, want only rowid 2 updated", got)
+	}
+	if id := lastRowID(t, service, domain.AuditUpdate); id != "2" {
+		t.Errorf("audit row id = %q, want 2", id)
+	}
+
+	if err := service.DeleteData(ctx, "notes", []string{"1"}); err != nil {
+		t.Fatalf("DeleteData() error = %v", err)
+	}
+	got = queryValues(t, service, "SELECT body FROM notes ORDER BY rowid")
+	if !reflect.DeepEqual(got, []interface{}{"edited", "third"}) {
+		t.Errorf("bodies = %v, want rowid 1 deleted", got)
+	}
+
+	if err := service.DeleteData(ctx, "notes", []string{"1"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteData() of a deleted row error = %v, want ErrNotFound", err)
+	}
+	if err := service.UpdateData(ctx, "notes", []string{"2", "3"}, map[string]interface{}{"body": "x"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("UpdateData() with two key values error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestLookupForeignKey(t *testing.T) {
+	service := newKeyTestService(t,
+		"CREATE TABLE breeds (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE items (code TEXT PRIMARY KEY, weight INTEGER, title TEXT)",
+		"CREATE TABLE levels (n INTEGER PRIMARY KEY)",
+		"CREATE TABLE regions (code TEXT, zone INTEGER, PRIMARY KEY (code, zone))",
+		`CREATE TABLE spawns (
+			id INTEGER PRIMARY KEY,
+			breed_id INTEGER REFERENCES breeds(id),
+			item_code TEXT REFERENCES items(code),
+			level INTEGER REFERENCES levels(n),
+			code TEXT,
+			zone INTEGER,
+			note TEXT,
+			FOREIGN KEY (code, zone) REFERENCES regions (code, zone)
+		)`,
+		"INSERT INTO breeds (id, name) VALUES (1, 'Lamball'), (2, 'Cattiva'), (3, 'Chikipi'), (4, 'Lifmunk'), (5, '100% Lamb')",
+		"INSERT INTO items VALUES ('wool', 1, 'Wool'), ('ingot', 5, 'Ingot')",
+		"INSERT INTO levels VALUES (2), (1)",
+	)
+
+	tests := []struct {
+		name          string
+		column        string
+		search        string
+		limit         int
+		wantLabel     string
+		wantOptions   []domain.LookupOption
+		wantTruncated bool
+	}{
+		{
+			name:      "name column",
+			column:    "breed_id",
+			wantLabel: "name",
+			wantOptions: []domain.LookupOption{
+				{Value: int64(5), Label: "100% Lamb"}, {Value: int64(2), Label: "Cattiva"}, {Value: int64(3), Label: "Chikipi"},
+				{Value: int64(1), Label: "Lamball"}, {Value: int64(4), Label: "Lifmunk"},
+			},
+		},
+		{
+			name:      "search",
+			column:    "breed_id",
+			search:    "lamb",
+			wantLabel: "name",
+			wantOptions: []domain.LookupOption{
+				{Value: int64(5), Label: "100% Lamb"}, {Value: int64(1), Label: "Lamball"},
+			},
+		},
+		{
+			name:        "search for a wildcard",
+			column:      "breed_id",
+			search:      "%",
+			wantLabel:   "name",
+			wantOptions: []domain.LookupOption{{Value: int64(5), Label: "100% Lamb"}},
+		},
+		{
+			name:      "truncated",
+			column:    "breed_id",
+			search:    "i",
+			limit:     2,
+			wantLabel: "name",
+			wantOptions: []domain.LookupOption{
+				{Value: int64(2), Label: "Cattiva"}, {Value: int64(3), Label: "Chikipi"},
+			},
+			wantTruncated: true,
+		},
+		{
+			name:      "exactly the limit",
+			column:    "breed_id",
+			search:    "i",
+			limit:     3,
+			wantLabel: "name",
+			wantOptions: []domain.LookupOption{
+				{Value: int64(2), Label: "Cattiva"}, {Value: int64(3), Label: "Chikipi"}, {Value: int64(4), Label: "Lifmunk"},
+			},
+		},
+		{
+			name:      "first other text column",
+			column:    "item_code",
+			wantLabel: "title",
+			wantOptions: []domain.LookupOption{
+				{Value: "ingot", Label: "Ingot"}, {Value: "wool", Label: "Wool"},
+			},
+		},
+		{
+			name:      "no text column",
+			column:    "level",
+			wantLabel: "n",
+			wantOptions: []domain.LookupOption{
+				{Value: int64(1), Label: "1"}, {Value: int64(2), Label: "2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup, err := service.LookupForeignKey("spawns", tt.column, tt.search, tt.limit)
+			if err != nil {
+				t.Fatalf("LookupForeignKey() error = %v", err)
+			}
+			if lookup.LabelColumn != tt.wantLabel {
+				t.Errorf("label column = %s, want %s", lookup.LabelColumn, tt.wantLabel)
+			}
+			if !reflect.DeepEqual(lookup.Options, tt.wantOptions) {
+				t.Errorf("options = %v, want %v", lookup.Options, tt.wantOptions)
+			}
+			if lookup.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", lookup.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name   string
+		column string
+		limit  int
+	}{
+		{name: "unknown column", column: "missing"},
+		{name: "not a foreign key", column: "note"},
+		{name: "foreign key on several columns", column: "zone"},
+		{name: "limit too large", column: "breed_id", limit: maxLookupLimit + 1},
+		{name: "negative limit", column: "breed_id", limit: -1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.LookupForeignKey("spawns", tt.column, "", tt.limit); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("LookupForeignKey() error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+// pageIDs returns the id column of the rows of a table page
+func pageIDs(page *domain.TablePage) []int64 {
+	ids := make([]int64, len(page.Rows))
+	for i, row := range page.Rows {
+		ids[i], _ = row["id"].(int64)
+	}
+	return ids
+}
+
+func TestGetTableData(t *testing.T) {
+	service := newKeyTestService(t,
+		"CREATE TABLE mons (id INTEGER PRIMARY KEY, name TEXT, level INTEGER)",
+		`INSERT INTO mons (id, name, level) VALUES
+			(1, 'Lamball', 3), (2, 'Cattiva', 1), (3, 'Chikipi', 3), (4, 'Lifmunk', 2),
+			(5, '100% Lamb', 3), (6, 'Foxparks', NULL), (7, 'Foxparks_Cryst', 1)`,
+	)
+
+	t.Run("pages", func(t *testing.T) {
+		page, err := service.GetTableData("mons", domain.TableQuery{})
+		if err != nil {
+			t.Fatalf("GetTableData() error = %v", err)
+		}
+		if page.Limit != defaultTablePageSize || page.Offset != 0 || page.Total != 7 || len(page.Rows) != 7 {
+			t.Errorf("page = limit %d offset %d total %d rows %d, want the 7 rows on a default page",
+				page.Limit, page.Offset, page.Total, len(page.Rows))
+		}
+
+		page, err = service.GetTableData("mons", domain.TableQuery{Limit: 2, Offset: 6})
+		if err != nil {
+			t.Fatalf("GetTableData() error = %v", err)
+		}
+		if ids := pageIDs(page); page.Total != 7 || !reflect.DeepEqual(ids, []int64{7}) {
+			t.Errorf("last page = %v of %d, want [7] of 7", ids, page.Total)
+		}
+
+		page, err = service.GetTableData("mons", domain.TableQuery{Limit: 2, Offset: 10})
+		if err != nil {
+			t.Fatalf("GetTableData() error = %v", err)
+		}
+		if page.Rows == nil || len(page.Rows) != 0 {
+			t.Errorf("rows past the end = %v, want an empty list", page.Rows)
+		}
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		for _, query := range []domain.TableQuery{
+			{Limit: -1},
+			{Limit: maxTablePageSize + 1},
+			{Offset: -1},
+			{Sort: "missing"},
+			{Filters: []domain.ColumnFilter{{Column: "missing", Operator: domain.FilterEqual}}},
+		} {
+			if _, err := service.GetTableData("mons", query); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("GetTableData(%+v) error = %v, want ErrInvalidInput", query, err)
+			}
+		}
+		if _, err := service.GetTableData("mons", domain.TableQuery{Limit: maxTablePageSize}); err != nil {
+			t.Errorf("GetTableData() at the largest limit error = %v", err)
+		}
+		if _, err := service.GetTableData("missing", domain.TableQuery{}); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetTableData() of a missing table error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter domain.ColumnFilter
+			want   []int64
+		}{
+			{"equal", domain.ColumnFilter{Column: "level", Operator: domain.FilterEqual, Value: "3"}, []int64{1, 3, 5}},
+			{"not equal", domain.ColumnFilter{Column: "level", Operator: domain.FilterNotEqual, Value: "3"}, []int64{2, 4, 7}},
+			{"less", domain.ColumnFilter{Column: "level", Operator: domain.FilterLess, Value: "2"}, []int64{2, 7}},
+			{"less or equal", domain.ColumnFilter{Column: "level", Operator: domain.FilterLessEqual, Value: "2"}, []int64{2, 4, 7}},
+			{"greater", domain.ColumnFilter{Column: "level", Operator: domain.FilterGreater, Value: "2"}, []int64{1, 3, 5}},
+			{"greater or equal", domain.ColumnFilter{Column: "level", Operator: domain.FilterGreaterEqual, Value: "2"}, []int64{1, 3, 4, 5}},
+			{"contains", domain.ColumnFilter{Column: "name", Operator: domain.FilterContains, Value: "lamb"}, []int64{1, 5}},
+			{"contains a percent sign", domain.ColumnFilter{Column: "name", Operator: domain.FilterContains, Value: "%"}, []int64{5}},
+			{"contains an underscore", domain.ColumnFilter{Column: "name", Operator: domain.FilterContains, Value: "_"}, []int64{7}},
+			{"like", domain.ColumnFilter{Column: "name", Operator: domain.FilterLike, Value: "L%"}, []int64{1, 4}},
+			{"like single character", domain.ColumnFilter{Column: "name", Operator: domain.FilterLike, Value: "Foxpark_"}, []int64{6}},
+			{"null", domain.ColumnFilter{Column: "level", Operator: domain.FilterNull}, []int64{6}},
+			{"not null", domain.ColumnFilter{Column: "level", Operator: domain.FilterNotNull}, []int64{1, 2, 3, 4, 5, 7}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := service.GetTableData("mons", domain.TableQuery{Filters: []domain.ColumnFilter{tt.filter}})
+				if err != nil {
+					t.Fatalf("GetTableData() error = %v", err)
+				}
+				if ids := pageIDs(page); !reflect.DeepEqual(ids, tt.want) || page.Total != len(tt.want) {
+					t.Errorf("rows = %v of %d, want %v", ids, page.Total, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("ties", func(t *testing.T) {
+		// Rows of the same level come in key order, so the pages of a sort
+		// neither repeat nor skip rows
+		for _, tt := range []struct {
+			desc bool
+			want []int64
+		}{
+			{false, []int64{6, 2, 7, 4, 1, 3, 5}},
+			{true, []int64{1, 3, 5, 4, 2, 7, 6}},
+		} {
+			var ids []int64
+			for offset := 0; offset < 7; offset += 2 {
+				page, err := service.GetTableData("mons", domain.TableQuery{Limit: 2, Offset: offset, Sort: "level", Desc: tt.desc})
+				if err != nil {
+					t.Fatalf("GetTableData() error = %v", err)
+				}
+				ids = append(ids, pageIDs(page)...)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("pages sorted by level (desc %v) = %v, want %v", tt.desc, ids, tt.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// ExplainQuery returns the plan SQLite would follow to run a single
+// statement, without running it. Users may explain the queries they may run.
+func (s *adminService) ExplainQuery(ctx context.Context, query string) (*domain.QueryPlan, error) {
+	summary, err := summarizeSQL(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(summary.statements) > 1 {
+		return nil, fmt.Errorf("%w: only one statement can be explained at a time", domain.ErrInvalidInput)
+	}
+
+	if _, err := s.authorizeQuery(ctx, summary); err != nil {
+		return nil, err
+	}
+
+	queryCtx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	// EXPLAIN never runs the statement, so the read-only connection does for
+	// every user
+	explain := "EXPLAIN QUERY PLAN " + query
+	rows, err := s.repo.ExecuteReadOnlyQuery(queryCtx, explain)
+	if err != nil {
+		return nil, s.queryError(queryCtx, err)
+	}
+
+	// Each row names the step it belongs to, which SQLite lists before it
+	plan := &domain.QueryPlan{Query: query, Steps: []*domain.QueryPlanStep{}}
+	steps := make(map[int64]*domain.QueryPlanStep)
+	for _, row := range rows {
+		id, _ := row["id"].(int64)
+		parentID, _ := row["parent"].(int64)
+		detail, _ := row["detail"].(string)
+
+		step := &domain.QueryPlanStep{ID: int(id), Detail: detail, Children: []*domain.QueryPlanStep{}}
+		steps[id] = step
+		if parent, ok := steps[parentID]; ok && parentID != 0 {
+			parent.Children = append(parent.Children, step)
+		} else {
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+
+	if err := s.record(ctx, &domain.AuditEntry{Action: domain.AuditQuery, Query: explain}); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// authorizeQuery checks the user of ctx may run a query under the policy,
+// and tells whether they may change data. The protected tables are refused by
+// name, then by asking SQLite which tables each statement reads, which also
+// catches views and names SQLite resolves in ways the tokenizer does not.
+func (s *adminService) authorizeQuery(ctx context.Context, summary *sqlSummary) (bool, error) {
+	var role domain.Role
+	if user := domain.UserFromContext(ctx); user != nil {
+		role = user.Role
+	}
+	if !role.Allows(s.policy.ReadRole) {
+		return false, fmt.Errorf("%w: the %s role is required to run queries", domain.ErrForbidden, s.policy.ReadRole)
+	}
+
+	privileged := s.policy.WriteRole != "" && role.Allows(s.policy.WriteRole)
+	if !privileged {
+		if !summary.readOnly {
+			return false, fmt.Errorf("%w: queries are read-only, only SELECT, WITH, VALUES and EXPLAIN statements are allowed", domain.ErrForbidden)
+		}
+		for table := range protectedTables {
+			if summary.names[table] {
+				return false, fmt.Errorf("%w: table %s cannot be queried", domain.ErrForbidden, table)
+			}
+		}
+
+		for _, statement := range summary.statements {
+			// EXPLAIN lists the program of a statement and reads no rows
+			if statement.keyword == "EXPLAIN" {
+				continue
+			}
+			tables, err := s.repo.StatementTables(ctx, statement.sql)
+			if err != nil {
+				return false, err
+			}
+			for _, table := range tables {
+				if _, ok := protectedTables[strings.ToLower(table)]; ok {
+					return false, fmt.Errorf("%w: table %s cannot be queried", domain.ErrForbidden, table)
+				}
+			}
+		}
+	}
+	return privileged, nil
+}
+
+// queryContext bounds a query by the timeout of the policy, if any
+func (s *adminService) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.policy.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.policy.Timeout)
+}
+
+// queryError reports a query interrupted by the policy timeout as
+// domain.ErrTimeout and one whose request was cancelled as context.Canceled
+func (s *adminService) queryError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: query ran longer than %s and was interrupted", domain.ErrTimeout, s.policy.Timeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("query was cancelled: %w", context.Canceled)
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// endlessQuery counts the rows of a recursive CTE that never ends
+const endlessQuery = "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT count(*) FROM n"
+
+func TestExecuteQueryInterrupted(t *testing.T) {
+	policy := domain.DefaultQueryPolicy
+	policy.Timeout = 50 * time.Millisecond
+
+	// Admins run queries on the read-write connection, viewers on the
+	// read-only one, and both are interrupted
+	for _, role := range []domain.Role{domain.RoleAdmin, domain.RoleViewer} {
+		t.Run(string(role)+" timeout", func(t *testing.T) {
+			service := newTestAdminService(t, policy)
+			start := time.Now()
+			_, err := service.ExecuteQuery(userContext(role), endlessQuery)
+			if !errors.Is(err, domain.ErrTimeout) {
+				t.Fatalf("ExecuteQuery() error = %v, want ErrTimeout", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("ExecuteQuery() returned after %s", elapsed)
+			}
+		})
+
+		t.Run(string(role)+" cancelled", func(t *testing.T) {
+			service := newTestAdminService(t, domain.DefaultQueryPolicy)
+			ctx, cancel := context.WithCancel(userContext(role))
+			time.AfterFunc(50*time.Millisecond, cancel)
+			_, err := service.ExecuteQuery(ctx, endlessQuery)
+			if !errors.Is(err, context.Canceled) || errors.Is(err, domain.ErrTimeout) {
+				t.Fatalf("ExecuteQuery() error = %v, want context.Canceled", err)
+			}
+		})
+	}
+
+	t.Run("not audited", func(t *testing.T) {
+		service := newTestAdminService(t, policy)
+		if _, err := service.ExecuteQuery(userContext(domain.RoleAdmin), endlessQuery); err == nil {
+			t.Fatal("ExecuteQuery() of an endless query succeeded")
+		}
+		if n := countAudit(t, service, domain.AuditQuery); n != 0 {
+			t.Errorf("%d query entries for an interrupted query, want none", n)
+		}
+	})
+}
+
+// planOutline writes the steps of a plan one per line, indented by depth
+func planOutline(b *strings.Builder, steps []*domain.QueryPlanStep, depth int) {
+	for _, step := range steps {
+		b.WriteString(strings.Repeat("  ", depth) + step.Detail + "\n")
+		planOutline(b, step.Children, depth+1)
+	}
+}
+
+func TestExplainQueryPlanTree(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+	query := "SELECT name FROM resources WHERE id IN (SELECT resource_id FROM recipe_resources) UNION SELECT name FROM crafting_recipes"
+
+	plan, err := service.ExplainQuery(userContext(domain.RoleViewer), query)
+	if err != nil {
+		t.Fatalf("ExplainQuery() error = %v", err)
+	}
+
+	var outline strings.Builder
+	planOutline(&outline, plan.Steps, 0)
+	want := `COMPOUND QUERY
+  LEFT-MOST SUBQUERY
+    SEARCH resources USING INTEGER PRIMARY KEY (rowid=?)
+    LIST SUBQUERY 1
+      SCAN recipe_resources USING COVERING INDEX sqlite_autoindex_recipe_resources_1
+      CREATE BLOOM FILTER
+  UNION USING TEMP B-TREE
+    SCAN crafting_recipes
+`
+	if outline.String() != want {
+		t.Errorf("plan =\n%s\nwant\n%s", outline.String(), want)
+	}
+
+	// Steps of the top level come in the order SQLite lists them
+	plan, err = service.ExplainQuery(userContext(domain.RoleViewer),
+		"SELECT name FROM resources WHERE id = (SELECT max(resource_id) FROM recipe_resources)")
+	if err != nil {
+		t.Fatalf("ExplainQuery() error = %v", err)
+	}
+	if len(plan.Steps) != 2 || !strings.HasPrefix(plan.Steps[0].Detail, "SEARCH resources") ||
+		plan.Steps[1].Detail != "SCALAR SUBQUERY 1" || len(plan.Steps[1].Children) != 1 {
+		outline.Reset()
+		planOutline(&outline, plan.Steps, 0)
+		t.Errorf("plan =\n%s\nwant the search of resources, then the subquery holding its own step", outline.String())
+	}
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// tableConstraintKeywords start the table constraints of a CREATE TABLE
+var tableConstraintKeywords = map[string]bool{
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+	"UNIQUE":     true,
+	"CHECK":      true,
+	"FOREIGN":    true,
+}
+
+// tableDefinition is a CREATE TABLE statement split into its column
+// definitions and table constraints
+type tableDefinition struct {
+	items []tableItem
+	// tail follows the closing parenthesis, such as WITHOUT ROWID
+	tail string
+}
+
+// tableItem is a column definition or a table constraint
+type tableItem struct {
+	sql string
+	// column is the name of the defined column, empty for table constraints
+	column string
+	// names holds every name and keyword of the item, lowercased
+	names map[string]bool
+}
+
+// PlanSchemaChange builds the statements of a schema change without running
+// them, so they can be previewed
+func (s *adminService) PlanSchemaChange(change domain.SchemaChange) (*domain.SchemaPlan, error) {
+	table, err := s.getTable(change.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &domain.SchemaPlan{Change: change, Warnings: []string{}}
+	name := quoteIdentifier(table.Name)
+
+	switch change.Action {
+	case domain.SchemaRenameTable:
+		if err := checkIdentifier("table", change.NewName); err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(change.NewName, table.Name) {
+			if _, err := s.getTable(change.NewName); err == nil {
+				return nil, fmt.Errorf("%w: table %s already exists", domain.ErrConflict, change.NewName)
+			}
+		}
+		plan.Statements = []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", name, quoteIdentifier(change.NewName))}
+
+	case domain.SchemaDropTable:
+		// Dropping a table deletes its rows first, which the foreign keys
+		// of the tables referencing it cascade to, set to NULL or refuse
+		children, err := s.referencingTables(table.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("table %s is referenced by %s, whose rows referencing it are deleted, set to NULL or block the drop as their foreign keys define",
+				table.Name, strings.Join(children, ", ")))
+		}
+		plan.Statements = []string{"DROP TABLE " + name}
+
+	case domain.SchemaRenameColumn:
+		column, err := schemaColumn(table, change.ColumnName)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIdentifier("column", change.NewName); err != nil {
+			return nil, err
+		}
+		if existing := findColumn(table, change.NewName); existing != nil && existing != column {
+			return nil, fmt.Errorf("%w: table %s already has a column %s", domain.ErrConflict, table.Name, existing.Name)
+		}
+		plan.Statements = []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+			name, quoteIdentifier(column.Name), quoteIdentifier(change.NewName))}
+
+	case domain.SchemaAddColumn:
+		if change.Column == nil {
+			return nil, fmt.Errorf("%w: the column to add is required", domain.ErrInvalidInput)
+		}
+		colDef, err := columnDefinition(*change.Column)
+		if err != nil {
+			return nil, err
+		}
+		if findColumn(table, change.Column.Name) != nil {
+			return nil, fmt.Errorf("%w: table %s already has a column %s", domain.ErrConflict, table.Name, change.Column.Name)
+		}
+
+		// Existing rows would get NULL in a NOT NULL column without default,
+		// unless it is an INTEGER PRIMARY KEY which SQLite fills with rowids
+		rowIDKey := change.Column.PrimaryKey && strings.EqualFold(change.Column.Type, "INTEGER")
+		if change.Column.NotNull && change.Column.DefaultValue == "" && !rowIDKey {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("column %s is NOT NULL without a default, so it can only be added to an empty table", change.Column.Name))
+			filled, err := s.hasRows(table)
+			if err != nil {
+				return nil, err
+			}
+			if filled {
+				return nil, fmt.Errorf("%w: table %s has rows, which would have no value for the NOT NULL column %s, give it a default",
+					domain.ErrInvalidInput, table.Name, change.Column.Name)
+			}
+		}
+
+		// ALTER TABLE ADD COLUMN cannot add keys, nor give existing rows a
+		// value other than a constant default
+		if !change.Column.PrimaryKey && !(change.Column.NotNull && change.Column.DefaultValue == "") &&
+			!strings.HasPrefix(strings.ToUpper(change.Column.DefaultValue), "CURRENT_") {
+			plan.Statements = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", name, colDef)}
+			break
+		}
+
+		err = s.planRebuild(plan, table, func(def *tableDefinition) error {
+			last := 0
+			for i, item := range def.items {
+				if item.column != "" {
+					last = i + 1
+				}
+			}
+			item := tableItem{sql: colDef, column: change.Column.Name}
+			def.items = append(def.items[:last], append([]tableItem{item}, def.items[last:]...)...)
+			return nil
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.SchemaAlterColumn:
+		column, err := schemaColumn(table, change.ColumnName)
+		if err != nil {
+			return nil, err
+		}
+		if change.Column == nil {
+			return nil, fmt.Errorf("%w: the new definition of column %s is required", domain.ErrInvalidInput, column.Name)
+		}
+		altered := *change.Column
+		if altered.Name == "" {
+			altered.Name = column.Name
+		}
+		if !strings.EqualFold(altered.Name, column.Name) {
+			return nil, fmt.Errorf("%w: alter_column keeps the column name, use rename_column to rename it", domain.ErrInvalidInput)
+		}
+		colDef, err := columnDefinition(altered)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.planRebuild(plan, table, func(def *tableDefinition) error {
+			for i, item := range def.items {
+				if strings.EqualFold(item.column, column.Name) {
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("column %s was defined as %s", column.Name, item.sql))
+					def.items[i] = tableItem{sql: colDef, column: altered.Name}
+				}
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.SchemaDropColumn:
+		column, err := schemaColumn(table, change.ColumnName)
+		if err != nil {
+			return nil, err
+		}
+		if len(table.Columns) == 1 {
+			return nil, fmt.Errorf("%w: %s is the only column of %s, drop the table instead", domain.ErrInvalidInput, column.Name, table.Name)
+		}
+
+		inPlace, err := s.canDropInPlace(table, column)
+		if err != nil {
+			return nil, err
+		}
+		if inPlace {
+			plan.Statements = []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", name, quoteIdentifier(column.Name))}
+			break
+		}
+
+		err = s.planRebuild(plan, table, func(def *tableDefinition) error {
+			var items []tableItem
+			for _, item := range def.items {
+				switch {
+				case strings.EqualFold(item.column, column.Name):
+				case item.column == "" && item.names[strings.ToLower(column.Name)]:
+					return fmt.Errorf("%w: column %s is used by the table constraint %s", domain.ErrInvalidInput, column.Name, item.sql)
+				default:
+					items = append(items, item)
+				}
+			}
+			def.items = items
+			return nil
+		}, column)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: unknown schema change %q", domain.ErrInvalidInput, change.Action)
+	}
+
+	return plan, nil
+}
+
+// ApplySchemaChange plans a schema change and runs it in a single transaction
+func (s *adminService) ApplySchemaChange(ctx context.Context, change domain.SchemaChange) (*domain.SchemaPlan, error) {
+	plan, err := s.PlanSchemaChange(change)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ApplySchemaChange(change.Table, plan.Statements, plan.Rebuild); err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+
+	err = s.record(ctx, &domain.AuditEntry{
+		Action:    domain.AuditAlterSchema,
+		TableName: plan.Change.Table,
+		Query:     strings.Join(plan.Statements, ";\n"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planRebuild fills a plan with the statements rebuilding a table: edit
+// changes its definition, then the rows are copied to a new table which takes
+// the name of the old one. The indexes and triggers are created again, except
+// the indexes using the dropped column, if any.
+func (s *adminService) planRebuild(plan *domain.SchemaPlan, table *domain.TableInfo, edit func(def *tableDefinition) error, dropped *domain.ColumnInfo) error {
+	tableSQL, err := s.repo.GetTableSQL(table.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read definition of %s: %w", table.Name, err)
+	}
+	def, err := parseTableDefinition(tableSQL.Create)
+	if err != nil {
+		return err
+	}
+	if err := edit(def); err != nil {
+		return err
+	}
+
+	tables, err := s.repo.GetTables()
+	if err != nil {
+		return fmt.Errorf("failed to get tables list: %w", err)
+	}
+	rebuilt := table.Name + "_rebuild"
+	for taken := true; taken; {
+		taken = false
+		for _, existing := range tables {
+			if strings.EqualFold(existing, rebuilt) {
+				rebuilt += "_"
+				taken = true
+			}
+		}
+	}
+
+	items := make([]string, len(def.items))
+	for i, item := range def.items {
+		items[i] = item.sql
+	}
+	create := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(rebuilt), strings.Join(items, ", "))
+	if def.tail != "" {
+		create += " " + def.tail
+	}
+
+	var columns []string
+	for _, column := range table.Columns {
+		if dropped == nil || column.Name != dropped.Name {
+			columns = append(columns, quoteIdentifier(column.Name))
+		}
+	}
+	list := strings.Join(columns, ", ")
+
+	plan.Rebuild = true
+	plan.Statements = []string{
+		create,
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", quoteIdentifier(rebuilt), list, list, quoteIdentifier(table.Name)),
+		"DROP TABLE " + quoteIdentifier(table.Name),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdentifier(rebuilt), quoteIdentifier(table.Name)),
+	}
+
+	for _, index := range tableSQL.Indexes {
+		if dropped != nil {
+			names, err := sqlNames(index.SQL)
+			if err != nil {
+				return err
+			}
+			if names[strings.ToLower(dropped.Name)] {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("index %s uses column %s and is dropped", index.Name, dropped.Name))
+				continue
+			}
+		}
+		plan.Statements = append(plan.Statements, index.SQL)
+	}
+	for _, trigger := range tableSQL.Triggers {
+		plan.Statements = append(plan.Statements, trigger.SQL)
+	}
+
+	return nil
+}
+
+// canDropInPlace tells whether ALTER TABLE DROP COLUMN can drop a column: it
+// must not be a key, unique, a foreign key, nor be used by a table
+// constraint or an index
+func (s *adminService) canDropInPlace(table *domain.TableInfo, column *domain.ColumnInfo) (bool, error) {
+	if column.PrimaryKey {
+		return false, nil
+	}
+
+	tableSQL, err := s.repo.GetTableSQL(table.Name)
+	if err != nil {
+		return false, fmt.Errorf("failed to read definition of %s: %w", table.Name, err)
+	}
+	def, err := parseTableDefinition(tableSQL.Create)
+	if err != nil {
+		return false, err
+	}
+
+	name := strings.ToLower(column.Name)
+	for _, item := range def.items {
+		if strings.EqualFold(item.column, column.Name) {
+			if item.names["unique"] || item.names["references"] || item.names["primary"] {
+				return false, nil
+			}
+		} else if item.column == "" && item.names[name] {
+			return false, nil
+		}
+	}
+	for _, index := range tableSQL.Indexes {
+		names, err := sqlNames(index.SQL)
+		if err != nil {
+			return false, err
+		}
+		if names[name] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// hasRows tells whether a table holds at least one row
+func (s *adminService) hasRows(table *domain.TableInfo) (bool, error) {
+	rows, err := s.repo.ExecuteQuery("SELECT EXISTS (SELECT 1 FROM " + quoteIdentifier(table.Name) + ") AS filled")
+	if err != nil {
+		return false, fmt.Errorf("failed to count rows of %s: %w", table.Name, err)
+	}
+	filled, _ := rows[0]["filled"].(int64)
+	return filled == 1, nil
+}
+
+// schemaColumn finds the column a schema change applies to
+func schemaColumn(table *domain.TableInfo, name string) (*domain.ColumnInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: column_name is required", domain.ErrInvalidInput)
+	}
+	column := findColumn(table, name)
+	if column == nil {
+		return nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, table.Name, name)
+	}
+	return column, nil
+}
+
+// parseTableDefinition splits the CREATE TABLE statement SQLite stored for a
+// table at the top-level commas of its parentheses
+func parseTableDefinition(createSQL string) (*tableDefinition, error) {
+	tokens, err := tokenizeSQL(createSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	def := &tableDefinition{}
+	depth, itemStart := 0, -1
+	for i, token := range tokens {
+		switch {
+		case token.is("("):
+			depth++
+			if depth == 1 {
+				itemStart = i + 1
+			}
+		case token.is(")"):
+			depth--
+			if depth == 0 {
+				if err := def.add(createSQL, tokens[itemStart:i]); err != nil {
+					return nil, err
+				}
+				def.tail = strings.TrimSpace(createSQL[token.end:])
+				return def, nil
+			}
+		case token.is(",") && depth == 1:
+			if err := def.add(createSQL, tokens[itemStart:i]); err != nil {
+				return nil, err
+			}
+			itemStart = i + 1
+		}
+	}
+
+	return nil, fmt.Errorf("cannot read the table definition %s", createSQL)
+}
+
+// add appends the item made of the given tokens
+func (def *tableDefinition) add(createSQL string, tokens []sqlToken) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("cannot read the table definition %s", createSQL)
+	}
+
+	item := tableItem{
+		sql:   createSQL[tokens[0].start:tokens[len(tokens)-1].end],
+		names: make(map[string]bool),
+	}
+	for _, token := range tokens {
+		if token.isName() {
+			item.names[strings.ToLower(token.name)] = true
+		}
+	}
+	if first := tokens[0]; first.kind == tokenQuoted || (first.kind == tokenWord && !tableConstraintKeywords[strings.ToUpper(first.name)]) {
+		item.column = first.name
+	}
+
+	def.items = append(def.items, item)
+	return nil
+}
+
+// sqlNames returns every name and keyword of a statement, lowercased
+func sqlNames(statement string) (map[string]bool, error) {
+	tokens, err := tokenizeSQL(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, token := range tokens {
+		if token.isName() {
+			names[strings.ToLower(token.name)] = true
+		}
+	}
+	return names, nil
+}
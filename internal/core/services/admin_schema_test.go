@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func TestPlanAddNotNullColumn(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+	admin := userContext(domain.RoleAdmin)
+	for _, query := range []string{
+		"CREATE TABLE filled (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO filled (name) VALUES ('a')",
+		"CREATE TABLE empty (id INTEGER PRIMARY KEY, name TEXT)",
+	} {
+		if _, err := service.ExecuteQuery(admin, query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		table       string
+		column      domain.ColumnInfo
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"filled table", "filled", domain.ColumnInfo{Name: "code", Type: "TEXT", NotNull: true}, true, true},
+		{"empty table", "empty", domain.ColumnInfo{Name: "code", Type: "TEXT", NotNull: true}, false, true},
+		{"with a default", "filled", domain.ColumnInfo{Name: "code", Type: "TEXT", NotNull: true, DefaultValue: "x"}, false, false},
+		{"nullable", "filled", domain.ColumnInfo{Name: "code", Type: "TEXT"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := domain.SchemaChange{Action: domain.SchemaAddColumn, Table: tt.table, Column: &tt.column}
+			plan, err := service.PlanSchemaChange(change)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("PlanSchemaChange() error = %v, want domain.ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanSchemaChange() error = %v", err)
+			}
+
+			warned := false
+			for _, warning := range plan.Warnings {
+				warned = warned || strings.Contains(warning, "NOT NULL")
+			}
+			if warned != tt.wantWarning {
+				t.Errorf("warnings = %v, want a NOT NULL warning: %v", plan.Warnings, tt.wantWarning)
+			}
+
+			if _, err := service.ApplySchemaChange(admin, change); err != nil {
+				t.Errorf("ApplySchemaChange() error = %v", err)
+			}
+			if _, err := service.ExecuteQuery(admin, "ALTER TABLE "+tt.table+" DROP COLUMN code"); err != nil {
+				t.Fatalf("dropping column: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlanDropReferencedTable(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+
+	tests := []struct {
+		table        string
+		wantChildren []string
+	}{
+		{"resources", []string{"recipe_resources", "inventory", "pal_drops"}},
+		{"inventory", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			plan, err := service.PlanSchemaChange(domain.SchemaChange{Action: domain.SchemaDropTable, Table: tt.table})
+			if err != nil {
+				t.Fatalf("PlanSchemaChange() error = %v", err)
+			}
+			if len(tt.wantChildren) == 0 {
+				if len(plan.Warnings) > 0 {
+					t.Errorf("warnings = %v, want none", plan.Warnings)
+				}
+				return
+			}
+			if len(plan.Warnings) != 1 {
+				t.Fatalf("warnings = %v, want one listing the referencing tables", plan.Warnings)
+			}
+			for _, child := range tt.wantChildren {
+				if !strings.Contains(plan.Warnings[0], child) {
+					t.Errorf("warning %q does not name %s", plan.Warnings[0], child)
+				}
+			}
+		})
+	}
+}
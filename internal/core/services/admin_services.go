@@ -1,21 +1,52 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"palworld-helper/internal/core/domain"
 	"palworld-helper/internal/core/ports"
 )
 
+// protectedTables are kept out of the generic table editor, with the page
+// managing them. Editing accounts as raw rows would let editors raise their
+// role, and editing the audit log would defeat it.
+var protectedTables = map[string]string{
+	"users":     "the users page",
+	"sessions":  "the users page",
+	"audit_log": "the audit log page",
+}
+
+const (
+	// maxAuditLimit caps the number of audit entries returned at once
+	maxAuditLimit = 1000
+
+	// Number of table rows returned by default and at most in one page
+	defaultTablePageSize = 50
+	maxTablePageSize     = 1000
+
+	// Number of foreign key options returned by default and at most
+	defaultLookupLimit = 100
+	maxLookupLimit     = 1000
+)
+
 type adminService struct {
-	repo ports.AdminRepository
+	repo   ports.AdminRepository
+	audit  ports.AuditRepository
+	policy domain.QueryPolicy
 }
 
-// NewAdminService creates a new admin service
-func NewAdminService(repo ports.AdminRepository) ports.AdminService {
+// NewAdminService creates a new admin service recording changes in the audit
+// log and running raw SQL under the given policy
+func NewAdminService(repo ports.AdminRepository, audit ports.AuditRepository, policy domain.QueryPolicy) ports.AdminService {
 	return &adminService{
-		repo: repo,
+		repo:   repo,
+		audit:  audit,
+		policy: policy,
 	}
 }
 
@@ -38,98 +69,250 @@ func (s *adminService) GetDatabaseSchema() ([]domain.TableInfo, error) {
 	return tableInfos, nil
 }
 
-// ExecuteQuery executes a raw SQL query
-func (s *adminService) ExecuteQuery(query string) ([]map[string]interface{}, error) {
-	return s.repo.ExecuteQuery(query)
+// ExecuteQuery executes a raw SQL query. Users below the write role of the
+// policy run it on a read-only connection and cannot touch the protected tables.
+// The query is interrupted when ctx is cancelled or the policy timeout expires.
+func (s *adminService) ExecuteQuery(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	summary, err := summarizeSQL(query)
+	if err != nil {
+		return nil, err
+	}
+
+	privileged, err := s.authorizeQuery(ctx, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	queryCtx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	var results []map[string]interface{}
+	if privileged {
+		results, err = s.repo.ExecuteQueryContext(queryCtx, query)
+	} else {
+		results, err = s.repo.ExecuteReadOnlyQuery(queryCtx, query)
+	}
+	if err != nil {
+		return nil, s.queryError(queryCtx, err)
+	}
+
+	if err := s.record(ctx, &domain.AuditEntry{Action: domain.AuditQuery, Query: query}); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-// GetTableData retrieves all data from a specific table
-func (s *adminService) GetTableData(tableName string) ([]map[string]interface{}, error) {
-	tables, err := s.repo.GetTables()
+// GetTableData retrieves a page of rows from a specific table, filtered and
+// sorted on its columns
+func (s *adminService) GetTableData(tableName string, query domain.TableQuery) (*domain.TablePage, error) {
+	table, err := s.getTable(tableName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tables list: %w", err)
+		return nil, err
 	}
 
-	tableExists := false
-	for _, table := range tables {
-		if table == tableName {
-			tableExists = true
-			break
-		}
+	if query.Limit == 0 {
+		query.Limit = defaultTablePageSize
+	}
+	if query.Limit < 0 || query.Limit > maxTablePageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxTablePageSize)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset cannot be negative", domain.ErrInvalidInput)
 	}
 
-	if !tableExists {
-		return nil, fmt.Errorf("table '%s' does not exist", tableName)
+	where, args, err := filterClause(table, query.Filters)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := orderClause(table, query.Sort, query.Desc)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Getting data from table: %s\n", tableName)
+	from := quoteIdentifier(table.Name) + where
+	counts, err := s.repo.ExecuteQuery("SELECT COUNT(*) AS total FROM "+from, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows of %s: %w", table.Name, err)
+	}
 
-	query := fmt.Sprintf("SELECT * FROM %s", tableName)
-	data, err := s.repo.ExecuteQuery(query)
+	rowsQuery := fmt.Sprintf("SELECT %s FROM %s%s LIMIT ? OFFSET ?", selectColumns(table), from, orderBy)
+	rows, err := s.repo.ExecuteQuery(rowsQuery, append(args, query.Limit, query.Offset)...)
 	if err != nil {
-		fmt.Printf("Error executing query '%s': %v\n", query, err)
-		return nil, fmt.Errorf("failed to query table %s: %w", tableName, err)
+		return nil, fmt.Errorf("failed to query table %s: %w", table.Name, err)
+	}
+	if rows == nil {
+		rows = []map[string]interface{}{}
 	}
 
-	fmt.Printf("Retrieved %d rows from table %s\n", len(data), tableName)
-	return data, nil
+	total, _ := counts[0]["total"].(int64)
+	return &domain.TablePage{
+		Rows:   rows,
+		Total:  int(total),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
 }
 
-// CreateTable creates a new table with specified columns
-func (s *adminService) CreateTable(tableName string, columns []domain.ColumnInfo) error {
-	var columnDefs []string
-	for _, col := range columns {
-		colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
+// LookupForeignKey lists the values a foreign key column accepts, labelled by
+// the name column of the parent table or its first other text column. The
+// search matches the labels containing it.
+func (s *adminService) LookupForeignKey(tableName, column, search string, limit int) (*domain.ForeignKeyLookup, error) {
+	table, err := s.getTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	col := findColumn(table, column)
+	if col == nil {
+		return nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, table.Name, column)
+	}
 
-		if col.PrimaryKey {
-			colDef += " PRIMARY KEY"
-			if strings.ToUpper(col.Type) == "INTEGER" {
-				colDef += " AUTOINCREMENT"
+	if limit == 0 {
+		limit = defaultLookupLimit
+	}
+	if limit < 0 || limit > maxLookupLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxLookupLimit)
+	}
+
+	var key *domain.ForeignKeyInfo
+	for i := range table.ForeignKeys {
+		for _, from := range table.ForeignKeys[i].Columns {
+			if strings.EqualFold(from, col.Name) {
+				key = &table.ForeignKeys[i]
 			}
 		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: column %s of %s is not a foreign key", domain.ErrInvalidInput, col.Name, table.Name)
+	}
+	if len(key.Columns) != 1 {
+		return nil, fmt.Errorf("%w: column %s of %s is part of a foreign key on several columns", domain.ErrInvalidInput, col.Name, table.Name)
+	}
+
+	parent, err := s.getTable(key.Table)
+	if err != nil {
+		return nil, err
+	}
+	references := findColumn(parent, key.References[0])
+	if references == nil {
+		return nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, parent.Name, key.References[0])
+	}
 
-		if col.NotNull && !col.PrimaryKey {
-			colDef += " NOT NULL"
+	label := references
+	if name := findColumn(parent, "name"); name != nil {
+		label = name
+	} else {
+		for i := range parent.Columns {
+			if parent.Columns[i].Name != references.Name && columnAffinity(parent.Columns[i].Type) == "TEXT" {
+				label = &parent.Columns[i]
+				break
+			}
 		}
+	}
 
-		if col.DefaultValue != "" {
-			colDef += fmt.Sprintf(" DEFAULT %s", col.DefaultValue)
+	var filters []domain.ColumnFilter
+	if search != "" {
+		filters = append(filters, domain.ColumnFilter{Column: label.Name, Operator: domain.FilterContains, Value: search})
+	}
+	where, args, err := filterClause(parent, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more row than the limit tells whether the options are truncated
+	query := fmt.Sprintf("SELECT %s AS value, %s AS label FROM %s%s ORDER BY label, value LIMIT ?",
+		quoteIdentifier(references.Name), quoteIdentifier(label.Name), quoteIdentifier(parent.Name), where)
+	rows, err := s.repo.ExecuteQuery(query, append(args, limit+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table %s: %w", parent.Name, err)
+	}
+
+	lookup := &domain.ForeignKeyLookup{
+		Table:       table.Name,
+		Column:      col.Name,
+		Parent:      parent.Name,
+		References:  references.Name,
+		LabelColumn: label.Name,
+		Options:     []domain.LookupOption{},
+		Truncated:   len(rows) > limit,
+	}
+	for i, row := range rows {
+		if i == limit {
+			break
 		}
+		option := domain.LookupOption{Value: row["value"]}
+		if row["label"] != nil {
+			option.Label = fmt.Sprint(row["label"])
+		}
+		lookup.Options = append(lookup.Options, option)
+	}
 
+	return lookup, nil
+}
+
+// CreateTable creates a new table with specified columns
+func (s *adminService) CreateTable(ctx context.Context, tableName string, columns []domain.ColumnInfo) error {
+	if err := checkIdentifier("table", tableName); err != nil {
+		return err
+	}
+	switch _, err := s.getTable(tableName); {
+	case err == nil:
+		return fmt.Errorf("%w: table %s already exists", domain.ErrConflict, tableName)
+	case !errors.Is(err, domain.ErrNotFound):
+		return err
+	}
+
+	var columnDefs []string
+	for _, col := range columns {
+		colDef, err := columnDefinition(col)
+		if err != nil {
+			return err
+		}
 		columnDefs = append(columnDefs, colDef)
 	}
 
-	query := fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(columnDefs, ", "))
-	return s.repo.CreateTable(query)
+	query := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(tableName), strings.Join(columnDefs, ", "))
+	return s.writeAudited(ctx, func(tx ports.AdminStatements) (*domain.AuditEntry, error) {
+		if err := tx.ExecuteNonQuery(query); err != nil {
+			return nil, err
+		}
+		return &domain.AuditEntry{Action: domain.AuditCreateTable, TableName: tableName, Query: query}, nil
+	})
 }
 
 // InsertData inserts new data into a table
-func (s *adminService) InsertData(tableName string, data map[string]interface{}) error {
-	fmt.Printf("Attempting to insert data into table %s: %+v\n", tableName, data)
-
+func (s *adminService) InsertData(ctx context.Context, tableName string, data map[string]interface{}) error {
 	// Get table schema to identify auto-increment columns
-	tableInfo, err := s.repo.GetTableInfo(tableName)
+	tableInfo, err := s.getTable(tableName)
 	if err != nil {
-		return fmt.Errorf("failed to get table info for %s: %w", tableName, err)
+		return err
 	}
 
+	return s.writeAudited(ctx, func(tx ports.AdminStatements) (*domain.AuditEntry, error) {
+		return insertRow(tx, tableInfo, data)
+	})
+}
+
+// insertRow inserts a row and returns the audit entry recording it
+func insertRow(exec ports.AdminStatements, tableInfo *domain.TableInfo, data map[string]interface{}) (*domain.AuditEntry, error) {
+	tableName := tableInfo.Name
+
 	var columns []string
 	var placeholders []string
 	var values []interface{}
+	inserted := make(map[string]interface{})
 
 	// Filter out primary key columns that are auto-increment (INTEGER PRIMARY KEY)
 	for column, value := range data {
-		shouldInclude := true
+		col := findColumn(tableInfo, column)
+		if col == nil {
+			return nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, tableName, column)
+		}
 
-		// Check if this column is an auto-increment primary key
-		for _, col := range tableInfo.Columns {
-			if col.Name == column && col.PrimaryKey && strings.ToUpper(col.Type) == "INTEGER" {
-				// Skip auto-increment primary keys, unless explicitly provided and not empty
-				if value == nil || value == "" || value == "0" {
-					shouldInclude = false
-					fmt.Printf("Skipping auto-increment column %s\n", column)
-				}
-				break
+		// Skip auto-increment primary keys, unless explicitly provided and not empty
+		shouldInclude := true
+		if col.PrimaryKey && strings.ToUpper(col.Type) == "INTEGER" {
+			if value == nil || value == "" || value == "0" {
+				shouldInclude = false
 			}
 		}
 
@@ -138,62 +321,374 @@ func (s *adminService) InsertData(tableName string, data map[string]interface{})
 			if value == "" {
 				value = nil
 			}
-			columns = append(columns, column)
+			columns = append(columns, quoteIdentifier(col.Name))
 			placeholders = append(placeholders, "?")
 			values = append(values, value)
+			inserted[strings.ToLower(col.Name)] = value
 		}
 	}
 
 	if len(columns) == 0 {
-		return fmt.Errorf("no valid columns to insert")
+		return nil, fmt.Errorf("%w: no valid columns to insert", domain.ErrInvalidInput)
 	}
 
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		tableName,
+		quoteIdentifier(tableName),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "))
 
-	fmt.Printf("Executing INSERT query: %s with values: %+v\n", query, values)
+	rowID, err := exec.ExecuteInsert(query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert data into %s: %w", tableName, err)
+	}
 
-	// Use a dedicated method for INSERT operations
-	err = s.repo.ExecuteNonQuery(query, values...)
+	// The row is read back by its key, or by its rowid when SQLite chose the key
+	where, args := " WHERE rowid = ?", []interface{}{rowID}
+	key := make([]interface{}, len(tableInfo.RowKey))
+	for i, column := range tableInfo.RowKey {
+		key[i] = inserted[strings.ToLower(column)]
+		if key[i] == nil {
+			key = nil
+			break
+		}
+	}
+	if key != nil {
+		if where, args, err = keyClause(tableInfo, key); err != nil {
+			return nil, err
+		}
+	}
+	after, err := getRow(exec, tableInfo, where, args)
 	if err != nil {
-		fmt.Printf("Insert failed with error: %v\n", err)
-		return fmt.Errorf("failed to insert data into %s: %w", tableName, err)
+		return nil, err
 	}
 
-	fmt.Printf("Insert successful\n")
-	return nil
+	return &domain.AuditEntry{
+		Action:    domain.AuditInsert,
+		TableName: tableName,
+		RowID:     rowKeyID(tableInfo, after),
+		After:     snapshot(after),
+	}, nil
+}
+
+// UpdateData updates the row of a table with the given key. The key holds the
+// values of the primary key columns in key order, or the rowid of tables
+// without a primary key.
+func (s *adminService) UpdateData(ctx context.Context, tableName string, key []string, data map[string]interface{}) error {
+	tableInfo, err := s.getTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	return s.writeAudited(ctx, func(tx ports.AdminStatements) (*domain.AuditEntry, error) {
+		return updateRow(tx, tableInfo, stringKey(key), data)
+	})
 }
 
-// UpdateData updates existing data in a table
-func (s *adminService) UpdateData(tableName string, id int, data map[string]interface{}) error {
+// updateRow updates the row with the given key and returns the audit entry
+// recording it
+func updateRow(exec ports.AdminStatements, tableInfo *domain.TableInfo, key []interface{}, data map[string]interface{}) (*domain.AuditEntry, error) {
+	tableName := tableInfo.Name
+
+	where, keyArgs, err := keyClause(tableInfo, key)
+	if err != nil {
+		return nil, err
+	}
+
 	var setParts []string
 	var values []interface{}
 
 	for column, value := range data {
-		if column != "id" { // Don't update the ID column
-			setParts = append(setParts, fmt.Sprintf("%s = ?", column))
+		col := findColumn(tableInfo, column)
+		if col == nil {
+			return nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, tableName, column)
+		}
+		if !isKeyColumn(tableInfo, col.Name) { // Don't update the key columns
+			setParts = append(setParts, fmt.Sprintf("%s = ?", quoteIdentifier(col.Name)))
 			values = append(values, value)
 		}
 	}
 
 	if len(setParts) == 0 {
-		return fmt.Errorf("no columns to update")
+		return nil, fmt.Errorf("%w: no columns to update", domain.ErrInvalidInput)
 	}
 
-	values = append(values, id)
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?",
-		tableName,
-		strings.Join(setParts, ", "))
+	values = append(values, keyArgs...)
+	query := fmt.Sprintf("UPDATE %s SET %s%s",
+		quoteIdentifier(tableName),
+		strings.Join(setParts, ", "),
+		where)
+
+	before, err := getRow(exec, tableInfo, where, keyArgs)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, fmt.Errorf("%w: table %s has no row %s", domain.ErrNotFound, tableName, formatKey(key))
+	}
 
 	// Use a dedicated method for UPDATE operations
-	return s.repo.ExecuteNonQuery(query, values...)
+	if err := exec.ExecuteNonQuery(query, values...); err != nil {
+		return nil, err
+	}
+
+	after, err := getRow(exec, tableInfo, where, keyArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuditEntry{
+		Action:    domain.AuditUpdate,
+		TableName: tableName,
+		RowID:     rowKeyID(tableInfo, before),
+		Before:    snapshot(before),
+		After:     snapshot(after),
+	}, nil
 }
 
-// DeleteData deletes data from a table by ID
-func (s *adminService) DeleteData(tableName string, id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName)
+// DeleteData deletes the row of a table with the given key, as UpdateData
+// addresses it
+func (s *adminService) DeleteData(ctx context.Context, tableName string, key []string) error {
+	tableInfo, err := s.getTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	return s.writeAudited(ctx, func(tx ports.AdminStatements) (*domain.AuditEntry, error) {
+		return deleteRow(tx, tableInfo, stringKey(key))
+	})
+}
+
+// deleteRow deletes the row with the given key and returns the audit entry
+// recording it
+func deleteRow(exec ports.AdminStatements, tableInfo *domain.TableInfo, key []interface{}) (*domain.AuditEntry, error) {
+	tableName := tableInfo.Name
+
+	where, keyArgs, err := keyClause(tableInfo, key)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := getRow(exec, tableInfo, where, keyArgs)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, fmt.Errorf("%w: table %s has no row %s", domain.ErrNotFound, tableName, formatKey(key))
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s%s", quoteIdentifier(tableName), where)
 	// Use a dedicated method for DELETE operations
-	return s.repo.ExecuteNonQuery(query, id)
+	if err := exec.ExecuteNonQuery(query, keyArgs...); err != nil {
+		return nil, err
+	}
+
+	return &domain.AuditEntry{
+		Action:    domain.AuditDelete,
+		TableName: tableName,
+		RowID:     rowKeyID(tableInfo, before),
+		Before:    snapshot(before),
+	}, nil
+}
+
+// GetAuditLog retrieves the audit entries matching a filter, newest first
+func (s *adminService) GetAuditLog(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Action != "" && !validAuditAction(filter.Action) {
+		return nil, fmt.Errorf("%w: unknown action %s", domain.ErrInvalidInput, filter.Action)
+	}
+	if filter.Limit < 0 || filter.Limit > maxAuditLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, maxAuditLimit)
+	}
+
+	var err error
+	if filter.Since, err = auditTime(filter.Since, false); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = auditTime(filter.Until, true); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.audit.GetAuditEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+	return entries, nil
+}
+
+// getRow reads the row of a table matching a WHERE clause, or nil if there is
+// no such row
+func getRow(exec ports.AdminStatements, table *domain.TableInfo, where string, args []interface{}) (map[string]interface{}, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s%s", selectColumns(table), quoteIdentifier(table.Name), where)
+	rows, err := exec.ExecuteQuery(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row %v of %s: %w", args, table.Name, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// rowKeyID formats the key of a row for the audit log, its values separated
+// by slashes as in the row URLs
+func rowKeyID(table *domain.TableInfo, row map[string]interface{}) string {
+	values := make([]string, len(table.RowKey))
+	for i, column := range table.RowKey {
+		values[i] = fmt.Sprint(row[column])
+	}
+	return strings.Join(values, "/")
+}
+
+// formatKey formats the key of a row URL, its values separated by slashes
+func formatKey(key []interface{}) string {
+	values := make([]string, len(key))
+	for i, value := range key {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, "/")
+}
+
+// stringKey converts the key values of a row URL to query arguments
+func stringKey(key []string) []interface{} {
+	values := make([]interface{}, len(key))
+	for i, value := range key {
+		values[i] = value
+	}
+	return values
+}
+
+// snapshot encodes a row for the audit log. Rows read from SQLite only hold
+// strings, numbers and times, which always encode.
+func snapshot(row map[string]interface{}) json.RawMessage {
+	if row == nil {
+		return nil
+	}
+	data, _ := json.Marshal(row)
+	return data
+}
+
+// record saves an audit entry under the user of the context
+func (s *adminService) record(ctx context.Context, entry *domain.AuditEntry) error {
+	return recordAudit(ctx, s.audit, entry)
+}
+
+// writeAudited runs a write and records the audit entry it returns in one
+// transaction, so that a write is never applied without its entry
+func (s *adminService) writeAudited(ctx context.Context, write func(tx ports.AdminStatements) (*domain.AuditEntry, error)) error {
+	return s.repo.InTransaction(func(tx ports.AdminTransaction) error {
+		entry, err := write(tx)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entry)
+	})
+}
+
+// recordAudit saves an audit entry under the user of the context
+func recordAudit(ctx context.Context, audit ports.AuditWriter, entry *domain.AuditEntry) error {
+	if user := domain.UserFromContext(ctx); user != nil {
+		entry.UserID = &user.ID
+		entry.Username = user.Username
+	}
+
+	if err := audit.CreateAuditEntry(entry); err != nil {
+		return fmt.Errorf("failed to record %s in audit log: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// auditTime converts a date or a timestamp to the format of the created_at
+// column. A date used as upper bound includes the whole day.
+func auditTime(value string, upper bool) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		if upper {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day.Format(time.DateTime), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.DateTime), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s is not a date or a timestamp", domain.ErrInvalidInput, value)
+}
+
+func validAuditAction(action domain.AuditAction) bool {
+	switch action {
+	case domain.AuditInsert, domain.AuditUpdate, domain.AuditDelete, domain.AuditCreateTable, domain.AuditAlterSchema,
+		domain.AuditQuery, domain.AuditImport, domain.AuditBackup, domain.AuditRestore:
+		return true
+	}
+	return false
+}
+
+// getTable looks a table up in the live schema, ignoring case like SQLite
+// does, and returns it with its columns. Protected tables are refused.
+func (s *adminService) getTable(tableName string) (*domain.TableInfo, error) {
+	tables, err := s.repo.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables list: %w", err)
+	}
+
+	for _, table := range tables {
+		if !strings.EqualFold(table, tableName) {
+			continue
+		}
+		if page, ok := protectedTables[strings.ToLower(table)]; ok {
+			return nil, fmt.Errorf("%w: table %s is managed from %s", domain.ErrForbidden, table, page)
+		}
+
+		tableInfo, err := s.repo.GetTableInfo(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get table info for %s: %w", table, err)
+		}
+		return tableInfo, nil
+	}
+
+	return nil, fmt.Errorf("%w: table %s does not exist", domain.ErrNotFound, tableName)
+}
+
+// referencingTables lists the other tables having a foreign key to a table,
+// whose rows deleting the rows of the table cascades to or is refused by
+func (s *adminService) referencingTables(tableName string) ([]string, error) {
+	tables, err := s.repo.GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tables list: %w", err)
+	}
+
+	var children []string
+	for _, table := range tables {
+		if strings.EqualFold(table, tableName) {
+			continue
+		}
+		tableInfo, err := s.repo.GetTableInfo(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get table info for %s: %w", table, err)
+		}
+		for _, key := range tableInfo.ForeignKeys {
+			if strings.EqualFold(key.Table, tableName) {
+				children = append(children, table)
+				break
+			}
+		}
+	}
+	return children, nil
+}
+
+// findColumn returns the column of a table with the given name, ignoring case
+func findColumn(tableInfo *domain.TableInfo, name string) *domain.ColumnInfo {
+	for i := range tableInfo.Columns {
+		if strings.EqualFold(tableInfo.Columns[i].Name, name) {
+			return &tableInfo.Columns[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// identifierPattern limits the names of new tables and columns
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// columnTypes are the column types offered when creating a table
+var columnTypes = map[string]bool{
+	"INTEGER":  true,
+	"TEXT":     true,
+	"REAL":     true,
+	"BLOB":     true,
+	"NUMERIC":  true,
+	"BOOLEAN":  true,
+	"DATETIME": true,
+}
+
+// columnAffinity returns the type affinity SQLite gives a declared column
+// type: INTEGER, TEXT, BLOB, REAL or NUMERIC
+func columnAffinity(columnType string) string {
+	columnType = strings.ToUpper(columnType)
+	switch {
+	case strings.Contains(columnType, "INT"):
+		return "INTEGER"
+	case strings.Contains(columnType, "CHAR"), strings.Contains(columnType, "CLOB"), strings.Contains(columnType, "TEXT"):
+		return "TEXT"
+	case columnType == "", strings.Contains(columnType, "BLOB"):
+		return "BLOB"
+	case strings.Contains(columnType, "REAL"), strings.Contains(columnType, "FLOA"), strings.Contains(columnType, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// readOnlyKeywords start the statements that cannot change the database
+var readOnlyKeywords = map[string]bool{
+	"SELECT":  true,
+	"WITH":    true,
+	"VALUES":  true,
+	"EXPLAIN": true,
+}
+
+// defaultKeywords are the default values used as is rather than as text
+var defaultKeywords = map[string]bool{
+	"NULL":              true,
+	"TRUE":              true,
+	"FALSE":             true,
+	"CURRENT_TIME":      true,
+	"CURRENT_DATE":      true,
+	"CURRENT_TIMESTAMP": true,
+}
+
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// quoteIdentifier quotes a table or column name for use in SQL. The services
+// and the database adapter each have a copy, as the core imports no adapter;
+// keep it in step with the one in internal/adapters/database/sqlite.go.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// checkIdentifier validates the name of a new table or column
+func checkIdentifier(kind, name string) error {
+	if !identifierPattern.MatchString(name) {
+		return fmt.Errorf("%w: %s name %q must start with a letter and hold only letters, digits and underscores", domain.ErrInvalidInput, kind, name)
+	}
+	if strings.HasPrefix(strings.ToLower(name), "sqlite_") {
+		return fmt.Errorf("%w: %s name %q is reserved by SQLite", domain.ErrInvalidInput, kind, name)
+	}
+	return nil
+}
+
+// columnDefinition builds the SQL defining a new column
+func columnDefinition(col domain.ColumnInfo) (string, error) {
+	if err := checkIdentifier("column", col.Name); err != nil {
+		return "", err
+	}
+	columnType := strings.ToUpper(col.Type)
+	if !columnTypes[columnType] {
+		return "", fmt.Errorf("%w: unsupported column type %q", domain.ErrInvalidInput, col.Type)
+	}
+
+	colDef := fmt.Sprintf("%s %s", quoteIdentifier(col.Name), columnType)
+
+	if col.PrimaryKey {
+		colDef += " PRIMARY KEY"
+		if columnType == "INTEGER" {
+			colDef += " AUTOINCREMENT"
+		}
+	}
+
+	if col.NotNull && !col.PrimaryKey {
+		colDef += " NOT NULL"
+	}
+
+	if col.DefaultValue != "" {
+		colDef += fmt.Sprintf(" DEFAULT %s", defaultLiteral(col.DefaultValue))
+	}
+
+	return colDef, nil
+}
+
+// defaultLiteral turns a column default into SQL: numbers and keywords such as
+// CURRENT_TIMESTAMP are kept, anything else becomes a text literal
+func defaultLiteral(value string) string {
+	if numberPattern.MatchString(value) || defaultKeywords[strings.ToUpper(value)] {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// filterOperators maps the filter operators to SQL comparisons
+var filterOperators = map[string]string{
+	domain.FilterEqual:        "= ?",
+	domain.FilterNotEqual:     "!= ?",
+	domain.FilterLess:         "< ?",
+	domain.FilterLessEqual:    "<= ?",
+	domain.FilterGreater:      "> ?",
+	domain.FilterGreaterEqual: ">= ?",
+	domain.FilterContains:     `LIKE ? ESCAPE '\'`,
+	domain.FilterLike:         "LIKE ?",
+	domain.FilterNull:         "IS NULL",
+	domain.FilterNotNull:      "IS NOT NULL",
+}
+
+// filterClause builds the WHERE clause of table filters, with its arguments
+func filterClause(table *domain.TableInfo, filters []domain.ColumnFilter) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	for _, filter := range filters {
+		column := findColumn(table, filter.Column)
+		if column == nil {
+			return "", nil, fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, table.Name, filter.Column)
+		}
+		comparison, ok := filterOperators[filter.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown filter operator %s", domain.ErrInvalidInput, filter.Operator)
+		}
+
+		conditions = append(conditions, quoteIdentifier(column.Name)+" "+comparison)
+		switch filter.Operator {
+		case domain.FilterNull, domain.FilterNotNull:
+		case domain.FilterContains:
+			escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Value)
+			args = append(args, "%"+escaped+"%")
+		default:
+			args = append(args, filter.Value)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// keyClause builds the WHERE clause addressing a row by the values of its key
+// columns. SQLite converts the values to the affinity of the columns, so the
+// text of a URL matches integer keys.
+func keyClause(table *domain.TableInfo, key []interface{}) (string, []interface{}, error) {
+	if len(key) != len(table.RowKey) {
+		return "", nil, fmt.Errorf("%w: rows of %s are addressed by %s", domain.ErrInvalidInput, table.Name, strings.Join(table.RowKey, ", "))
+	}
+
+	conditions := make([]string, len(key))
+	for i, column := range table.RowKey {
+		conditions[i] = quoteIdentifier(column) + " = ?"
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), key, nil
+}
+
+// isKeyColumn tells whether a column is part of the key addressing the rows
+func isKeyColumn(table *domain.TableInfo, column string) bool {
+	for _, key := range table.RowKey {
+		if strings.EqualFold(key, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectColumns lists the columns read from a table: all of them, with the
+// rowid first for tables without a primary key so their rows can be addressed
+func selectColumns(table *domain.TableInfo) string {
+	if len(table.RowKey) == 1 && findColumn(table, table.RowKey[0]) == nil {
+		return quoteIdentifier(table.RowKey[0]) + " AS " + quoteIdentifier(table.RowKey[0]) + ", *"
+	}
+	return "*"
+}
+
+// orderClause builds the ORDER BY clause of a table page. Rows are then
+// ordered by primary key, or rowid, so pages do not overlap.
+func orderClause(table *domain.TableInfo, sort string, desc bool) (string, error) {
+	var terms []string
+	if sort != "" {
+		column := findColumn(table, sort)
+		if column == nil {
+			return "", fmt.Errorf("%w: table %s has no column %s", domain.ErrInvalidInput, table.Name, sort)
+		}
+		term := quoteIdentifier(column.Name)
+		if desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+
+	for _, column := range table.RowKey {
+		terms = append(terms, quoteIdentifier(column))
+	}
+
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// sqlSummary describes a query for the query policy
+type sqlSummary struct {
+	readOnly   bool
+	statements []sqlStatement
+	// names holds every identifier of the query, lowercased, and the strings
+	// SQLite would read as table names
+	names map[string]bool
+}
+
+// sqlStatement is one statement of a query
+type sqlStatement struct {
+	sql string
+	// keyword is the first word of the statement, uppercased
+	keyword string
+}
+
+// summarizeSQL splits a query into statements to tell whether each statement
+// starts with a read-only keyword and which names the query uses
+func summarizeSQL(query string) (*sqlSummary, error) {
+	tokens, err := tokenizeSQL(query)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &sqlSummary{readOnly: true, names: make(map[string]bool)}
+	start := -1
+
+	for i, token := range tokens {
+		if token.isName() {
+			summary.names[strings.ToLower(token.name)] = true
+		}
+		// SQLite accepts a string as a table name, as in FROM 'users'
+		if token.kind == tokenString && i > 0 &&
+			(tokens[i-1].is("FROM") || tokens[i-1].is("JOIN") || tokens[i-1].is(".")) {
+			summary.names[strings.ToLower(token.name)] = true
+		}
+
+		if token.is(";") {
+			if start >= 0 {
+				summary.addStatement(query, tokens[start:i])
+			}
+			start = -1
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		summary.addStatement(query, tokens[start:])
+	}
+
+	if len(summary.statements) == 0 {
+		return nil, fmt.Errorf("%w: query is empty", domain.ErrInvalidInput)
+	}
+	return summary, nil
+}
+
+// addStatement appends the statement made of the given tokens
+func (summary *sqlSummary) addStatement(query string, tokens []sqlToken) {
+	first := tokens[0]
+	statement := sqlStatement{sql: query[first.start:tokens[len(tokens)-1].end]}
+	if first.kind == tokenWord {
+		statement.keyword = strings.ToUpper(first.name)
+	}
+	if !readOnlyKeywords[statement.keyword] {
+		summary.readOnly = false
+	}
+	summary.statements = append(summary.statements, statement)
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	// tokenQuoted is an identifier quoted with "", `` or []
+	tokenQuoted
+	tokenString
+	tokenPunct
+)
+
+// sqlToken is a token of a SQL text, start and end being byte offsets
+type sqlToken struct {
+	kind       tokenKind
+	name       string // unquoted text of words, quoted identifiers and strings
+	start, end int
+}
+
+func (t sqlToken) isName() bool {
+	return t.kind == tokenWord || t.kind == tokenQuoted
+}
+
+// is tells whether the token is the given punctuation or keyword
+func (t sqlToken) is(text string) bool {
+	return (t.kind == tokenPunct || t.kind == tokenWord) && strings.EqualFold(t.name, text)
+}
+
+// tokenizeSQL splits SQL into words, quoted identifiers, strings and
+// punctuation, skipping whitespace and comments
+func tokenizeSQL(text string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(text)
+	// offsets maps rune indexes to byte offsets
+	offsets := make([]int, len(runes)+1)
+	for i, offset := 0, 0; i < len(runes); i++ {
+		offsets[i] = offset
+		offset += utf8.RuneLen(runes[i])
+		offsets[i+1] = offset
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i++
+			}
+			i = min(i+2, len(runes))
+		case r == '\'' || r == '"' || r == '`' || r == '[':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			name, next, ok := quoted(runes, i, closing)
+			if !ok {
+				return nil, fmt.Errorf("%w: unterminated %c in query", domain.ErrInvalidInput, r)
+			}
+			kind := tokenQuoted
+			if r == '\'' {
+				kind = tokenString
+			}
+			i = next
+			tokens = append(tokens, sqlToken{kind: kind, name: name, start: offsets[start], end: offsets[i]})
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, name: string(runes[start:i]), start: offsets[start], end: offsets[i]})
+		default:
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenPunct, name: string(r), start: offsets[start], end: offsets[i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// quoted reads a quoted string or identifier starting at runes[start], where
+// a doubled closing quote stands for the quote itself
+func quoted(runes []rune, start int, closing rune) (string, int, bool) {
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] != closing {
+			b.WriteRune(runes[i])
+			continue
+		}
+		if closing != ']' && i+1 < len(runes) && runes[i+1] == closing {
+			b.WriteRune(closing)
+			i++
+			continue
+		}
+		return b.String(), i + 1, true
+	}
+	return "", len(runes), false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
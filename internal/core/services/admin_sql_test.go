@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/core/domain"
+)
+
+func TestSummarizeSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		readOnly   bool
+		statements []string
+		names      []string
+	}{
+		{"select", "SELECT name FROM resources", true, []string{"SELECT name FROM resources"}, []string{"select", "name", "from", "resources"}},
+		{"trailing semicolon", "VALUES (1);", true, []string{"VALUES (1)"}, nil},
+		{"lowercase keyword", "with x AS (SELECT 1) SELECT * FROM x", true, []string{"with x AS (SELECT 1) SELECT * FROM x"}, []string{"x"}},
+		{"explain", "EXPLAIN QUERY PLAN SELECT 1", true, []string{"EXPLAIN QUERY PLAN SELECT 1"}, nil},
+		{"two statements", "SELECT 1; SELECT 2", true, []string{"SELECT 1", "SELECT 2"}, nil},
+		{"write after read", "SELECT 1; DELETE FROM resources", false, []string{"SELECT 1", "DELETE FROM resources"}, []string{"resources"}},
+		{"insert", "INSERT INTO resources (name) VALUES ('x')", false, []string{"INSERT INTO resources (name) VALUES ('x')"}, nil},
+		{"pragma", "PRAGMA foreign_keys = OFF", false, []string{"PRAGMA foreign_keys = OFF"}, nil},
+		{"semicolon in a string", "SELECT ';DROP TABLE users'", true, []string{"SELECT ';DROP TABLE users'"}, nil},
+		{"semicolon in a comment", "SELECT 1 -- ; DELETE FROM users\n", true, []string{"SELECT 1"}, nil},
+		{"quoted identifiers", "SELECT \"a\"\"b\", `c`, [d] FROM t", true, []string{"SELECT \"a\"\"b\", `c`, [d] FROM t"}, []string{`a"b`, "c", "d"}},
+		{"string as table", "SELECT * FROM 'Users'", true, []string{"SELECT * FROM 'Users'"}, []string{"users"}},
+		{"string after schema", "SELECT * FROM main.'users' JOIN 'sessions' ON 1", true, []string{"SELECT * FROM main.'users' JOIN 'sessions' ON 1"}, []string{"main", "users", "sessions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := summarizeSQL(tt.query)
+			if err != nil {
+				t.Fatalf("summarizeSQL() error = %v", err)
+			}
+			if summary.readOnly != tt.readOnly {
+				t.Errorf("readOnly = %v, want %v", summary.readOnly, tt.readOnly)
+			}
+			if len(summary.statements) != len(tt.statements) {
+				t.Fatalf("statements = %v, want %v", summary.statements, tt.statements)
+			}
+			for i, statement := range summary.statements {
+				if statement.sql != tt.statements[i] {
+					t.Errorf("statement %d = %q, want %q", i, statement.sql, tt.statements[i])
+				}
+			}
+			for _, name := range tt.names {
+				if !summary.names[name] {
+					t.Errorf("names = %v, want %q in them", summary.names, name)
+				}
+			}
+		})
+	}
+
+	t.Run("string value", func(t *testing.T) {
+		summary, err := summarizeSQL("SELECT * FROM resources WHERE name = 'users'")
+		if err != nil {
+			t.Fatalf("summarizeSQL() error = %v", err)
+		}
+		if summary.names["users"] {
+			t.Error("a string compared to a column was taken for a table name")
+		}
+	})
+
+	for _, query := range []string{"", "  ", "-- comment only", ";;"} {
+		if _, err := summarizeSQL(query); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("summarizeSQL(%q) error = %v, want domain.ErrInvalidInput", query, err)
+		}
+	}
+	if _, err := summarizeSQL("SELECT 'unterminated"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("summarizeSQL() of an unterminated string error = %v, want domain.ErrInvalidInput", err)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"resources":    `"resources"`,
+		"with space":   `"with space"`,
+		`say "hi"`:     `"say ""hi"""`,
+		`"; DROP --`:   `"""; DROP --"`,
+		"":             `""`,
+		"emoji_🐑_name": `"emoji_🐑_name"`,
+	}
+	for name, want := range tests {
+		if got := quoteIdentifier(name); got != want {
+			t.Errorf("quoteIdentifier(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// newTestAdminService opens a migrated database in a temporary directory
+func newTestAdminService(t *testing.T, policy domain.QueryPolicy) *adminService {
+	t.Helper()
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewAdminService(db, db, policy).(*adminService)
+}
+
+func userContext(role domain.Role) context.Context {
+	return domain.WithUser(context.Background(), &domain.User{ID: 1, Username: string(role), Role: role})
+}
+
+func TestAuthorizeQuery(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+
+	// A view over a protected table hides its name from the tokenizer
+	if _, err := service.ExecuteQuery(userContext(domain.RoleAdmin), "CREATE VIEW people AS SELECT username, password_hash FROM users"); err != nil {
+		t.Fatalf("creating view: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		// allowed lists the roles which may run the query, privileged ones
+		// being allowed anything
+		viewer, editor, admin bool
+	}{
+		{"select", "SELECT * FROM resources", true, true, true},
+		{"join", "SELECT r.name FROM resources r JOIN recipe_resources rr ON rr.resource_id = r.id", true, true, true},
+		{"string value", "SELECT * FROM resources WHERE name = 'users'", true, true, true},
+		{"explain", "EXPLAIN QUERY PLAN SELECT * FROM resources", true, true, true},
+		{"update", "UPDATE resources SET name = name", false, false, true},
+		{"write after read", "SELECT 1; DELETE FROM resources WHERE 0", false, false, true},
+		{"pragma", "PRAGMA table_info(resources)", false, false, true},
+		{"users", "SELECT * FROM users", false, false, true},
+		{"uppercase", "SELECT * FROM USERS", false, false, true},
+		{"double quoted", `SELECT * FROM "users"`, false, false, true},
+		{"single quoted", "SELECT username, password_hash FROM 'users'", false, false, true},
+		{"bracketed", "SELECT * FROM [sessions]", false, false, true},
+		{"backtick", "SELECT * FROM `audit_log`", false, false, true},
+		{"schema and string", "SELECT * FROM main.'users'", false, false, true},
+		{"schema and quotes", `SELECT * FROM "main"."sessions"`, false, false, true},
+		{"subquery", "SELECT (SELECT count(*) FROM 'audit_log')", false, false, true},
+		{"view", "SELECT * FROM people", false, false, true},
+		{"second statement", "SELECT 1; SELECT * FROM 'users'", false, false, true},
+	}
+	roles := []domain.Role{domain.RoleViewer, domain.RoleEditor, domain.RoleAdmin}
+
+	for _, tt := range tests {
+		for i, allowed := range []bool{tt.viewer, tt.editor, tt.admin} {
+			role := roles[i]
+			t.Run(tt.name+" as "+string(role), func(t *testing.T) {
+				summary, err := summarizeSQL(tt.query)
+				if err != nil {
+					t.Fatalf("summarizeSQL() error = %v", err)
+				}
+
+				privileged, err := service.authorizeQuery(userContext(role), summary)
+				if allowed && err != nil {
+					t.Fatalf("authorizeQuery() error = %v, want the query allowed", err)
+				}
+				if !allowed && !errors.Is(err, domain.ErrForbidden) {
+					t.Fatalf("authorizeQuery() error = %v, want domain.ErrForbidden", err)
+				}
+				if err == nil && privileged != (role == domain.RoleAdmin) {
+					t.Errorf("authorizeQuery() privileged = %v for %s", privileged, role)
+				}
+			})
+		}
+	}
+
+	t.Run("no user", func(t *testing.T) {
+		summary, _ := summarizeSQL("SELECT 1")
+		if _, err := service.authorizeQuery(context.Background(), summary); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("authorizeQuery() error = %v, want domain.ErrForbidden", err)
+		}
+	})
+}
+
+func TestAuthorizeQueryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  domain.QueryPolicy
+		role    domain.Role
+		query   string
+		wantErr bool
+	}{
+		{"below read role", domain.QueryPolicy{ReadRole: domain.RoleEditor, WriteRole: domain.RoleAdmin}, domain.RoleViewer, "SELECT 1", true},
+		{"read role", domain.QueryPolicy{ReadRole: domain.RoleEditor, WriteRole: domain.RoleAdmin}, domain.RoleEditor, "SELECT 1", false},
+		{"write role lowered", domain.QueryPolicy{ReadRole: domain.RoleViewer, WriteRole: domain.RoleEditor}, domain.RoleEditor, "DELETE FROM resources WHERE 0", false},
+		{"no write role", domain.QueryPolicy{ReadRole: domain.RoleViewer}, domain.RoleAdmin, "DELETE FROM resources WHERE 0", true},
+		{"no write role protects users", domain.QueryPolicy{ReadRole: domain.RoleViewer}, domain.RoleAdmin, "SELECT * FROM 'users'", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestAdminService(t, tt.policy)
+			summary, err := summarizeSQL(tt.query)
+			if err != nil {
+				t.Fatalf("summarizeSQL() error = %v", err)
+			}
+			_, err = service.authorizeQuery(userContext(tt.role), summary)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("authorizeQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrForbidden) {
+				t.Errorf("authorizeQuery() error = %v, want domain.ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestExecuteQueryProtectedTables(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+
+	for _, query := range []string{
+		"SELECT username, password_hash FROM 'users'",
+		"SELECT * FROM main.'sessions'",
+		"SELECT * FROM 'audit_log'",
+	} {
+		if _, err := service.ExecuteQuery(userContext(domain.RoleViewer), query); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("ExecuteQuery(%q) as viewer error = %v, want domain.ErrForbidden", query, err)
+		}
+	}
+
+	if _, err := service.ExecuteQuery(userContext(domain.RoleViewer), "SELECT count(*) FROM resources"); err != nil {
+		t.Errorf("ExecuteQuery() as viewer error = %v", err)
+	}
+}
+
+func TestFilterClause(t *testing.T) {
+	table := &domain.TableInfo{
+		Name:    "pals",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INTEGER"}, {Name: "Name", Type: "TEXT"}},
+		RowKey:  []string{"id"},
+	}
+
+	tests := []struct {
+		name      string
+		filters   []domain.ColumnFilter
+		wantWhere string
+		wantArgs  []interface{}
+		wantErr   bool
+	}{
+		{name: "no filter"},
+		{
+			name:      "equal",
+			filters:   []domain.ColumnFilter{{Column: "id", Operator: domain.FilterEqual, Value: "3"}},
+			wantWhere: ` WHERE "id" = ?`,
+			wantArgs:  []interface{}{"3"},
+		},
+		{
+			name: "comparisons",
+			filters: []domain.ColumnFilter{
+				{Column: "id", Operator: domain.FilterNotEqual, Value: "1"},
+				{Column: "id", Operator: domain.FilterLess, Value: "2"},
+				{Column: "id", Operator: domain.FilterLessEqual, Value: "3"},
+				{Column: "id", Operator: domain.FilterGreater, Value: "4"},
+				{Column: "id", Operator: domain.FilterGreaterEqual, Value: "5"},
+			},
+			wantWhere: ` WHERE "id" != ? AND "id" < ? AND "id" <= ? AND "id" > ? AND "id" >= ?`,
+			wantArgs:  []interface{}{"1", "2", "3", "4", "5"},
+		},
+		{
+			name:      "contains escapes wildcards",
+			filters:   []domain.ColumnFilter{{Column: "name", Operator: domain.FilterContains, Value: `50%_a\b`}},
+			wantWhere: ` WHERE "Name" LIKE ? ESCAPE '\'`,
+			wantArgs:  []interface{}{`%50\%\_a\\b%`},
+		},
+		{
+			name:      "like keeps wildcards",
+			filters:   []domain.ColumnFilter{{Column: "name", Operator: domain.FilterLike, Value: "La%_"}},
+			wantWhere: ` WHERE "Name" LIKE ?`,
+			wantArgs:  []interface{}{"La%_"},
+		},
+		{
+			name: "null checks take no value",
+			filters: []domain.ColumnFilter{
+				{Column: "name", Operator: domain.FilterNull, Value: "ignored"},
+				{Column: "id", Operator: domain.FilterNotNull},
+			},
+			wantWhere: ` WHERE "Name" IS NULL AND "id" IS NOT NULL`,
+		},
+		{
+			name:    "unknown column",
+			filters: []domain.ColumnFilter{{Column: "name; DROP TABLE pals", Operator: domain.FilterEqual}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown operator",
+			filters: []domain.ColumnFilter{{Column: "id", Operator: "between"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args, err := filterClause(table, tt.filters)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Errorf("filterClause() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filterClause() error = %v", err)
+			}
+			if where != tt.wantWhere {
+				t.Errorf("where = %s, want %s", where, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	columns := []domain.ColumnInfo{{Name: "pal"}, {Name: "item"}, {Name: "rate"}}
+	byKey := &domain.TableInfo{Name: "drops", Columns: columns, RowKey: []string{"pal", "item"}}
+	byRowID := &domain.TableInfo{Name: "notes", Columns: columns, RowKey: []string{"rowid"}}
+
+	tests := []struct {
+		name    string
+		table   *domain.TableInfo
+		sort    string
+		desc    bool
+		want    string
+		wantErr bool
+	}{
+		{name: "key", table: byKey, want: ` ORDER BY "pal", "item"`},
+		{name: "rowid", table: byRowID, want: ` ORDER BY "rowid"`},
+		{name: "column then key", table: byKey, sort: "RATE", want: ` ORDER BY "rate", "pal", "item"`},
+		{name: "descending", table: byRowID, sort: "rate", desc: true, want: ` ORDER BY "rate" DESC, "rowid"`},
+		{name: "unknown column", table: byKey, sort: "rate DESC; --", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderClause(tt.table, tt.sort, tt.desc)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Errorf("orderClause() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("orderClause() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("orderClause() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
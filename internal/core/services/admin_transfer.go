@@ -0,0 +1,553 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// errRollback rolls back the transaction of an import which is not committed
+var errRollback = errors.New("import rolled back")
+
+// maxImportLine caps the length of a line of an NDJSON import
+const maxImportLine = 1 << 20
+
+// importRecord is a row read from an imported file, or the reason it could
+// not be read
+type importRecord struct {
+	values map[string]interface{}
+	err    error
+}
+
+// ExportTable streams every row of a table in CSV, JSON or NDJSON, with the
+// columns in table order
+func (s *adminService) ExportTable(w io.Writer, tableName string, format domain.ExportFormat) error {
+	if err := checkFormat(format, domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON); err != nil {
+		return err
+	}
+	table, err := s.getTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, len(table.Columns))
+	quotedColumns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = column.Name
+		quotedColumns[i] = quoteIdentifier(column.Name)
+	}
+	orderBy, err := orderClause(table, "", false)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(quotedColumns, ", "), quoteIdentifier(table.Name), orderBy)
+
+	switch format {
+	case domain.FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		err = s.repo.QueryEach(query, nil, func(values []interface{}) error {
+			for i, value := range values {
+				record[i] = ""
+				if value != nil {
+					record[i] = fmt.Sprint(exportValue(value))
+				}
+			}
+			return writer.Write(record)
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+
+	case domain.FormatNDJSON:
+		err = s.repo.QueryEach(query, nil, func(values []interface{}) error {
+			if err := writeJSONRow(w, columns, values); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "\n")
+			return err
+		})
+
+	default:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		separator := "\n"
+		err = s.repo.QueryEach(query, nil, func(values []interface{}) error {
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+			separator = ",\n"
+			return writeJSONRow(w, columns, values)
+		})
+		if err == nil {
+			_, err = io.WriteString(w, "\n]\n")
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to export table %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+// ImportTable writes the rows of a CSV, JSON or NDJSON file to a table in a
+// single transaction. Rows are checked against the table columns, and the
+// transaction is only committed when every row succeeded and it is not a dry run.
+func (s *adminService) ImportTable(ctx context.Context, r io.Reader, tableName string, format domain.ExportFormat, mode domain.ImportMode, dryRun bool) (*domain.TableImportResult, error) {
+	if err := checkFormat(format, domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON); err != nil {
+		return nil, err
+	}
+	table, err := s.getTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			keys = append(keys, quoteIdentifier(column.Name))
+		}
+	}
+	switch mode {
+	case domain.ImportInsert:
+	case domain.ImportReplace:
+		// Without a key the table is emptied first, which would cascade to
+		// the rows of the tables referencing it
+		if len(keys) == 0 {
+			children, err := s.referencingTables(table.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(children) > 0 {
+				return nil, fmt.Errorf("%w: table %s has no primary key and is referenced by %s, it cannot be replaced", domain.ErrInvalidInput, table.Name, strings.Join(children, ", "))
+			}
+		}
+	case domain.ImportUpsert:
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%w: table %s has no primary key to upsert on", domain.ErrInvalidInput, table.Name)
+		}
+	default:
+		return nil, fmt.Errorf("%w: import mode must be insert, upsert or replace", domain.ErrInvalidInput)
+	}
+
+	var records []importRecord
+	switch format {
+	case domain.FormatCSV:
+		records, err = readCSVTable(r, table)
+	case domain.FormatNDJSON:
+		records, err = readNDJSONTable(r)
+	default:
+		records, err = readJSONTable(r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidInput, err)
+	}
+
+	result := &domain.TableImportResult{
+		Table:  table.Name,
+		Mode:   mode,
+		DryRun: dryRun,
+		Rows:   len(records),
+		Errors: []domain.RowError{},
+	}
+
+	var statements []domain.Statement
+	// rowOf maps the statements back to the rows of the file
+	rowOf := make(map[int]int)
+	// kept holds the keys of the rows of the file, which replacing the table
+	// leaves in place
+	kept := make(map[string]bool)
+
+	for i, record := range records {
+		statement, key, err := importStatement(table, keys, mode, record, format == domain.FormatCSV)
+		if err != nil {
+			result.Errors = append(result.Errors, domain.RowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		if key != nil {
+			kept[importKey(key)] = true
+		}
+		rowOf[len(statements)] = i + 1
+		statements = append(statements, *statement)
+	}
+
+	// The statements run even when the import is not committed, so that a dry
+	// run reports every row the database refuses
+	err = s.repo.InTransaction(func(tx ports.AdminTransaction) error {
+		if mode == domain.ImportReplace {
+			if err := deleteRowsNotImported(tx, table, kept); err != nil {
+				return err
+			}
+		}
+		for i, statement := range statements {
+			if err := tx.ExecuteNonQuery(statement.Query, statement.Args...); err != nil {
+				result.Errors = append(result.Errors, domain.RowError{Row: rowOf[i], Error: err.Error()})
+			}
+		}
+
+		result.Imported = result.Rows - len(result.Errors)
+		if dryRun || len(result.Errors) > 0 {
+			return errRollback
+		}
+		return recordAudit(ctx, tx, &domain.AuditEntry{
+			Action:    domain.AuditImport,
+			TableName: table.Name,
+			Query:     fmt.Sprintf("%s of %d rows from %s", mode, result.Imported, format),
+		})
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, fmt.Errorf("failed to import into %s: %w", table.Name, err)
+	}
+	result.Committed = err == nil
+	return result, nil
+}
+
+// importStatement builds the statement writing an imported row, and returns
+// the values of its primary key, nil when the row leaves it to SQLite.
+// Replacing a table upserts its rows so that the rows of other tables
+// referencing them are kept.
+func importStatement(table *domain.TableInfo, keys []string, mode domain.ImportMode, record importRecord, emptyIsNull bool) (*domain.Statement, []interface{}, error) {
+	if record.err != nil {
+		return nil, nil, record.err
+	}
+
+	values := make(map[string]interface{}, len(record.values))
+	for name, value := range record.values {
+		column := findColumn(table, name)
+		if column == nil {
+			return nil, nil, fmt.Errorf("table %s has no column %s", table.Name, name)
+		}
+		values[column.Name] = value
+	}
+
+	var columns, placeholders, updates []string
+	var args, key []interface{}
+	// Follow the table order so statements do not depend on map order
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		raw, ok := values[column.Name]
+		if !ok {
+			continue
+		}
+		value, err := importValue(column, raw, emptyIsNull)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Leave auto-increment keys to SQLite
+		if value == nil && column.PrimaryKey && columnAffinity(column.Type) == "INTEGER" {
+			continue
+		}
+
+		name := quoteIdentifier(column.Name)
+		columns = append(columns, name)
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+		if column.PrimaryKey {
+			if value != nil {
+				key = append(key, value)
+			}
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", name, name))
+		}
+	}
+	if len(key) < len(keys) {
+		key = nil
+	}
+
+	if len(columns) == 0 {
+		return &domain.Statement{Query: fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", quoteIdentifier(table.Name))}, nil, nil
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdentifier(table.Name),
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "))
+
+	if mode == domain.ImportUpsert || mode == domain.ImportReplace && len(keys) > 0 {
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO ", strings.Join(keys, ", "))
+		if len(updates) == 0 {
+			query += "NOTHING"
+		} else {
+			query += "UPDATE SET " + strings.Join(updates, ", ")
+		}
+	}
+
+	return &domain.Statement{Query: query, Args: args}, key, nil
+}
+
+// importKey turns the values of a primary key into a map key. Values are
+// converted to the affinity of their column on import, like SQLite stores
+// them, so that the same key read back from the table gives the same string.
+func importKey(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// deleteRowsNotImported deletes the rows of a table replaced by an import
+// whose primary key is not in kept. Deleting only those leaves the rows of
+// the file, and the rows of other tables referencing them, in place; a table
+// without a primary key is emptied.
+func deleteRowsNotImported(tx ports.AdminStatements, table *domain.TableInfo, kept map[string]bool) error {
+	var names, conditions []string
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			names = append(names, column.Name)
+			conditions = append(conditions, quoteIdentifier(column.Name)+" = ?")
+		}
+	}
+	if len(names) == 0 {
+		if err := tx.ExecuteNonQuery("DELETE FROM " + quoteIdentifier(table.Name)); err != nil {
+			return fmt.Errorf("failed to empty %s: %w", table.Name, err)
+		}
+		return nil
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	rows, err := tx.ExecuteQuery(fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), quoteIdentifier(table.Name)))
+	if err != nil {
+		return fmt.Errorf("failed to read the keys of %s: %w", table.Name, err)
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+	for _, row := range rows {
+		key := make([]interface{}, len(names))
+		for i, name := range names {
+			key[i] = row[name]
+		}
+		if kept[importKey(key)] {
+			continue
+		}
+		if err := tx.ExecuteNonQuery(query, key...); err != nil {
+			return fmt.Errorf("failed to delete a row of %s missing from the file: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// importValue checks an imported value against the affinity of its column
+// and converts it. Empty CSV fields are NULL, except in NOT NULL columns
+// where they stand for an empty string. Text in columns declared as BLOB is
+// base64, as exported.
+func importValue(column *domain.ColumnInfo, value interface{}, emptyIsNull bool) (interface{}, error) {
+	affinity := columnAffinity(column.Type)
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+
+	case bool:
+		if affinity == "TEXT" {
+			return strconv.FormatBool(v), nil
+		}
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+
+	case json.Number:
+		switch affinity {
+		case "TEXT":
+			return v.String(), nil
+		case "INTEGER":
+			n, err := v.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("column %s expects an integer, got %s", column.Name, v)
+			}
+			return n, nil
+		}
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+
+	case string:
+		if v == "" && emptyIsNull && (!column.NotNull || affinity == "INTEGER" || affinity == "REAL") {
+			return nil, nil
+		}
+		switch affinity {
+		case "INTEGER":
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("column %s expects an integer, got %q", column.Name, v)
+			}
+			return n, nil
+		case "REAL":
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("column %s expects a number, got %q", column.Name, v)
+			}
+			return f, nil
+		}
+		if strings.Contains(strings.ToUpper(column.Type), "BLOB") {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("column %s expects base64 data, got %q", column.Name, v)
+			}
+			return data, nil
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("column %s cannot hold a JSON object or array", column.Name)
+}
+
+// readCSVTable reads a CSV file whose header names the columns of the table
+func readCSVTable(r io.Reader, table *domain.TableInfo) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, name := range header {
+		column := findColumn(table, strings.TrimSpace(name))
+		if column == nil {
+			return nil, fmt.Errorf("table %s has no column %s", table.Name, name)
+		}
+		if seen[column.Name] {
+			return nil, fmt.Errorf("column %s appears twice in the header", column.Name)
+		}
+		seen[column.Name] = true
+		header[i] = column.Name
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		if err != nil {
+			records = append(records, importRecord{err: fmt.Errorf("expected %d fields, got %d", len(header), len(fields))})
+			continue
+		}
+
+		values := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			values[name] = fields[i]
+		}
+		records = append(records, importRecord{values: values})
+	}
+}
+
+// readJSONTable reads a JSON array of objects keyed by column
+func readJSONTable(r io.Reader) ([]importRecord, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("JSON import must be an array of objects")
+	}
+
+	var records []importRecord
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		records = append(records, decodeJSONRow(raw))
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// readNDJSONTable reads one JSON object keyed by column per line, skipping
+// blank lines
+func readNDJSONTable(r io.Reader) ([]importRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+
+	var records []importRecord
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		records = append(records, decodeJSONRow(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func decodeJSONRow(raw []byte) importRecord {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil || values == nil {
+		return importRecord{err: errors.New("row is not a JSON object")}
+	}
+	return importRecord{values: values}
+}
+
+// writeJSONRow writes a row as a JSON object with its columns in order
+func writeJSONRow(w io.Writer, columns []string, values []interface{}) error {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(column)
+		value, err := json.Marshal(exportValue(values[i]))
+		if err != nil {
+			return err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// exportValue formats the times SQLite returns for DATETIME columns the way
+// CURRENT_TIMESTAMP stores them, and blobs in base64, so exports can be
+// imported back
+func exportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.DateTime)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	}
+	return value
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func TestImportTableAudit(t *testing.T) {
+	const file = "name\nWood\nStone\n"
+
+	tests := []struct {
+		name          string
+		file          string
+		dryRun        bool
+		refuseAudit   bool
+		wantCommitted bool
+		wantErr       bool
+	}{
+		{"committed", file, false, false, true, false},
+		{"dry run", file, true, false, false, false},
+		{"row refused", "name\nWood\nWood\n", false, false, false, false},
+		{"audit refused", file, false, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestAdminService(t, domain.DefaultQueryPolicy)
+			if tt.refuseAudit {
+				refuseAudit(t, service)
+			}
+
+			result, err := service.ImportTable(userContext(domain.RoleAdmin), strings.NewReader(tt.file), "resources", domain.FormatCSV, domain.ImportInsert, tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && result.Committed != tt.wantCommitted {
+				t.Errorf("ImportTable() committed = %v, want %v", result.Committed, tt.wantCommitted)
+			}
+
+			wantRows, wantEntries := int64(0), 0
+			if tt.wantCommitted {
+				wantRows, wantEntries = 2, 1
+			}
+			if n := countResources(t, service); n != wantRows {
+				t.Errorf("%d resources after the import, want %d", n, wantRows)
+			}
+			if n := countAudit(t, service, domain.AuditImport); n != wantEntries {
+				t.Errorf("%d import entries in the audit log, want %d", n, wantEntries)
+			}
+		})
+	}
+}
+
+func TestImportTableReplace(t *testing.T) {
+	service := newTestAdminService(t, domain.DefaultQueryPolicy)
+	for _, statement := range []string{
+		"INSERT INTO crafting_recipes (id, name) VALUES (1, 'Campfire')",
+		"INSERT INTO resources (id, name) VALUES (1, 'Wood'), (2, 'Stone')",
+		"INSERT INTO recipe_resources (recipe_id, resource_id, quantity) VALUES (1, 1, 10), (1, 2, 5)",
+	} {
+		if err := service.repo.ExecuteNonQuery(statement); err != nil {
+			t.Fatalf("seeding: %v", err)
+		}
+	}
+
+	file := "id,name\n1,Wood\n,Fiber\n"
+	result, err := service.ImportTable(userContext(domain.RoleAdmin), strings.NewReader(file), "resources", domain.FormatCSV, domain.ImportReplace, false)
+	if err != nil {
+		t.Fatalf("ImportTable() error = %v", err)
+	}
+	if !result.Committed || len(result.Errors) > 0 {
+		t.Fatalf("ImportTable() = %+v, want it committed", result)
+	}
+
+	rows, err := service.repo.ExecuteQuery("SELECT name FROM resources ORDER BY name")
+	if err != nil {
+		t.Fatalf("reading resources: %v", err)
+	}
+	var names []string
+	for _, row := range rows {
+		names = append(names, row["name"].(string))
+	}
+	if strings.Join(names, ",") != "Fiber,Wood" {
+		t.Errorf("resources = %v, want Fiber and Wood", names)
+	}
+
+	// The recipe needing Wood is kept, the one needing Stone went with it
+	rows, err = service.repo.ExecuteQuery("SELECT resource_id FROM recipe_resources")
+	if err != nil {
+		t.Fatalf("reading recipe_resources: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["resource_id"] != int64(1) {
+		t.Errorf("recipe_resources = %v, want the row of Wood only", rows)
+	}
+
+	t.Run("referenced table without a key", func(t *testing.T) {
+		for _, statement := range []string{
+			"CREATE TABLE tags (name TEXT UNIQUE)",
+			"CREATE TABLE labels (tag TEXT REFERENCES tags (name) ON DELETE CASCADE)",
+		} {
+			if err := service.repo.ExecuteNonQuery(statement); err != nil {
+				t.Fatalf("creating tables: %v", err)
+			}
+		}
+		_, err := service.ImportTable(userContext(domain.RoleAdmin), strings.NewReader("name\nred\n"), "tags", domain.FormatCSV, domain.ImportReplace, false)
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("ImportTable() error = %v, want domain.ErrInvalidInput", err)
+		}
+	})
+}
+
+// quotedRows reads the rows of a table as SQL literals, which tell apart
+// NULL, text, numbers and blobs
+func quotedRows(t *testing.T, service *adminService, table string) []string {
+	t.Helper()
+	rows, err := service.repo.ExecuteQuery("SELECT quote(id) || ' ' || quote(name) || ' ' || quote(weight) || ' ' || quote(data) || ' ' || quote(note) AS row FROM " + table + " ORDER BY id")
+	if err != nil {
+		t.Fatalf("reading %s: %v", table, err)
+	}
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i], _ = row["row"].(string)
+	}
+	return values
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []domain.ExportFormat{domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			service := newKeyTestService(t,
+				"CREATE TABLE samples (id INTEGER PRIMARY KEY, name TEXT, weight REAL, data BLOB, note TEXT NOT NULL)",
+				`INSERT INTO samples VALUES
+					(1, 'Wool', 1.5, X'00FF0A2C22', 'comma, "quote"'),
+					(2, NULL, NULL, NULL, ''),
+					(10, 'Ingot', 2, X'CAFE', 'line' || char(10) || 'break')`,
+			)
+			want := quotedRows(t, service, "samples")
+
+			var file strings.Builder
+			if err := service.ExportTable(&file, "samples", format); err != nil {
+				t.Fatalf("ExportTable() error = %v", err)
+			}
+
+			for _, statement := range []string{
+				"DELETE FROM samples WHERE id = 2",
+				"UPDATE samples SET name = 'Changed', data = X'00' WHERE id = 1",
+				"INSERT INTO samples VALUES (99, 'Extra', 0, NULL, '')",
+			} {
+				if err := service.repo.ExecuteNonQuery(statement); err != nil {
+					t.Fatalf("%s: %v", statement, err)
+				}
+			}
+
+			result, err := service.ImportTable(userContext(domain.RoleAdmin), strings.NewReader(file.String()), "samples", format, domain.ImportReplace, false)
+			if err != nil {
+				t.Fatalf("ImportTable() error = %v", err)
+			}
+			if !result.Committed || result.Imported != 3 {
+				t.Fatalf("ImportTable() = %+v, want 3 rows committed", result)
+			}
+			if got := quotedRows(t, service, "samples"); !reflect.DeepEqual(got, want) {
+				t.Errorf("rows after the round trip = %q, want %q\nexported:\n%s", got, want, file.String())
+			}
+		})
+	}
+}
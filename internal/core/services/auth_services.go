@@ -0,0 +1,311 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+const (
+	sessionDuration   = 12 * time.Hour
+	minPasswordLength = 8
+
+	// PBKDF2-SHA256 parameters, stored with each hash so they can be raised later
+	passwordHashScheme     = "pbkdf2-sha256"
+	passwordHashIterations = 600000
+	passwordSaltLength     = 16
+	passwordKeyLength      = 32
+)
+
+type authService struct {
+	repo ports.UserRepository
+}
+
+// NewAuthService creates a new admin authentication service
+func NewAuthService(repo ports.UserRepository) ports.AuthService {
+	return &authService{
+		repo: repo,
+	}
+}
+
+// Login checks the credentials and opens a new session, returning
+// domain.ErrUnauthorized when the username or password is wrong
+func (s *authService) Login(credentials domain.Credentials) (*domain.Session, error) {
+	user, err := s.repo.GetUserByUsername(strings.TrimSpace(credentials.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		// Hash anyway so unknown usernames take as long as wrong passwords
+		hashPassword(credentials.Password)
+		return nil, fmt.Errorf("%w: invalid username or password", domain.ErrUnauthorized)
+	}
+	if !verifyPassword(user.PasswordHash, credentials.Password) {
+		return nil, fmt.Errorf("%w: invalid username or password", domain.ErrUnauthorized)
+	}
+
+	if err := s.repo.DeleteExpiredSessions(); err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	session := &domain.Session{
+		Token:     base64.RawURLEncoding.EncodeToString(token),
+		User:      *user,
+		ExpiresAt: time.Now().Add(sessionDuration),
+	}
+	if err := s.repo.CreateSession(hashToken(session.Token), user.ID, session.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Logout closes a session
+func (s *authService) Logout(token string) error {
+	return s.repo.DeleteSession(hashToken(token))
+}
+
+// Authenticate returns the user of a session token, or domain.ErrUnauthorized
+// if the session does not exist or expired
+func (s *authService) Authenticate(token string) (*domain.User, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: login required", domain.ErrUnauthorized)
+	}
+
+	user, err := s.repo.GetSessionUser(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: session expired", domain.ErrUnauthorized)
+	}
+	return user, nil
+}
+
+// GetUsers retrieves every admin user
+func (s *authService) GetUsers() ([]domain.User, error) {
+	users, err := s.repo.GetAllUsers()
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []domain.User{}
+	}
+	return users, nil
+}
+
+// CreateUser validates and saves a new user with a hashed password
+func (s *authService) CreateUser(credentials domain.Credentials) (*domain.User, error) {
+	username := strings.TrimSpace(credentials.Username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", domain.ErrInvalidInput)
+	}
+	if !credentials.Role.Valid() {
+		return nil, fmt.Errorf("%w: role must be viewer, editor or admin", domain.ErrInvalidInput)
+	}
+	if err := checkPassword(credentials.Password); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: user %s already exists", domain.ErrConflict, username)
+	}
+
+	hash, err := hashPassword(credentials.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{Username: username, PasswordHash: hash, Role: credentials.Role}
+	if err := s.repo.CreateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", username, err)
+	}
+
+	return s.repo.GetUserByID(user.ID)
+}
+
+// UpdateUser changes the role and, when one is given, the password of a user.
+// The last admin cannot be demoted. A new password or a lower role ends the
+// sessions of the user, who has to log in again.
+func (s *authService) UpdateUser(id int, credentials domain.Credentials) (*domain.User, error) {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	endSessions := false
+	if credentials.Role != "" && credentials.Role != user.Role {
+		if !credentials.Role.Valid() {
+			return nil, fmt.Errorf("%w: role must be viewer, editor or admin", domain.ErrInvalidInput)
+		}
+		if err := s.keepAnAdmin(user); err != nil {
+			return nil, err
+		}
+		endSessions = !credentials.Role.Allows(user.Role)
+		user.Role = credentials.Role
+	}
+
+	if credentials.Password != "" {
+		if err := checkPassword(credentials.Password); err != nil {
+			return nil, err
+		}
+		if user.PasswordHash, err = hashPassword(credentials.Password); err != nil {
+			return nil, err
+		}
+		endSessions = true
+	}
+
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user %d: %w", id, err)
+	}
+	if endSessions {
+		if err := s.repo.DeleteUserSessions(id); err != nil {
+			return nil, fmt.Errorf("failed to end sessions of user %d: %w", id, err)
+		}
+	}
+	return user, nil
+}
+
+// DeleteUser deletes a user and its sessions. The last admin cannot be deleted.
+func (s *authService) DeleteUser(id int) error {
+	user, err := s.repo.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.ErrNotFound
+	}
+
+	if err := s.keepAnAdmin(user); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteUser(id); err != nil {
+		return fmt.Errorf("failed to delete user %d: %w", id, err)
+	}
+	return nil
+}
+
+// keepAnAdmin refuses to remove the admin role from the last admin. The
+// repository checks it again in the write itself, which two concurrent
+// requests removing the last two admins cannot both pass.
+func (s *authService) keepAnAdmin(user *domain.User) error {
+	if user.Role != domain.RoleAdmin {
+		return nil
+	}
+
+	admins, err := s.repo.CountUsersWithRole(domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return fmt.Errorf("%w: %s is the last admin", domain.ErrConflict, user.Username)
+	}
+	return nil
+}
+
+func checkPassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", domain.ErrInvalidInput, minPasswordLength)
+	}
+	return nil
+}
+
+// hashPassword derives a salted PBKDF2 hash encoded as scheme$iterations$salt$key
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := pbkdf2SHA256(password, salt, passwordHashIterations, passwordKeyLength)
+	return strings.Join([]string{
+		passwordHashScheme,
+		strconv.Itoa(passwordHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// verifyPassword checks a password against a hash made by hashPassword
+func verifyPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != passwordHashScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	got := pbkdf2SHA256(password, salt, iterations, len(want))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// pbkdf2SHA256 derives a key with PBKDF2 and HMAC-SHA256 as described in RFC 8018
+func pbkdf2SHA256(password string, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha256.New, []byte(password))
+	key := make([]byte, 0, keyLength+prf.Size())
+	block := make([]byte, 4)
+
+	for i := uint32(1); len(key) < keyLength; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(block, i)
+		prf.Write(block)
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+
+	return key[:keyLength]
+}
+
+// hashToken hashes session tokens so a leaked database cannot be used to log in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
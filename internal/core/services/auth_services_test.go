@@ -0,0 +1,267 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// memoryUserRepo is a ports.UserRepository holding users and sessions in maps
+type memoryUserRepo struct {
+	users    map[int]domain.User
+	sessions map[string]int
+	nextID   int
+}
+
+func newMemoryUserRepo() *memoryUserRepo {
+	return &memoryUserRepo{users: make(map[int]domain.User), sessions: make(map[string]int), nextID: 1}
+}
+
+func (r *memoryUserRepo) GetAllUsers() ([]domain.User, error) {
+	var users []domain.User
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepo) GetUserByID(id int) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepo) GetUserByUsername(username string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepo) CreateUser(user *domain.User) error {
+	user.ID = r.nextID
+	r.nextID++
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepo) UpdateUser(user *domain.User) error {
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if user.Role != domain.RoleAdmin && r.lastAdmin(stored) {
+		return domain.ErrConflict
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepo) DeleteUser(id int) error {
+	stored, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if r.lastAdmin(stored) {
+		return domain.ErrConflict
+	}
+	delete(r.users, id)
+	return r.DeleteUserSessions(id)
+}
+
+// lastAdmin tells whether a stored user is the only admin
+func (r *memoryUserRepo) lastAdmin(user domain.User) bool {
+	admins, _ := r.CountUsersWithRole(domain.RoleAdmin)
+	return user.Role == domain.RoleAdmin && admins <= 1
+}
+
+func (r *memoryUserRepo) CountUsersWithRole(role domain.Role) (int, error) {
+	count := 0
+	for _, user := range r.users {
+		if user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepo) CreateSession(tokenHash string, userID int, expiresAt time.Time) error {
+	r.sessions[tokenHash] = userID
+	return nil
+}
+
+func (r *memoryUserRepo) GetSessionUser(tokenHash string) (*domain.User, error) {
+	id, ok := r.sessions[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return r.GetUserByID(id)
+}
+
+func (r *memoryUserRepo) DeleteSession(tokenHash string) error {
+	delete(r.sessions, tokenHash)
+	return nil
+}
+
+func (r *memoryUserRepo) DeleteUserSessions(userID int) error {
+	for tokenHash, id := range r.sessions {
+		if id == userID {
+			delete(r.sessions, tokenHash)
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepo) DeleteExpiredSessions() error {
+	return nil
+}
+
+// testPassword is hashed once, PBKDF2 being slow on purpose
+const testPassword = "correct horse"
+
+var testPasswordHash = func() string {
+	hash, err := hashPassword(testPassword)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}()
+
+// addUser stores a user whose password is testPassword
+func (r *memoryUserRepo) addUser(username string, role domain.Role) domain.User {
+	user := domain.User{Username: username, PasswordHash: testPasswordHash, Role: role}
+	r.CreateUser(&user)
+	return user
+}
+
+func TestLogin(t *testing.T) {
+	repo := newMemoryUserRepo()
+	boss := repo.addUser("boss", domain.RoleAdmin)
+	service := NewAuthService(repo)
+
+	tests := []struct {
+		name        string
+		credentials domain.Credentials
+		wantErr     error
+	}{
+		{"valid credentials", domain.Credentials{Username: "boss", Password: testPassword}, nil},
+		{"username with spaces", domain.Credentials{Username: " boss ", Password: testPassword}, nil},
+		{"wrong password", domain.Credentials{Username: "boss", Password: "wrong password"}, domain.ErrUnauthorized},
+		{"unknown user", domain.Credentials{Username: "nobody", Password: testPassword}, domain.ErrUnauthorized},
+		{"empty password", domain.Credentials{Username: "boss"}, domain.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := service.Login(tt.credentials)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			user, err := service.Authenticate(session.Token)
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.ID != boss.ID {
+				t.Errorf("Authenticate() user = %d, want %d", user.ID, boss.ID)
+			}
+			if _, stored := repo.sessions[session.Token]; stored {
+				t.Error("Login() stored the session token instead of its hash")
+			}
+		})
+	}
+}
+
+func TestKeepAnAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        domain.Role
+		otherAdmins int
+		wantErr     bool
+	}{
+		{"last admin", domain.RoleAdmin, 0, true},
+		{"one of two admins", domain.RoleAdmin, 1, false},
+		{"editor", domain.RoleEditor, 1, false},
+		{"viewer", domain.RoleViewer, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryUserRepo()
+			for i := 0; i < tt.otherAdmins; i++ {
+				repo.addUser(fmt.Sprintf("admin%d", i), domain.RoleAdmin)
+			}
+			user := repo.addUser("target", tt.role)
+			service := NewAuthService(repo)
+
+			err := service.(*authService).keepAnAdmin(&user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("keepAnAdmin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrConflict) {
+				t.Errorf("keepAnAdmin() error = %v, want domain.ErrConflict", err)
+			}
+
+			// Demoting and deleting go through the same check
+			if tt.role != domain.RoleViewer {
+				_, err = service.UpdateUser(user.ID, domain.Credentials{Role: domain.RoleViewer})
+				if (err != nil) != tt.wantErr {
+					t.Errorf("UpdateUser() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if err := service.DeleteUser(user.ID); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateUserEndsSessions(t *testing.T) {
+	tests := []struct {
+		name         string
+		role         domain.Role
+		credentials  domain.Credentials
+		wantSessions bool
+	}{
+		{"new password", domain.RoleEditor, domain.Credentials{Password: "another password"}, false},
+		{"lower role", domain.RoleEditor, domain.Credentials{Role: domain.RoleViewer}, false},
+		{"higher role", domain.RoleEditor, domain.Credentials{Role: domain.RoleAdmin}, true},
+		{"same role", domain.RoleEditor, domain.Credentials{Role: domain.RoleEditor}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryUserRepo()
+			repo.addUser("boss", domain.RoleAdmin)
+			user := repo.addUser("target", tt.role)
+			service := NewAuthService(repo)
+
+			session, err := service.Login(domain.Credentials{Username: "target", Password: testPassword})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			if _, err := service.UpdateUser(user.ID, tt.credentials); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			_, err = service.Authenticate(session.Token)
+			if tt.wantSessions && err != nil {
+				t.Errorf("Authenticate() error = %v, want the session kept", err)
+			}
+			if !tt.wantSessions && !errors.Is(err, domain.ErrUnauthorized) {
+				t.Errorf("Authenticate() error = %v, want the session ended", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// backupTimeLayout is the UTC time in backup file names
+const backupTimeLayout = "20060102-150405.000"
+
+// backupNamePattern matches the files written by the backup service, such as
+// palworld-20261016-203400.000-manual.db
+var backupNamePattern = regexp.MustCompile(`^palworld-(\d{8}-\d{6}\.\d{3})-(manual|auto|pre-restore)\.db$`)
+
+type backupService struct {
+	repo  ports.BackupRepository
+	audit ports.AuditRepository
+	dir   string
+	keep  int
+
+	// mu serializes backups and restores
+	mu sync.Mutex
+}
+
+// NewBackupService creates a new backup service storing snapshots in dir. It
+// keeps the latest keep automatic backups and the latest keep backups taken
+// before restores, or all of them when keep is 0. Manual backups are kept.
+func NewBackupService(repo ports.BackupRepository, audit ports.AuditRepository, dir string, keep int) ports.BackupService {
+	return &backupService{
+		repo:  repo,
+		audit: audit,
+		dir:   dir,
+		keep:  keep,
+	}
+}
+
+// CreateBackup takes a snapshot of the live database. Automatic backups
+// beyond the retention are deleted afterwards.
+func (s *backupService) CreateBackup(ctx context.Context, kind domain.BackupKind) (*domain.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup, err := s.createBackup(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == domain.BackupAutomatic {
+		if err := s.prune(kind); err != nil {
+			return nil, err
+		}
+	}
+	return backup, nil
+}
+
+// ListBackups lists the stored backups, newest first
+func (s *backupService) ListBackups() ([]domain.Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []domain.Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := []domain.Backup{}
+	for _, entry := range entries {
+		backup, ok := parseBackupName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backup.Size = info.Size()
+		backups = append(backups, *backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// OpenBackup opens a stored backup for download
+func (s *backupService) OpenBackup(name string) (io.ReadSeekCloser, *domain.Backup, error) {
+	backup, ok := parseBackupName(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open backup %s: %w", name, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to open backup %s: %w", name, err)
+	}
+	backup.Size = info.Size()
+	return file, backup, nil
+}
+
+// DeleteBackup deletes a stored backup
+func (s *backupService) DeleteBackup(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := parseBackupName(name); !ok {
+		return fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+	err := os.Remove(filepath.Join(s.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete backup %s: %w", name, err)
+	}
+
+	return recordAudit(ctx, s.audit, &domain.AuditEntry{Action: domain.AuditBackup, Query: "delete " + name})
+}
+
+// RestoreBackup replaces the live database with a stored backup
+func (s *backupService) RestoreBackup(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := parseBackupName(name); !ok {
+		return fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: backup %s does not exist", domain.ErrNotFound, name)
+	}
+
+	return s.restore(ctx, path, name)
+}
+
+// RestoreUpload replaces the live database with an uploaded database file
+func (s *backupService) RestoreUpload(ctx context.Context, r io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// The upload is kept next to the backups, hidden from the list, while it
+	// is checked and restored
+	file, err := os.CreateTemp(s.dir, ".upload-*.db")
+	if err != nil {
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+
+	return s.restore(ctx, file.Name(), "upload")
+}
+
+// restore checks a database file, backs up the live database so the restore
+// can be undone, then copies the file over the live database
+func (s *backupService) restore(ctx context.Context, path, source string) error {
+	if err := s.repo.CheckBackup(path); err != nil {
+		return err
+	}
+
+	safety, err := s.createBackup(ctx, domain.BackupPreRestore)
+	if err != nil {
+		return err
+	}
+	if err := s.prune(domain.BackupPreRestore); err != nil {
+		return err
+	}
+
+	if err := s.repo.RestoreFrom(path); err != nil {
+		return err
+	}
+
+	// The entry goes to the audit log of the restored database
+	return recordAudit(ctx, s.audit, &domain.AuditEntry{
+		Action: domain.AuditRestore,
+		Query:  fmt.Sprintf("restore from %s, previous database saved as %s", source, safety.Name),
+	})
+}
+
+func (s *backupService) createBackup(ctx context.Context, kind domain.BackupKind) (*domain.Backup, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Millisecond)
+	name := fmt.Sprintf("palworld-%s-%s.db", createdAt.Format(backupTimeLayout), kind)
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%w: backup %s already exists", domain.ErrConflict, name)
+	}
+
+	if err := s.repo.BackupTo(path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", name, err)
+	}
+
+	backup := &domain.Backup{Name: name, Kind: kind, Size: info.Size(), CreatedAt: createdAt}
+	if err := recordAudit(ctx, s.audit, &domain.AuditEntry{Action: domain.AuditBackup, Query: "create " + name}); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// prune deletes the oldest backups of a kind beyond the retention
+func (s *backupService) prune(kind domain.BackupKind) error {
+	if s.keep <= 0 {
+		return nil
+	}
+
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	kept := 0
+	for _, backup := range backups {
+		if backup.Kind != kind {
+			continue
+		}
+		if kept++; kept <= s.keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, backup.Name)); err != nil {
+			return fmt.Errorf("failed to delete old backup %s: %w", backup.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseBackupName reads the time and kind of a backup from its file name,
+// refusing names the backup service did not write
+func parseBackupName(name string) (*domain.Backup, bool) {
+	match := backupNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return nil, false
+	}
+	createdAt, err := time.Parse(backupTimeLayout, match[1])
+	if err != nil {
+		return nil, false
+	}
+	return &domain.Backup{Name: name, Kind: domain.BackupKind(match[2]), CreatedAt: createdAt}, true
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/core/domain"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, kind := range []string{"auto", "pre-restore", "manual"} {
+		for minute := 0; minute < 4; minute++ {
+			name := fmt.Sprintf("palworld-20261016-20%02d00.000-%s.db", minute, kind)
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("db"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	service := NewBackupService(nil, nil, dir, 2).(*backupService)
+	for _, kind := range []domain.BackupKind{domain.BackupAutomatic, domain.BackupPreRestore} {
+		if err := service.prune(kind); err != nil {
+			t.Fatalf("prune(%s) error = %v", kind, err)
+		}
+	}
+
+	backups, err := service.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	counts := make(map[domain.BackupKind]int)
+	for _, backup := range backups {
+		counts[backup.Kind]++
+		if backup.Kind != domain.BackupManual && backup.CreatedAt.Minute() < 2 {
+			t.Errorf("prune kept the old backup %s", backup.Name)
+		}
+	}
+	want := map[domain.BackupKind]int{domain.BackupAutomatic: 2, domain.BackupPreRestore: 2, domain.BackupManual: 4}
+	for kind, count := range want {
+		if counts[kind] != count {
+			t.Errorf("%d %s backups left, want %d", counts[kind], kind, count)
+		}
+	}
+}
+
+// openBackupTestDB opens a migrated database and runs statements on it
+func openBackupTestDB(t *testing.T, path string, statements ...string) *database.SQLiteDB {
+	t.Helper()
+	db, err := database.NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, statement := range statements {
+		if err := db.ExecuteNonQuery(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return db
+}
+
+// newTestBackupService returns a backup service over a live database holding
+// the resource Wood, storing its backups in a temporary directory
+func newTestBackupService(t *testing.T) (*backupService, *database.SQLiteDB) {
+	t.Helper()
+	dir := t.TempDir()
+	db := openBackupTestDB(t, filepath.Join(dir, "live.db"), "INSERT INTO resources (name) VALUES ('Wood')")
+	return NewBackupService(db, db, filepath.Join(dir, "backups"), 2).(*backupService), db
+}
+
+// resourceNames lists the resources of a database by name
+func resourceNames(t *testing.T, db *database.SQLiteDB) []string {
+	t.Helper()
+	rows, err := db.ExecuteQuery("SELECT name FROM resources ORDER BY name")
+	if err != nil {
+		t.Fatalf("reading resources: %v", err)
+	}
+	names := []string{}
+	for _, row := range rows {
+		names = append(names, fmt.Sprint(row["name"]))
+	}
+	return names
+}
+
+// backupsOfKind lists the stored backups of a kind
+func backupsOfKind(t *testing.T, service *backupService, kind domain.BackupKind) []domain.Backup {
+	t.Helper()
+	backups, err := service.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	var matching []domain.Backup
+	for _, backup := range backups {
+		if backup.Kind == kind {
+			matching = append(matching, backup)
+		}
+	}
+	return matching
+}
+
+func TestCreateAndRestoreBackup(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+	service, db := newTestBackupService(t)
+
+	backup, err := service.CreateBackup(ctx, domain.BackupManual)
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if backup.Kind != domain.BackupManual || backup.Size == 0 {
+		t.Errorf("CreateBackup() = %+v, want a manual backup with data", backup)
+	}
+	if listed := backupsOfKind(t, service, domain.BackupManual); len(listed) != 1 || listed[0].Name != backup.Name {
+		t.Errorf("manual backups = %+v, want %s", listed, backup.Name)
+	}
+
+	if err := db.ExecuteNonQuery("UPDATE resources SET name = 'Stone'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RestoreBackup(ctx, backup.Name); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if names := resourceNames(t, db); !reflect.DeepEqual(names, []string{"Wood"}) {
+		t.Errorf("resources after the restore = %v, want [Wood]", names)
+	}
+
+	// The database replaced by the restore is saved first, so the restore
+	// can be undone
+	safety := backupsOfKind(t, service, domain.BackupPreRestore)
+	if len(safety) != 1 {
+		t.Fatalf("pre-restore backups = %+v, want 1", safety)
+	}
+	entries, err := db.GetAuditEntries(domain.AuditFilter{Action: domain.AuditRestore})
+	if err != nil {
+		t.Fatalf("GetAuditEntries() error = %v", err)
+	}
+	if len(entries) != 1 || !strings.Contains(entries[0].Query, safety[0].Name) {
+		t.Errorf("restore entries = %+v, want one naming %s", entries, safety[0].Name)
+	}
+
+	if err := service.RestoreBackup(ctx, safety[0].Name); err != nil {
+		t.Fatalf("RestoreBackup() of the pre-restore backup error = %v", err)
+	}
+	if names := resourceNames(t, db); !reflect.DeepEqual(names, []string{"Stone"}) {
+		t.Errorf("resources after undoing the restore = %v, want [Stone]", names)
+	}
+}
+
+func TestRestoreUpload(t *testing.T) {
+	ctx := userContext(domain.RoleAdmin)
+
+	t.Run("older schema", func(t *testing.T) {
+		service, db := newTestBackupService(t)
+		upload := filepath.Join(t.TempDir(), "upload.db")
+		old := openBackupTestDB(t, upload, "INSERT INTO resources (name) VALUES ('Paldium')")
+		if _, err := old.MigrateDown(1); err != nil {
+			t.Fatalf("MigrateDown() error = %v", err)
+		}
+		old.Close()
+
+		file, err := os.Open(upload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if err := service.RestoreUpload(ctx, file); err != nil {
+			t.Fatalf("RestoreUpload() error = %v", err)
+		}
+
+		if names := resourceNames(t, db); !reflect.DeepEqual(names, []string{"Paldium"}) {
+			t.Errorf("resources after the restore = %v, want [Paldium]", names)
+		}
+		// The restored database is migrated to the current schema
+		status, err := db.MigrationStatus()
+		if err != nil {
+			t.Fatalf("MigrationStatus() error = %v", err)
+		}
+		for _, migration := range status {
+			if !migration.Applied {
+				t.Errorf("migration %d not applied after the restore", migration.Version)
+			}
+		}
+		if n := len(backupsOfKind(t, service, domain.BackupPreRestore)); n != 1 {
+			t.Errorf("%d pre-restore backups, want 1", n)
+		}
+	})
+
+	// newerSchema marks a database as migrated past the schema this server knows
+	newerSchema := "INSERT INTO schema_migrations (version, name) VALUES (9999, 'future')"
+	// corruptIndex points an index at a column it does not hold the values of
+	corruptIndex := []string{
+		"INSERT INTO resources (name) VALUES ('Stone'), ('Fiber')",
+		"CREATE INDEX resources_by_name ON resources (name)",
+		"PRAGMA writable_schema = ON",
+		"UPDATE sqlite_master SET sql = 'CREATE INDEX resources_by_name ON resources (id)' WHERE name = 'resources_by_name'",
+		"PRAGMA writable_schema = OFF",
+	}
+
+	tests := []struct {
+		name       string
+		statements []string
+		file       []byte
+	}{
+		{name: "not a SQLite database", file: []byte("name\nWood\n")},
+		{name: "not a Palworld Helper database", statements: []string{"DROP TABLE schema_migrations"}},
+		{name: "newer schema", statements: []string{newerSchema}},
+		{name: "failed integrity check", statements: corruptIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, db := newTestBackupService(t)
+			file := tt.file
+			if file == nil {
+				upload := filepath.Join(t.TempDir(), "upload.db")
+				openBackupTestDB(t, upload, append([]string{"DELETE FROM resources"}, tt.statements...)...).Close()
+				var err error
+				if file, err = os.ReadFile(upload); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := service.RestoreUpload(ctx, strings.NewReader(string(file)))
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("RestoreUpload() error = %v, want ErrInvalidInput", err)
+			}
+			if names := resourceNames(t, db); !reflect.DeepEqual(names, []string{"Wood"}) {
+				t.Errorf("resources after a refused restore = %v, want [Wood]", names)
+			}
+			// A refused upload leaves no backup and no stored file behind
+			entries, err := os.ReadDir(service.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("%d files left in the backup directory, want none", len(entries))
+			}
+		})
+	}
+}
+
+func TestRestoreBackupNames(t *testing.T) {
+	ctx := context.Background()
+	service, db := newTestBackupService(t)
+	if _, err := service.CreateBackup(userContext(domain.RoleAdmin), domain.BackupManual); err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+
+	// The live database sits one directory above the backups
+	for _, name := range []string{
+		"../live.db",
+		"..",
+		"backups/../../live.db",
+		"palworld-20261016-203400.000-manual.db/../../live.db",
+		"/etc/passwd",
+		"palworld-20261016-203400.000-manual.db",
+	} {
+		if err := service.RestoreBackup(ctx, name); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("RestoreBackup(%q) error = %v, want ErrNotFound", name, err)
+		}
+		if err := service.DeleteBackup(ctx, name); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("DeleteBackup(%q) error = %v, want ErrNotFound", name, err)
+		}
+		if _, _, err := service.OpenBackup(name); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("OpenBackup(%q) error = %v, want ErrNotFound", name, err)
+		}
+	}
+
+	if names := resourceNames(t, db); !reflect.DeepEqual(names, []string{"Wood"}) {
+		t.Errorf("resources = %v, want [Wood]", names)
+	}
+	if n := len(backupsOfKind(t, service, domain.BackupPreRestore)); n != 0 {
+		t.Errorf("%d pre-restore backups, want none", n)
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type breedingService struct {
+	repo   ports.PalRepository
+	images fs.FS
+}
+
+// NewBreedingService creates a new breeding service, images being the
+// paldeck images as given to NewPalService
+func NewBreedingService(repo ports.PalRepository, images fs.FS) ports.BreedingService {
+	return &breedingService{
+		repo:   repo,
+		images: images,
+	}
+}
+
+// Breed computes the child of two parents
+func (s *breedingService) Breed(parent1ID, parent2ID int) (*domain.BreedingResult, error) {
+	table, err := s.loadTable()
+	if err != nil {
+		return nil, err
+	}
+
+	parent1, ok := table.pals[parent1ID]
+	if !ok {
+		return nil, fmt.Errorf("parent %d: %w", parent1ID, domain.ErrNotFound)
+	}
+	parent2, ok := table.pals[parent2ID]
+	if !ok {
+		return nil, fmt.Errorf("parent %d: %w", parent2ID, domain.ErrNotFound)
+	}
+
+	child, special, ok := table.child(parent1, parent2)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s and %s cannot be bred together", domain.ErrInvalidInput, parent1.Name, parent2.Name)
+	}
+
+	child.ImagePath = paldeckImagePath(child, s.images)
+	return &domain.BreedingResult{
+		Parent1: palRef(parent1),
+		Parent2: palRef(parent2),
+		Child:   child,
+		Special: special,
+	}, nil
+}
+
+// FindPath finds the breedings leading from the owned pals to the target in
+// the fewest generations. Each generation breeds every pair of pals obtained
+// so far, so the first generation producing the target is the shortest one.
+func (s *breedingService) FindPath(request domain.BreedingPathRequest) (*domain.BreedingPath, error) {
+	table, err := s.loadTable()
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := table.pals[request.TargetID]
+	if !ok {
+		return nil, fmt.Errorf("target %d: %w", request.TargetID, domain.ErrNotFound)
+	}
+
+	generation := make(map[int]int)
+	frontier := make(map[int]bool)
+	for _, id := range request.Owned {
+		if _, ok := table.pals[id]; !ok {
+			return nil, fmt.Errorf("owned pal %d: %w", id, domain.ErrNotFound)
+		}
+		generation[id] = 0
+		frontier[id] = true
+	}
+
+	parents := make(map[int][2]int)
+	for gen := 1; ; gen++ {
+		if _, found := generation[target.ID]; found {
+			break
+		}
+
+		obtained := make([]int, 0, len(generation))
+		for id := range generation {
+			obtained = append(obtained, id)
+		}
+		sort.Ints(obtained)
+
+		next := make(map[int]bool)
+		for i, a := range obtained {
+			for _, b := range obtained[i:] {
+				// Pairs of older pals were already bred in a previous generation
+				if !frontier[a] && !frontier[b] {
+					continue
+				}
+
+				child, _, ok := table.child(table.pals[a], table.pals[b])
+				if !ok {
+					continue
+				}
+				if _, known := generation[child.ID]; known || next[child.ID] {
+					continue
+				}
+				next[child.ID] = true
+				parents[child.ID] = [2]int{a, b}
+			}
+		}
+
+		if len(next) == 0 {
+			return nil, fmt.Errorf("%w for %s", domain.ErrNoBreedingPath, target.Name)
+		}
+		for id := range next {
+			generation[id] = gen
+		}
+		frontier = next
+	}
+
+	path := &domain.BreedingPath{
+		Target:      palRef(target),
+		Generations: generation[target.ID],
+		Steps:       []domain.BreedingStep{},
+	}
+
+	// Walk back from the target, adding parents before their children
+	added := make(map[int]bool)
+	var addSteps func(id int)
+	addSteps = func(id int) {
+		if generation[id] == 0 || added[id] {
+			return
+		}
+		added[id] = true
+
+		pair := parents[id]
+		addSteps(pair[0])
+		addSteps(pair[1])
+		path.Steps = append(path.Steps, domain.BreedingStep{
+			Generation: generation[id],
+			Parent1:    palRef(table.pals[pair[0]]),
+			Parent2:    palRef(table.pals[pair[1]]),
+			Child:      palRef(table.pals[id]),
+		})
+	}
+	addSteps(target.ID)
+
+	return path, nil
+}
+
+// breedingTable holds what is needed to compute the child of any two pals
+type breedingTable struct {
+	pals map[int]domain.Pal
+	// candidates are the pals a regular breeding can produce, in tie-break order
+	candidates []domain.Pal
+	special    map[[2]int]int
+}
+
+func (s *breedingService) loadTable() (*breedingTable, error) {
+	pals, err := s.repo.GetAllPals()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pals: %w", err)
+	}
+
+	combinations, err := s.repo.GetBreedingCombinations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breeding combinations: %w", err)
+	}
+
+	table := &breedingTable{
+		pals:    make(map[int]domain.Pal),
+		special: make(map[[2]int]int),
+	}
+
+	// Pals obtained through a special combination can't come from the breeding power rule
+	specialChild := make(map[int]bool)
+	for _, combination := range combinations {
+		table.special[parentKey(combination.Parent1ID, combination.Parent2ID)] = combination.ChildID
+		specialChild[combination.ChildID] = true
+	}
+
+	// Pals are listed by paldeck number, which breaks ties between equally close powers
+	for _, pal := range pals {
+		table.pals[pal.ID] = pal
+		if pal.BreedingPower > 0 && !specialChild[pal.ID] {
+			table.candidates = append(table.candidates, pal)
+		}
+	}
+
+	return table, nil
+}
+
+// child returns the child of two parents: a special combination when one
+// exists, the parent species when both are the same, and otherwise the pal
+// whose breeding power is closest to the average of the parents' powers
+func (t *breedingTable) child(parent1, parent2 domain.Pal) (domain.Pal, bool, bool) {
+	if childID, ok := t.special[parentKey(parent1.ID, parent2.ID)]; ok {
+		child, ok := t.pals[childID]
+		return child, true, ok
+	}
+
+	if parent1.ID == parent2.ID {
+		return parent1, false, true
+	}
+
+	if parent1.BreedingPower == 0 || parent2.BreedingPower == 0 || len(t.candidates) == 0 {
+		return domain.Pal{}, false, false
+	}
+
+	target := (parent1.BreedingPower + parent2.BreedingPower + 1) / 2
+	best := t.candidates[0]
+	for _, candidate := range t.candidates[1:] {
+		if abs(candidate.BreedingPower-target) < abs(best.BreedingPower-target) {
+			best = candidate
+		}
+	}
+
+	return best, false, true
+}
+
+// parentKey orders a pair of parents so both orders map to the same combination
+func parentKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+func palRef(pal domain.Pal) domain.PalRef {
+	return domain.PalRef{ID: pal.ID, Name: pal.Name}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
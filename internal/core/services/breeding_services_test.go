@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// fakePalRepo serves pals in paldeck order and the special combinations from
+// memory. Its other methods are not used by the breeding service.
+type fakePalRepo struct {
+	ports.PalRepository
+	pals         []domain.Pal
+	combinations []domain.BreedingCombination
+}
+
+func (r *fakePalRepo) GetAllPals() ([]domain.Pal, error) {
+	return r.pals, nil
+}
+
+func (r *fakePalRepo) GetBreedingCombinations() ([]domain.BreedingCombination, error) {
+	return r.combinations, nil
+}
+
+// newBreedingService breeds Alpha and Foxy into Legend through a special
+// combination. Coral and Dune share the same breeding power.
+func newBreedingService() ports.BreedingService {
+	return NewBreedingService(&fakePalRepo{
+		pals: []domain.Pal{
+			{ID: 1, PaldeckNumber: 1, Name: "Alpha", BreedingPower: 1000},
+			{ID: 2, PaldeckNumber: 2, Name: "Bolt", BreedingPower: 900},
+			{ID: 3, PaldeckNumber: 3, Name: "Coral", BreedingPower: 800},
+			{ID: 4, PaldeckNumber: 4, Name: "Dune", BreedingPower: 800},
+			{ID: 5, PaldeckNumber: 5, Name: "Ember", BreedingPower: 500},
+			{ID: 6, PaldeckNumber: 6, Name: "Foxy", BreedingPower: 100},
+			{ID: 7, PaldeckNumber: 7, Name: "Legend", BreedingPower: 50},
+			{ID: 8, PaldeckNumber: 8, Name: "Tower"},
+		},
+		combinations: []domain.BreedingCombination{{Parent1ID: 6, Parent2ID: 1, ChildID: 7}},
+	}, nil)
+}
+
+func TestBreed(t *testing.T) {
+	service := newBreedingService()
+
+	tests := []struct {
+		name    string
+		parent1 int
+		parent2 int
+		child   string
+		special bool
+	}{
+		{"closest breeding power", 1, 5, "Coral", false},
+		{"same species", 5, 5, "Ember", false},
+		{"equal breeding powers broken by paldeck number", 2, 5, "Coral", false},
+		{"equal distances broken by paldeck number", 5, 6, "Ember", false},
+		{"special combination", 1, 6, "Legend", true},
+		{"special combination in reverse order", 6, 1, "Legend", true},
+		{"special child left out of the power rule", 6, 6, "Foxy", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Breed(tt.parent1, tt.parent2)
+			if err != nil {
+				t.Fatalf("Breed(%d, %d) error = %v", tt.parent1, tt.parent2, err)
+			}
+			if result.Child.Name != tt.child || result.Special != tt.special {
+				t.Errorf("Breed(%d, %d) = %s (special %v), want %s (special %v)",
+					tt.parent1, tt.parent2, result.Child.Name, result.Special, tt.child, tt.special)
+			}
+		})
+	}
+
+	if _, err := service.Breed(1, 8); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Breed() of an unbreedable pal error = %v, want domain.ErrInvalidInput", err)
+	}
+	if _, err := service.Breed(1, 99); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Breed() of an unknown pal error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	service := newBreedingService()
+
+	tests := []struct {
+		name        string
+		owned       []int
+		target      int
+		generations int
+		steps       []string
+	}{
+		{
+			name:   "already owned",
+			owned:  []int{1, 5},
+			target: 5,
+			steps:  []string{},
+		},
+		{
+			name:        "one generation",
+			owned:       []int{1, 5, 6},
+			target:      3,
+			generations: 1,
+			steps:       []string{"1: Alpha + Ember = Coral"},
+		},
+		{
+			name:        "several generations",
+			owned:       []int{1, 6},
+			target:      3,
+			generations: 3,
+			steps: []string{
+				"1: Alpha + Foxy = Legend",
+				"2: Alpha + Legend = Ember",
+				"3: Alpha + Ember = Coral",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := service.FindPath(domain.BreedingPathRequest{Owned: tt.owned, TargetID: tt.target})
+			if err != nil {
+				t.Fatalf("FindPath() error = %v", err)
+			}
+			if path.Generations != tt.generations {
+				t.Errorf("generations = %d, want %d", path.Generations, tt.generations)
+			}
+			steps := []string{}
+			for _, step := range path.Steps {
+				steps = append(steps, fmt.Sprintf("%d: %s + %s = %s", step.Generation, step.Parent1.Name, step.Parent2.Name, step.Child.Name))
+			}
+			if !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("steps = %q, want %q", steps, tt.steps)
+			}
+		})
+	}
+
+	if _, err := service.FindPath(domain.BreedingPathRequest{Owned: []int{6}, TargetID: 1}); !errors.Is(err, domain.ErrNoBreedingPath) {
+		t.Errorf("FindPath() of an unreachable pal error = %v, want domain.ErrNoBreedingPath", err)
+	}
+	if _, err := service.FindPath(domain.BreedingPathRequest{Owned: []int{1}, TargetID: 99}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindPath() of an unknown target error = %v, want domain.ErrNotFound", err)
+	}
+	if _, err := service.FindPath(domain.BreedingPathRequest{Owned: []int{99}, TargetID: 1}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindPath() from an unknown pal error = %v, want domain.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type craftingListService struct {
+	repo       ports.CraftingListRepository
+	recipeRepo ports.CraftingRepository
+	crafting   ports.CraftingService
+	versions   ports.GameVersionService
+}
+
+// NewCraftingListService creates a new saved crafting list service
+func NewCraftingListService(
+	repo ports.CraftingListRepository,
+	recipeRepo ports.CraftingRepository,
+	crafting ports.CraftingService,
+	versions ports.GameVersionService,
+) ports.CraftingListService {
+	return &craftingListService{
+		repo:       repo,
+		recipeRepo: recipeRepo,
+		crafting:   crafting,
+		versions:   versions,
+	}
+}
+
+// GetAllLists retrieves every saved crafting list, flagging stale ones
+func (s *craftingListService) GetAllLists() ([]domain.CraftingList, error) {
+	lists, err := s.repo.GetAllCraftingLists()
+	if err != nil {
+		return nil, err
+	}
+	if lists == nil {
+		lists = []domain.CraftingList{}
+	}
+
+	if err := s.flagStale(lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// GetList retrieves a saved crafting list, returning domain.ErrNotFound if it does not exist
+func (s *craftingListService) GetList(id int) (*domain.CraftingList, error) {
+	list, err := s.repo.GetCraftingListByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	lists := []domain.CraftingList{*list}
+	if err := s.flagStale(lists); err != nil {
+		return nil, err
+	}
+	return &lists[0], nil
+}
+
+// CreateList validates and saves a new crafting list for the current game version
+func (s *craftingListService) CreateList(list *domain.CraftingList) error {
+	if err := s.validate(list); err != nil {
+		return err
+	}
+	if err := s.setGameVersion(list); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateCraftingList(list); err != nil {
+		return fmt.Errorf("failed to create crafting list: %w", err)
+	}
+	return nil
+}
+
+// UpdateList validates and replaces the name and items of a crafting list.
+// Saving a list plans it for the current game version, which clears its stale flag.
+func (s *craftingListService) UpdateList(list *domain.CraftingList) error {
+	if err := s.validate(list); err != nil {
+		return err
+	}
+	if err := s.setGameVersion(list); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateCraftingList(list); err != nil {
+		return fmt.Errorf("failed to update crafting list %d: %w", list.ID, err)
+	}
+	return nil
+}
+
+// DeleteList deletes a crafting list
+func (s *craftingListService) DeleteList(id int) error {
+	if err := s.repo.DeleteCraftingList(id); err != nil {
+		return fmt.Errorf("failed to delete crafting list %d: %w", id, err)
+	}
+	return nil
+}
+
+// CalculateList calculates the resources of a saved list. The options of the
+// given request (intermediates, inventory, work speed) are applied to the
+// list items.
+func (s *craftingListService) CalculateList(id int, options domain.CraftingRequest) (*domain.CraftingResult, error) {
+	list, err := s.GetList(id)
+	if err != nil {
+		return nil, err
+	}
+
+	options.Items = list.Items
+	return s.crafting.CalculateResources(options)
+}
+
+func (s *craftingListService) setGameVersion(list *domain.CraftingList) error {
+	version, err := s.versions.GetCurrentVersion()
+	if err != nil {
+		return err
+	}
+	list.GameVersion = version
+	list.Stale = false
+	list.StaleRecipes = nil
+	return nil
+}
+
+// flagStale marks the lists planned for an older game version whose recipes
+// were removed or rebalanced since
+func (s *craftingListService) flagStale(lists []domain.CraftingList) error {
+	current, err := s.versions.GetCurrentVersion()
+	if err != nil {
+		return err
+	}
+
+	diffs := make(map[string]*domain.VersionDiff)
+	for i := range lists {
+		list := &lists[i]
+		if list.GameVersion == "" || list.GameVersion == current {
+			continue
+		}
+
+		diff, ok := diffs[list.GameVersion]
+		if !ok {
+			diff, err = s.versions.Diff(list.GameVersion, current)
+			// Lists planned for a version that was never recorded cannot be compared
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+			diffs[list.GameVersion] = diff
+		}
+		if diff == nil {
+			continue
+		}
+
+		changed := make(map[string]bool)
+		for _, recipe := range diff.Removed {
+			changed[recipe] = true
+		}
+		for _, change := range diff.Changed {
+			changed[change.Recipe] = true
+		}
+
+		for _, item := range list.Items {
+			if changed[item.Name] {
+				list.StaleRecipes = append(list.StaleRecipes, item.Name)
+			}
+		}
+		list.Stale = len(list.StaleRecipes) > 0
+	}
+
+	return nil
+}
+
+// validate checks the list has a name and only references existing recipes
+func (s *craftingListService) validate(list *domain.CraftingList) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return fmt.Errorf("%w: list name is required", domain.ErrInvalidInput)
+	}
+
+	for _, item := range list.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity of recipe %d must be positive", domain.ErrInvalidInput, item.ID)
+		}
+
+		recipe, err := s.recipeRepo.GetRecipeByID(item.ID)
+		if err != nil {
+			return err
+		}
+		if recipe == nil {
+			return fmt.Errorf("%w: recipe %d does not exist", domain.ErrInvalidInput, item.ID)
+		}
+	}
+
+	return nil
+}
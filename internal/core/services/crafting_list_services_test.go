@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// fakeCraftingListRepo keeps saved lists in memory
+type fakeCraftingListRepo struct {
+	lists  []domain.CraftingList
+	nextID int
+}
+
+func (r *fakeCraftingListRepo) GetAllCraftingLists() ([]domain.CraftingList, error) {
+	return append([]domain.CraftingList(nil), r.lists...), nil
+}
+
+func (r *fakeCraftingListRepo) GetCraftingListByID(id int) (*domain.CraftingList, error) {
+	for _, list := range r.lists {
+		if list.ID == id {
+			return &list, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeCraftingListRepo) CreateCraftingList(list *domain.CraftingList) error {
+	r.nextID++
+	list.ID = r.nextID
+	r.lists = append(r.lists, *list)
+	return nil
+}
+
+func (r *fakeCraftingListRepo) UpdateCraftingList(list *domain.CraftingList) error {
+	for i := range r.lists {
+		if r.lists[i].ID == list.ID {
+			r.lists[i] = *list
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *fakeCraftingListRepo) DeleteCraftingList(id int) error {
+	for i := range r.lists {
+		if r.lists[i].ID == id {
+			r.lists = append(r.lists[:i], r.lists[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// newCraftingListService saves lists of the recipes of newChestRepo for game version 0.6.0
+func newCraftingListService(repo *fakeCraftingListRepo) ports.CraftingListService {
+	recipes := newChestRepo()
+	return NewCraftingListService(repo, recipes, NewCraftingService(recipes, noTechnologies{}), NewGameVersionService(newGameVersionRepo()))
+}
+
+func TestCreateList(t *testing.T) {
+	repo := &fakeCraftingListRepo{}
+	service := newCraftingListService(repo)
+
+	list := &domain.CraftingList{Name: "  Base  ", Items: []domain.CraftingItem{{ID: 12, Quantity: 2}}}
+	if err := service.CreateList(list); err != nil {
+		t.Fatalf("CreateList() error = %v", err)
+	}
+	if list.ID == 0 || list.Name != "Base" || list.GameVersion != "0.6.0" {
+		t.Errorf("created list = %+v, want an ID, the trimmed name and game version 0.6.0", list)
+	}
+
+	saved, err := service.GetList(list.ID)
+	if err != nil {
+		t.Fatalf("GetList() error = %v", err)
+	}
+	if saved.Name != "Base" || !reflect.DeepEqual(saved.Items, list.Items) {
+		t.Errorf("saved list = %+v, want %+v", saved, list)
+	}
+
+	tests := []struct {
+		name string
+		list domain.CraftingList
+	}{
+		{"blank name", domain.CraftingList{Name: " ", Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}}},
+		{"zero quantity", domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: 12, Quantity: 0}}}},
+		{"negative quantity", domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: 12, Quantity: -1}}}},
+		{"unknown recipe", domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: 99, Quantity: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.CreateList(&tt.list); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("CreateList() error = %v, want domain.ErrInvalidInput", err)
+			}
+		})
+	}
+	if len(repo.lists) != 1 {
+		t.Errorf("%d lists saved, want only the valid one", len(repo.lists))
+	}
+}
+
+func TestUpdateList(t *testing.T) {
+	repo := &fakeCraftingListRepo{}
+	service := newCraftingListService(repo)
+
+	// A list planned for an older version using a rebalanced recipe
+	repo.lists = []domain.CraftingList{{ID: 1, Name: "Base", GameVersion: "0.5.0", Items: []domain.CraftingItem{{ID: 12, Name: "Chest", Quantity: 1}}}}
+	repo.nextID = 1
+	if list, err := service.GetList(1); err != nil || !list.Stale {
+		t.Fatalf("GetList() = %+v, %v, want a stale list", list, err)
+	}
+
+	renamed := &domain.CraftingList{ID: 1, Name: "Outpost", Items: []domain.CraftingItem{{ID: 11, Quantity: 4}}}
+	if err := service.UpdateList(renamed); err != nil {
+		t.Fatalf("UpdateList() error = %v", err)
+	}
+	list, err := service.GetList(1)
+	if err != nil {
+		t.Fatalf("GetList() error = %v", err)
+	}
+	if list.Name != "Outpost" || list.GameVersion != "0.6.0" || list.Stale || len(list.Items) != 1 || list.Items[0].ID != 11 {
+		t.Errorf("updated list = %+v, want Outpost with 4 Nails planned for 0.6.0", list)
+	}
+
+	if err := service.UpdateList(&domain.CraftingList{ID: 1, Name: ""}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("UpdateList() without a name error = %v, want domain.ErrInvalidInput", err)
+	}
+	if err := service.UpdateList(&domain.CraftingList{ID: 7, Name: "Missing"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UpdateList() of an unknown list error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestDeleteList(t *testing.T) {
+	repo := &fakeCraftingListRepo{}
+	service := newCraftingListService(repo)
+
+	list := &domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}}
+	if err := service.CreateList(list); err != nil {
+		t.Fatalf("CreateList() error = %v", err)
+	}
+	if err := service.DeleteList(list.ID); err != nil {
+		t.Fatalf("DeleteList() error = %v", err)
+	}
+	if _, err := service.GetList(list.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetList() of a deleted list error = %v, want domain.ErrNotFound", err)
+	}
+	if err := service.DeleteList(list.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteList() of a deleted list error = %v, want domain.ErrNotFound", err)
+	}
+
+	lists, err := service.GetAllLists()
+	if err != nil {
+		t.Fatalf("GetAllLists() error = %v", err)
+	}
+	if lists == nil || len(lists) != 0 {
+		t.Errorf("GetAllLists() = %#v, want an empty slice", lists)
+	}
+}
+
+func TestCalculateList(t *testing.T) {
+	repo := &fakeCraftingListRepo{}
+	service := newCraftingListService(repo)
+
+	list := &domain.CraftingList{Name: "Base", Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}}
+	if err := service.CreateList(list); err != nil {
+		t.Fatalf("CreateList() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options domain.CraftingRequest
+		want    []domain.ResourceTotal
+	}{
+		{
+			name: "expanded",
+			// Items of the options are replaced by the list items
+			options: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 10, Quantity: 50}}},
+			want: []domain.ResourceTotal{
+				{Name: "Ore", Total: 4, Missing: 4},
+				{Name: "Wood", Total: 5, Missing: 5},
+			},
+		},
+		{
+			name:    "stopped at intermediates",
+			options: domain.CraftingRequest{StopAtIntermediates: true},
+			want: []domain.ResourceTotal{
+				{Name: "Nail", Total: 3, Missing: 3, Craftable: true},
+				{Name: "Wood", Total: 5, Missing: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.CalculateList(list.ID, tt.options)
+			if err != nil {
+				t.Fatalf("CalculateList() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Resources, tt.want) {
+				t.Errorf("resources = %+v, want %+v", result.Resources, tt.want)
+			}
+		})
+	}
+
+	if _, err := service.CalculateList(99, domain.CraftingRequest{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("CalculateList() of an unknown list error = %v, want domain.ErrNotFound", err)
+	}
+}
@@ -1,49 +1,147 @@
 package services
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	"palworld-helper/internal/core/domain"
 	"palworld-helper/internal/core/ports"
 )
 
 type craftingService struct {
-	repo ports.CraftingRepository
+	repo     ports.CraftingRepository
+	techRepo ports.TechnologyRepository
 }
 
 // NewCraftingService creates a new crafting service
-func NewCraftingService(repo ports.CraftingRepository) ports.CraftingService {
+func NewCraftingService(repo ports.CraftingRepository, techRepo ports.TechnologyRepository) ports.CraftingService {
 	return &craftingService{
-		repo: repo,
+		repo:     repo,
+		techRepo: techRepo,
 	}
 }
 
-// GetAllRecipes retrieves all crafting recipes
-func (s *craftingService) GetAllRecipes() ([]domain.RecipeWithResources, error) {
-	return s.repo.GetAllRecipes()
+// GetAllRecipes retrieves all crafting recipes, flagging or hiding the ones
+// whose technology is not unlocked yet when asked to
+func (s *craftingService) GetAllRecipes(query domain.RecipeQuery) ([]domain.RecipeWithResources, error) {
+	recipes, err := s.repo.GetAllRecipes()
+	if err != nil {
+		return nil, err
+	}
+
+	if !query.FlagLocked && !query.HideLocked {
+		return recipes, nil
+	}
+
+	technologies, err := loadRecipeTechnologies(s.techRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := recipes[:0]
+	for _, recipe := range recipes {
+		recipe.Locked = technologies.locked(recipe.ID)
+		if recipe.Locked && query.HideLocked {
+			continue
+		}
+		filtered = append(filtered, recipe)
+	}
+
+	return filtered, nil
 }
 
-// CalculateResources calculates the total resources needed for crafting items
-func (s *craftingService) CalculateResources(request domain.CraftingRequest) ([]domain.ResourceTotal, error) {
-	resourceTotals := make(map[string]int)
+// CalculateResources calculates the total resources needed for crafting items.
+// Resources produced by another recipe are expanded down to raw resources
+// unless the request asks to stop at intermediates. When the request uses the
+// inventory, held intermediates are consumed before being expanded and held
+// raw resources are reported as owned. Recipes producing several items per
+// craft are rounded up to whole batches, and the total work is turned into
+// a crafting time using the player and pal work speed.
+func (s *craftingService) CalculateResources(request domain.CraftingRequest) (*domain.CraftingResult, error) {
+	workSpeed, err := totalWorkSpeed(request.WorkSpeed)
+	if err != nil {
+		return nil, err
+	}
 
+	plan := newCraftingPlan(s.repo, !request.StopAtIntermediates)
+
+	demand := make(map[int]int)
 	for _, item := range request.Items {
-		recipe, err := s.repo.GetRecipeByID(item.ID)
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of recipe %d must be positive", domain.ErrInvalidInput, item.ID)
+		}
+
+		recipe, err := plan.visit(item.ID, nil)
 		if err != nil {
 			return nil, err
 		}
 
 		if recipe != nil {
-			for _, resource := range recipe.Resources {
-				resourceTotals[resource.Name] += resource.Quantity * item.Quantity
+			demand[item.ID] += item.Quantity
+		}
+	}
+
+	owned := make(map[int]int)
+	if request.UseInventory {
+		inventory, err := s.repo.GetInventory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load inventory: %w", err)
+		}
+		for _, item := range inventory {
+			owned[item.ResourceID] = item.Quantity
+		}
+	}
+
+	// Walk recipes parents first so every intermediate has its full demand
+	// before being split into its own ingredients
+	resourceTotals := make(map[int]*domain.ResourceTotal)
+	expanded := make(map[int]bool)
+	crafts := []domain.CraftingBatch{}
+	var crafted []int
+	for i := len(plan.order) - 1; i >= 0; i-- {
+		recipe := plan.recipes[plan.order[i]]
+		quantity := demand[recipe.ID]
+		if quantity == 0 {
+			continue
+		}
+
+		batch := newCraftingBatch(recipe, quantity)
+		crafts = append(crafts, batch)
+		crafted = append(crafted, recipe.ID)
+
+		for _, resource := range recipe.Resources {
+			needed := resource.Quantity * batch.Batches
+
+			total, ok := resourceTotals[resource.ID]
+			if !ok {
+				total = &domain.ResourceTotal{Name: resource.Name, Craftable: resource.RecipeID != nil}
+				resourceTotals[resource.ID] = total
+			}
+			total.Total += needed
+
+			if plan.expand && resource.RecipeID != nil && plan.recipes[*resource.RecipeID] != nil {
+				used := min(owned[resource.ID], needed)
+				owned[resource.ID] -= used
+				total.Owned += used
+				demand[*resource.RecipeID] += needed - used
+				expanded[resource.ID] = true
 			}
 		}
 	}
 
 	// Convert map to slice for consistent output
-	var results []domain.ResourceTotal
-	for name, total := range resourceTotals {
-		results = append(results, domain.ResourceTotal{Name: name, Total: total})
+	results := []domain.ResourceTotal{}
+	for resourceID, total := range resourceTotals {
+		if expanded[resourceID] && total.Owned == 0 {
+			// Fully expanded into its ingredients
+			continue
+		}
+		if !expanded[resourceID] {
+			total.Owned = min(owned[resourceID], total.Total)
+		}
+		total.Missing = total.Total - total.Owned
+		results = append(results, *total)
 	}
 
 	// Sort by resource name for consistent output
@@ -51,6 +149,131 @@ func (s *craftingService) CalculateResources(request domain.CraftingRequest) ([]
 		return results[i].Name < results[j].Name
 	})
 
+	stations, err := s.requiredStations(plan, crafted)
+	if err != nil {
+		return nil, err
+	}
+
+	technologies, err := loadRecipeTechnologies(s.techRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CraftingResult{
+		Resources:  results,
+		Crafts:     crafts,
+		Workload:   estimateWorkload(crafts, workSpeed),
+		Stations:   stations,
+		Technology: technologies.requirement(crafted),
+	}, nil
+}
+
+// newCraftingBatch rounds the wanted quantity of a recipe up to whole crafts
+func newCraftingBatch(recipe *domain.RecipeWithResources, quantity int) domain.CraftingBatch {
+	yield := max(recipe.Yield, 1)
+	batches := (quantity + yield - 1) / yield
+
+	return domain.CraftingBatch{
+		RecipeID:       recipe.ID,
+		Name:           recipe.Name,
+		Quantity:       quantity,
+		Yield:          yield,
+		Batches:        batches,
+		Produced:       batches * yield,
+		Overproduction: batches*yield - quantity,
+		Work:           batches * recipe.WorkAmount,
+	}
+}
+
+const (
+	// defaultPlayerWorkSpeed is the work speed of a player without bonuses
+	defaultPlayerWorkSpeed = 100
+	// handiworkSpeedPerLevel is the work speed a pal adds per Handiwork level
+	handiworkSpeedPerLevel = 50
+	// maxHandiworkLevel is the highest Handiwork suitability a pal can have
+	maxHandiworkLevel = 5
+)
+
+// totalWorkSpeed adds up the work speed of the player and the assigned pals.
+// Without any input the player crafts alone at the default speed.
+func totalWorkSpeed(speed *domain.WorkSpeed) (float64, error) {
+	if speed == nil {
+		return defaultPlayerWorkSpeed, nil
+	}
+
+	if speed.PlayerSpeed < 0 {
+		return 0, fmt.Errorf("%w: player speed cannot be negative", domain.ErrInvalidInput)
+	}
+
+	total := speed.PlayerSpeed
+	for _, pal := range speed.Pals {
+		if pal.HandiworkLevel < 1 || pal.HandiworkLevel > maxHandiworkLevel {
+			return 0, fmt.Errorf("%w: Handiwork level of %s must be between 1 and %d",
+				domain.ErrInvalidInput, pal.Name, maxHandiworkLevel)
+		}
+		total += float64(pal.HandiworkLevel * handiworkSpeedPerLevel)
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("%w: nobody is assigned to crafting", domain.ErrInvalidInput)
+	}
+	return total, nil
+}
+
+// estimateWorkload sums the work of every craft and converts it to a duration,
+// work speed being the amount of work done per second
+func estimateWorkload(crafts []domain.CraftingBatch, workSpeed float64) domain.Workload {
+	workload := domain.Workload{WorkSpeed: workSpeed}
+	for _, craft := range crafts {
+		workload.TotalWork += craft.Work
+	}
+	workload.Seconds = float64(workload.TotalWork) / workSpeed
+	return workload
+}
+
+// requiredStations lists the crafting stations used by the crafted recipes
+// along with whether the player already built them
+func (s *craftingService) requiredStations(plan *craftingPlan, crafted []int) ([]domain.StationRequirement, error) {
+	stations, err := s.repo.GetAllStations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load crafting stations: %w", err)
+	}
+
+	byID := make(map[int]domain.CraftingStation)
+	for _, station := range stations {
+		byID[station.ID] = station
+	}
+
+	required := make(map[int]*domain.StationRequirement)
+	for _, recipeID := range crafted {
+		recipe := plan.recipes[recipeID]
+		if recipe.StationID == nil {
+			continue
+		}
+
+		station, ok := byID[*recipe.StationID]
+		if !ok {
+			continue
+		}
+
+		requirement, ok := required[station.ID]
+		if !ok {
+			requirement = &domain.StationRequirement{CraftingStation: station}
+			required[station.ID] = requirement
+		}
+		requirement.Recipes = append(requirement.Recipes, recipe.Name)
+	}
+
+	results := []domain.StationRequirement{}
+	for _, requirement := range required {
+		sort.Strings(requirement.Recipes)
+		results = append(results, *requirement)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
 	return results, nil
 }
 
@@ -74,3 +297,109 @@ func (s *craftingService) GetCategories() ([]string, error) {
 	sort.Strings(categories)
 	return categories, nil
 }
+
+// GetInventory retrieves the resources held by the player
+func (s *craftingService) GetInventory() ([]domain.InventoryItem, error) {
+	return s.repo.GetInventory()
+}
+
+// UpdateInventory sets the held quantity of each given resource. The items
+// are checked first and written together, so that a rejected item leaves the
+// inventory unchanged.
+func (s *craftingService) UpdateInventory(items []domain.InventoryItem) error {
+	resources, err := s.repo.GetAllResources()
+	if err != nil {
+		return err
+	}
+	known := make(map[int]bool, len(resources))
+	for _, resource := range resources {
+		known[resource.ID] = true
+	}
+
+	for _, item := range items {
+		if item.Quantity < 0 {
+			return fmt.Errorf("%w: invalid quantity %d for resource %d", domain.ErrInvalidInput, item.Quantity, item.ResourceID)
+		}
+		if !known[item.ResourceID] {
+			return fmt.Errorf("%w: resource %d does not exist", domain.ErrInvalidInput, item.ResourceID)
+		}
+	}
+
+	return s.repo.UpdateInventory(items)
+}
+
+// DeleteInventoryItem removes a resource from the inventory
+func (s *craftingService) DeleteInventoryItem(resourceID int) error {
+	return s.repo.DeleteInventoryItem(resourceID)
+}
+
+// GetStations retrieves all crafting stations
+func (s *craftingService) GetStations() ([]domain.CraftingStation, error) {
+	return s.repo.GetAllStations()
+}
+
+// SetStationBuilt records whether the player has built a crafting station
+func (s *craftingService) SetStationBuilt(id int, built bool) error {
+	if err := s.repo.SetStationBuilt(id, built); err != nil {
+		return fmt.Errorf("failed to update station %d: %w", id, err)
+	}
+	return nil
+}
+
+// craftingPlan loads the recipe graph reachable from a crafting request and
+// orders it so intermediates can be expanded after everything that needs them
+type craftingPlan struct {
+	repo     ports.CraftingRepository
+	expand   bool
+	recipes  map[int]*domain.RecipeWithResources
+	visiting map[int]bool
+	order    []int
+}
+
+func newCraftingPlan(repo ports.CraftingRepository, expand bool) *craftingPlan {
+	return &craftingPlan{
+		repo:     repo,
+		expand:   expand,
+		recipes:  make(map[int]*domain.RecipeWithResources),
+		visiting: make(map[int]bool),
+	}
+}
+
+// visit loads a recipe and its sub-recipes depth first, appending them to the
+// plan order once all their ingredients are known. path holds the recipe names
+// currently being expanded and is used to report cycles.
+func (p *craftingPlan) visit(recipeID int, path []string) (*domain.RecipeWithResources, error) {
+	if recipe, ok := p.recipes[recipeID]; ok {
+		if p.visiting[recipeID] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrCraftingCycle, strings.Join(append(path, recipe.Name), " -> "))
+		}
+		return recipe, nil
+	}
+
+	recipe, err := p.repo.GetRecipeByID(recipeID)
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil {
+		return nil, nil
+	}
+
+	p.recipes[recipeID] = recipe
+	p.visiting[recipeID] = true
+	path = append(path, recipe.Name)
+
+	if p.expand {
+		for _, resource := range recipe.Resources {
+			if resource.RecipeID == nil {
+				continue
+			}
+			if _, err := p.visit(*resource.RecipeID, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	p.visiting[recipeID] = false
+	p.order = append(p.order, recipeID)
+	return recipe, nil
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// fakeCraftingRepo serves recipes and the inventory from memory. Its other
+// methods are not used by the calculator.
+type fakeCraftingRepo struct {
+	ports.CraftingRepository
+	recipes   map[int]*domain.RecipeWithResources
+	inventory []domain.InventoryItem
+	stations  []domain.CraftingStation
+}
+
+func (r *fakeCraftingRepo) GetRecipeByID(id int) (*domain.RecipeWithResources, error) {
+	return r.recipes[id], nil
+}
+
+func (r *fakeCraftingRepo) GetAllRecipes() ([]domain.RecipeWithResources, error) {
+	recipes := make([]domain.RecipeWithResources, 0, len(r.recipes))
+	for _, recipe := range r.recipes {
+		recipes = append(recipes, *recipe)
+	}
+	sort.Slice(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+	return recipes, nil
+}
+
+func (r *fakeCraftingRepo) GetInventory() ([]domain.InventoryItem, error) {
+	return r.inventory, nil
+}
+
+func (r *fakeCraftingRepo) GetAllStations() ([]domain.CraftingStation, error) {
+	return r.stations, nil
+}
+
+// noTechnologies is a ports.TechnologyRepository without any technology
+type noTechnologies struct {
+	ports.TechnologyRepository
+}
+
+func (noTechnologies) GetAllTechnologies() ([]domain.Technology, error) {
+	return nil, nil
+}
+
+func TestCalculateResourcesQuantity(t *testing.T) {
+	repo := &fakeCraftingRepo{recipes: map[int]*domain.RecipeWithResources{
+		1: {
+			CraftingRecipe: domain.CraftingRecipe{ID: 1, Name: "Campfire", Yield: 1, WorkAmount: 10},
+			Resources:      []domain.ResourceWithQuantity{{ID: 1, Name: "Wood", Quantity: 10}},
+		},
+	}}
+	service := NewCraftingService(repo, noTechnologies{})
+
+	for _, quantity := range []int{0, -1} {
+		_, err := service.CalculateResources(domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 1, Quantity: quantity}}})
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("CalculateResources() of quantity %d error = %v, want domain.ErrInvalidInput", quantity, err)
+		}
+	}
+
+	result, err := service.CalculateResources(domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 1, Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("CalculateResources() error = %v", err)
+	}
+	if len(result.Resources) != 1 || result.Resources[0].Total != 20 {
+		t.Errorf("resources = %+v, want 20 Wood", result.Resources)
+	}
+}
+
+func recipeID(id int) *int {
+	return &id
+}
+
+// newChestRepo holds a Chest made of Wood and Nails, Nails being crafted two
+// at a time from an Ingot, itself smelted from Ore
+func newChestRepo(inventory ...domain.InventoryItem) *fakeCraftingRepo {
+	return &fakeCraftingRepo{
+		inventory: inventory,
+		recipes: map[int]*domain.RecipeWithResources{
+			10: {
+				CraftingRecipe: domain.CraftingRecipe{ID: 10, Name: "Ingot", Yield: 1, WorkAmount: 5},
+				Resources:      []domain.ResourceWithQuantity{{ID: 4, Name: "Ore", Quantity: 2}},
+			},
+			11: {
+				CraftingRecipe: domain.CraftingRecipe{ID: 11, Name: "Nail", Yield: 2, WorkAmount: 4},
+				Resources:      []domain.ResourceWithQuantity{{ID: 3, Name: "Ingot", Quantity: 1, RecipeID: recipeID(10)}},
+			},
+			12: {
+				CraftingRecipe: domain.CraftingRecipe{ID: 12, Name: "Chest", Yield: 1, WorkAmount: 20},
+				Resources: []domain.ResourceWithQuantity{
+					{ID: 1, Name: "Wood", Quantity: 5},
+					{ID: 5, Name: "Nail", Quantity: 3, RecipeID: recipeID(11)},
+				},
+			},
+		},
+	}
+}
+
+func TestCalculateResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		repo      *fakeCraftingRepo
+		request   domain.CraftingRequest
+		resources []domain.ResourceTotal
+		// batches maps the crafted recipes to their number of crafts
+		batches map[string]int
+	}{
+		{
+			name:    "two levels",
+			repo:    newChestRepo(),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}},
+			resources: []domain.ResourceTotal{
+				{Name: "Ore", Total: 4, Missing: 4},
+				{Name: "Wood", Total: 5, Missing: 5},
+			},
+			batches: map[string]int{"Chest": 1, "Nail": 2, "Ingot": 2},
+		},
+		{
+			name:    "odd demand of a yield of 2",
+			repo:    newChestRepo(),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 11, Quantity: 3}}},
+			resources: []domain.ResourceTotal{
+				{Name: "Ore", Total: 4, Missing: 4},
+			},
+			batches: map[string]int{"Nail": 2, "Ingot": 2},
+		},
+		{
+			name:    "stop at intermediates",
+			repo:    newChestRepo(),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 2}}, StopAtIntermediates: true},
+			resources: []domain.ResourceTotal{
+				{Name: "Nail", Total: 6, Missing: 6, Craftable: true},
+				{Name: "Wood", Total: 10, Missing: 10},
+			},
+			batches: map[string]int{"Chest": 2},
+		},
+		{
+			name: "owned intermediates cut their subtree",
+			repo: newChestRepo(
+				domain.InventoryItem{ResourceID: 3, Quantity: 2},
+				domain.InventoryItem{ResourceID: 1, Quantity: 3},
+			),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}, UseInventory: true},
+			resources: []domain.ResourceTotal{
+				{Name: "Ingot", Total: 2, Owned: 2, Craftable: true},
+				{Name: "Wood", Total: 5, Owned: 3, Missing: 2},
+			},
+			batches: map[string]int{"Chest": 1, "Nail": 2},
+		},
+		{
+			name:    "partly owned intermediate",
+			repo:    newChestRepo(domain.InventoryItem{ResourceID: 3, Quantity: 1}),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}, UseInventory: true},
+			resources: []domain.ResourceTotal{
+				{Name: "Ingot", Total: 2, Owned: 1, Missing: 1, Craftable: true},
+				{Name: "Ore", Total: 2, Missing: 2},
+				{Name: "Wood", Total: 5, Missing: 5},
+			},
+			batches: map[string]int{"Chest": 1, "Nail": 2, "Ingot": 1},
+		},
+		{
+			name:    "inventory ignored",
+			repo:    newChestRepo(domain.InventoryItem{ResourceID: 3, Quantity: 2}),
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 10, Quantity: 1}}},
+			resources: []domain.ResourceTotal{
+				{Name: "Ore", Total: 2, Missing: 2},
+			},
+			batches: map[string]int{"Ingot": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCraftingService(tt.repo, noTechnologies{}).CalculateResources(tt.request)
+			if err != nil {
+				t.Fatalf("CalculateResources() error = %v", err)
+			}
+
+			if len(result.Resources) != len(tt.resources) {
+				t.Fatalf("resources = %+v, want %+v", result.Resources, tt.resources)
+			}
+			for i, want := range tt.resources {
+				if result.Resources[i] != want {
+					t.Errorf("resource %d = %+v, want %+v", i, result.Resources[i], want)
+				}
+			}
+
+			batches := make(map[string]int)
+			for _, craft := range result.Crafts {
+				batches[craft.Name] = craft.Batches
+				if craft.Produced != craft.Batches*craft.Yield || craft.Overproduction != craft.Produced-craft.Quantity {
+					t.Errorf("craft %+v does not add up", craft)
+				}
+			}
+			if len(batches) != len(tt.batches) {
+				t.Errorf("crafts = %v, want %v", batches, tt.batches)
+			}
+			for name, want := range tt.batches {
+				if batches[name] != want {
+					t.Errorf("%s crafted %d times, want %d", name, batches[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateResourcesCycle(t *testing.T) {
+	repo := &fakeCraftingRepo{recipes: map[int]*domain.RecipeWithResources{
+		20: {
+			CraftingRecipe: domain.CraftingRecipe{ID: 20, Name: "Egg", Yield: 1},
+			Resources:      []domain.ResourceWithQuantity{{ID: 7, Name: "Chicken", Quantity: 1, RecipeID: recipeID(21)}},
+		},
+		21: {
+			CraftingRecipe: domain.CraftingRecipe{ID: 21, Name: "Chicken", Yield: 1},
+			Resources:      []domain.ResourceWithQuantity{{ID: 6, Name: "Egg", Quantity: 1, RecipeID: recipeID(20)}},
+		},
+	}}
+	service := NewCraftingService(repo, noTechnologies{})
+
+	_, err := service.CalculateResources(domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 20, Quantity: 1}}})
+	if !errors.Is(err, domain.ErrCraftingCycle) {
+		t.Fatalf("CalculateResources() error = %v, want domain.ErrCraftingCycle", err)
+	}
+
+	// Without expanding, the cycle is never walked
+	request := domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 20, Quantity: 1}}, StopAtIntermediates: true}
+	if _, err := service.CalculateResources(request); err != nil {
+		t.Errorf("CalculateResources() stopping at intermediates error = %v", err)
+	}
+}
+
+func TestCalculateResourcesWorkload(t *testing.T) {
+	// A Chest is 20 work, plus 2 Nail crafts of 4 and 2 Ingot crafts of 5
+	const totalWork = 38
+
+	tests := []struct {
+		name      string
+		workSpeed *domain.WorkSpeed
+		speed     float64
+		wantErr   bool
+	}{
+		{"player alone by default", nil, 100, false},
+		{"player and pal", &domain.WorkSpeed{PlayerSpeed: 100, Pals: []domain.AssignedPal{{Name: "Lamball", HandiworkLevel: 1}}}, 150, false},
+		{"pals only", &domain.WorkSpeed{Pals: []domain.AssignedPal{{Name: "Anubis", HandiworkLevel: 4}, {Name: "Lamball", HandiworkLevel: 1}}}, 250, false},
+		{"highest Handiwork", &domain.WorkSpeed{Pals: []domain.AssignedPal{{Name: "Anubis", HandiworkLevel: 5}}}, 250, false},
+		{"Handiwork too high", &domain.WorkSpeed{Pals: []domain.AssignedPal{{Name: "Anubis", HandiworkLevel: 6}}}, 0, true},
+		{"Handiwork too low", &domain.WorkSpeed{PlayerSpeed: 100, Pals: []domain.AssignedPal{{Name: "Lamball", HandiworkLevel: 0}}}, 0, true},
+		{"negative player speed", &domain.WorkSpeed{PlayerSpeed: -1}, 0, true},
+		{"nobody crafting", &domain.WorkSpeed{}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}, WorkSpeed: tt.workSpeed}
+			result, err := NewCraftingService(newChestRepo(), noTechnologies{}).CalculateResources(request)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("CalculateResources() error = %v, want domain.ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CalculateResources() error = %v", err)
+			}
+
+			want := domain.Workload{TotalWork: totalWork, WorkSpeed: tt.speed, Seconds: totalWork / tt.speed}
+			if result.Workload != want {
+				t.Errorf("workload = %+v, want %+v", result.Workload, want)
+			}
+		})
+	}
+}
+
+func TestCalculateResourcesStations(t *testing.T) {
+	repo := newChestRepo()
+	repo.stations = []domain.CraftingStation{
+		{ID: 1, Name: "Workbench", Built: true},
+		{ID: 2, Name: "Primitive Furnace"},
+	}
+	repo.recipes[10].StationID = recipeID(2)
+	repo.recipes[11].StationID = recipeID(1)
+	repo.recipes[12].StationID = recipeID(1)
+	service := NewCraftingService(repo, noTechnologies{})
+
+	tests := []struct {
+		name    string
+		request domain.CraftingRequest
+		want    []domain.StationRequirement
+	}{
+		{
+			name:    "expanded",
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}},
+			want: []domain.StationRequirement{
+				{CraftingStation: repo.stations[1], Recipes: []string{"Ingot"}},
+				{CraftingStation: repo.stations[0], Recipes: []string{"Chest", "Nail"}},
+			},
+		},
+		{
+			name:    "stopped at intermediates",
+			request: domain.CraftingRequest{Items: []domain.CraftingItem{{ID: 12, Quantity: 1}}, StopAtIntermediates: true},
+			want: []domain.StationRequirement{
+				{CraftingStation: repo.stations[0], Recipes: []string{"Chest"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.CalculateResources(tt.request)
+			if err != nil {
+				t.Fatalf("CalculateResources() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Stations, tt.want) {
+				t.Errorf("stations = %+v, want %+v", result.Stations, tt.want)
+			}
+		})
+	}
+}
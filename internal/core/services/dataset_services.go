@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type datasetService struct {
+	source ports.DatasetSource
+	repo   ports.DatasetRepository
+}
+
+// NewDatasetService creates a new dataset service
+func NewDatasetService(source ports.DatasetSource, repo ports.DatasetRepository) ports.DatasetService {
+	return &datasetService{
+		source: source,
+		repo:   repo,
+	}
+}
+
+// GetStatus compares the bundled dataset version with the loaded one
+func (s *datasetService) GetStatus() (*domain.DatasetStatus, error) {
+	dataset, err := s.source.LoadDataset()
+	if err != nil {
+		return nil, err
+	}
+	return s.status(domain.DatasetSourceBundled, dataset.Version)
+}
+
+// Sync loads the bundled dataset unless the same version is already loaded
+func (s *datasetService) Sync() (*domain.DatasetStatus, error) {
+	status, err := s.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status.UpToDate {
+		return status, nil
+	}
+	return s.Load()
+}
+
+// Load validates the bundled dataset and upserts it into the database
+func (s *datasetService) Load() (*domain.DatasetStatus, error) {
+	dataset, err := s.source.LoadDataset()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateDataset(dataset, true); err != nil {
+		return nil, err
+	}
+
+	dataset.Source = domain.DatasetSourceBundled
+	if err := s.repo.UpsertDataset(dataset); err != nil {
+		return nil, fmt.Errorf("failed to load dataset %s: %w", dataset.Version, err)
+	}
+
+	return s.status(dataset.Source, dataset.Version)
+}
+
+// Import upserts a partial dataset, such as one converted from game files.
+// Its records may refer to recipes and stations already in the database.
+func (s *datasetService) Import(dataset *domain.Dataset) (*domain.DatasetStatus, error) {
+	if err := validateDataset(dataset, false); err != nil {
+		return nil, err
+	}
+
+	if dataset.Source == "" {
+		dataset.Source = domain.DatasetSourceDataTable
+	}
+	if err := s.repo.UpsertDataset(dataset); err != nil {
+		return nil, fmt.Errorf("failed to import dataset %s: %w", dataset.Version, err)
+	}
+
+	return s.status(dataset.Source, dataset.Version)
+}
+
+func (s *datasetService) status(source, version string) (*domain.DatasetStatus, error) {
+	loadedVersion, loadedAt, err := s.repo.GetDatasetVersion(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.DatasetStatus{
+		Source:        source,
+		Version:       version,
+		LoadedVersion: loadedVersion,
+		LoadedAt:      loadedAt,
+		UpToDate:      loadedVersion == version,
+	}, nil
+}
+
+// validateDataset checks that names are unique and, for a complete dataset,
+// that every reference between records points at a record of the dataset
+func validateDataset(dataset *domain.Dataset, complete bool) error {
+	if dataset.Version == "" {
+		return fmt.Errorf("%w: dataset version is required", domain.ErrInvalidInput)
+	}
+
+	stations := make(map[string]bool)
+	for _, station := range dataset.Stations {
+		if station.Name == "" || stations[station.Name] {
+			return fmt.Errorf("%w: station name %q is empty or duplicated", domain.ErrInvalidInput, station.Name)
+		}
+		stations[station.Name] = true
+	}
+
+	recipes := make(map[string]bool)
+	for _, recipe := range dataset.Recipes {
+		if recipe.Name == "" || recipes[recipe.Name] {
+			return fmt.Errorf("%w: recipe name %q is empty or duplicated", domain.ErrInvalidInput, recipe.Name)
+		}
+		recipes[recipe.Name] = true
+
+		if complete && recipe.Station != "" && !stations[recipe.Station] {
+			return fmt.Errorf("%w: recipe %s uses unknown station %s", domain.ErrInvalidInput, recipe.Name, recipe.Station)
+		}
+		if recipe.Yield < 0 || recipe.WorkAmount < 0 {
+			return fmt.Errorf("%w: recipe %s has a negative yield or work amount", domain.ErrInvalidInput, recipe.Name)
+		}
+		for resource, quantity := range recipe.Resources {
+			if quantity <= 0 {
+				return fmt.Errorf("%w: recipe %s needs a positive quantity of %s", domain.ErrInvalidInput, recipe.Name, resource)
+			}
+		}
+	}
+
+	for _, station := range dataset.Stations {
+		if complete && station.Recipe != "" && !recipes[station.Recipe] {
+			return fmt.Errorf("%w: station %s is built from unknown recipe %s", domain.ErrInvalidInput, station.Name, station.Recipe)
+		}
+	}
+
+	technologies := make(map[string]bool)
+	for _, technology := range dataset.Technologies {
+		if technology.Name == "" || technologies[technology.Name] {
+			return fmt.Errorf("%w: technology name %q is empty or duplicated", domain.ErrInvalidInput, technology.Name)
+		}
+		technologies[technology.Name] = true
+
+		for _, recipe := range technology.Recipes {
+			if complete && !recipes[recipe] {
+				return fmt.Errorf("%w: technology %s unlocks unknown recipe %s", domain.ErrInvalidInput, technology.Name, recipe)
+			}
+		}
+	}
+
+	pals := make(map[string]bool)
+	for _, pal := range dataset.Pals {
+		if pal.Name == "" || pals[pal.Name] {
+			return fmt.Errorf("%w: pal name %q is empty or duplicated", domain.ErrInvalidInput, pal.Name)
+		}
+		pals[pal.Name] = true
+	}
+
+	for _, combination := range dataset.Breeding {
+		for _, name := range []string{combination.Parent1, combination.Parent2, combination.Child} {
+			if name == "" || (complete && !pals[name]) {
+				return fmt.Errorf("%w: breeding combination %s + %s = %s uses unknown pal %q", domain.ErrInvalidInput,
+					combination.Parent1, combination.Parent2, combination.Child, name)
+			}
+		}
+	}
+
+	return nil
+}
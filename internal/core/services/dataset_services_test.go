@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func TestValidateDatasetBreeding(t *testing.T) {
+	pals := []domain.DatasetPal{{Name: "Relaxaurus"}, {Name: "Sparkit"}, {Name: "Relaxaurus Lux"}}
+
+	tests := []struct {
+		name        string
+		combination domain.DatasetBreeding
+		complete    bool
+		wantErr     bool
+	}{
+		{"known pals", domain.DatasetBreeding{Parent1: "Relaxaurus", Parent2: "Sparkit", Child: "Relaxaurus Lux"}, true, false},
+		{"unknown child", domain.DatasetBreeding{Parent1: "Relaxaurus", Parent2: "Sparkit", Child: "Anubis"}, true, true},
+		{"unknown parent in a partial dataset", domain.DatasetBreeding{Parent1: "Penking", Parent2: "Sparkit", Child: "Relaxaurus Lux"}, false, false},
+		{"missing parent", domain.DatasetBreeding{Parent1: "Relaxaurus", Child: "Relaxaurus Lux"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset := &domain.Dataset{Version: "1", Pals: pals, Breeding: []domain.DatasetBreeding{tt.combination}}
+			err := validateDataset(dataset, tt.complete)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("validateDataset() error = %v, want domain.ErrInvalidInput", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type exportService struct {
+	recipeRepo ports.CraftingRepository
+	crafting   ports.CraftingService
+	lists      ports.CraftingListService
+}
+
+// NewExportService creates a new crafting list import/export service
+func NewExportService(
+	recipeRepo ports.CraftingRepository,
+	crafting ports.CraftingService,
+	lists ports.CraftingListService,
+) ports.ExportService {
+	return &exportService{
+		recipeRepo: recipeRepo,
+		crafting:   crafting,
+		lists:      lists,
+	}
+}
+
+// Export calculates a crafting request and writes it with its resources in
+// the given format. Items whose recipe does not exist are listed as unknown.
+func (s *exportService) Export(w io.Writer, name string, request domain.CraftingRequest, format domain.ExportFormat) error {
+	if err := checkFormat(format, domain.FormatJSON, domain.FormatCSV, domain.FormatMarkdown); err != nil {
+		return err
+	}
+
+	result, err := s.crafting.CalculateResources(request)
+	if err != nil {
+		return err
+	}
+
+	export := domain.CraftingExport{
+		Name:      name,
+		Items:     make([]domain.CraftingItem, 0, len(request.Items)),
+		Resources: result.Resources,
+	}
+
+	// Name every item so the export can be imported back by recipe name
+	for _, item := range request.Items {
+		recipe, err := s.recipeRepo.GetRecipeByID(item.ID)
+		if err != nil {
+			return err
+		}
+		if recipe == nil {
+			export.Unknown = append(export.Unknown, item)
+			continue
+		}
+		item.Name = recipe.Name
+		export.Items = append(export.Items, item)
+	}
+
+	switch format {
+	case domain.FormatCSV:
+		return writeCSVExport(w, export)
+	case domain.FormatMarkdown:
+		return writeMarkdownExport(w, export)
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(export)
+	}
+}
+
+// ExportList exports a saved crafting list
+func (s *exportService) ExportList(w io.Writer, id int, format domain.ExportFormat) error {
+	list, err := s.lists.GetList(id)
+	if err != nil {
+		return err
+	}
+
+	return s.Export(w, list.Name, domain.CraftingRequest{Items: list.Items}, format)
+}
+
+// Import reads crafting items by recipe name from a JSON or CSV file. Names
+// that match no recipe or several recipes and unreadable lines are reported
+// in the result.
+func (s *exportService) Import(r io.Reader, format domain.ExportFormat) (*domain.ImportResult, error) {
+	if err := checkFormat(format, domain.FormatJSON, domain.FormatCSV); err != nil {
+		return nil, err
+	}
+
+	var rows []importRow
+	var err error
+	if format == domain.FormatCSV {
+		rows, err = readCSVImport(r)
+	} else {
+		rows, err = readJSONImport(r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	recipes, err := s.recipeRepo.GetAllRecipes()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]domain.RecipeWithResources)
+	for _, recipe := range recipes {
+		key := strings.ToLower(recipe.Name)
+		byName[key] = append(byName[key], recipe)
+	}
+
+	result := &domain.ImportResult{
+		Items:     []domain.CraftingItem{},
+		Unknown:   []string{},
+		Ambiguous: []string{},
+		Invalid:   []string{},
+	}
+	index := make(map[int]int)
+
+	for _, row := range rows {
+		if row.invalid != "" {
+			result.Invalid = append(result.Invalid, row.invalid)
+			continue
+		}
+
+		matches := byName[strings.ToLower(strings.TrimSpace(row.name))]
+		switch {
+		case len(matches) == 0:
+			result.Unknown = append(result.Unknown, row.name)
+			continue
+		case len(matches) > 1:
+			result.Ambiguous = append(result.Ambiguous, row.name)
+			continue
+		}
+		recipe := matches[0]
+
+		// Recipes listed several times are merged
+		if i, ok := index[recipe.ID]; ok {
+			result.Items[i].Quantity += row.quantity
+			continue
+		}
+		index[recipe.ID] = len(result.Items)
+		result.Items = append(result.Items, domain.CraftingItem{ID: recipe.ID, Name: recipe.Name, Quantity: row.quantity})
+	}
+
+	return result, nil
+}
+
+// importRow represents an item read from an imported file, invalid holding
+// the reason when the line could not be used
+type importRow struct {
+	name     string
+	quantity int
+	invalid  string
+}
+
+// readJSONImport accepts either an export document or a bare array of items
+func readJSONImport(r io.Reader) ([]importRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []domain.CraftingItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		var export domain.CraftingExport
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		items = export.Items
+	}
+
+	var rows []importRow
+	for i, item := range items {
+		row := importRow{name: item.Name, quantity: item.Quantity}
+		if strings.TrimSpace(item.Name) == "" {
+			row.invalid = fmt.Sprintf("item %d: missing name", i+1)
+		} else if item.Quantity <= 0 {
+			row.invalid = fmt.Sprintf("item %d (%s): quantity must be positive", i+1, item.Name)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readCSVImport reads a CSV file with a header containing name and quantity
+// columns. When a type column is present only "item" rows are read, so CSV
+// exports can be imported back.
+func readCSVImport(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty CSV file")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	nameCol, hasName := columns["name"]
+	quantityCol, hasQuantity := columns["quantity"]
+	if !hasName || !hasQuantity {
+		return nil, fmt.Errorf("CSV header must contain name and quantity columns")
+	}
+	typeCol, hasType := columns["type"]
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rows = append(rows, importRow{invalid: fmt.Sprintf("line %d: %v", line, err)})
+			continue
+		}
+
+		if hasType && typeCol < len(record) && record[typeCol] != "" && record[typeCol] != "item" {
+			continue
+		}
+
+		if nameCol >= len(record) || quantityCol >= len(record) {
+			rows = append(rows, importRow{invalid: fmt.Sprintf("line %d: missing columns", line)})
+			continue
+		}
+
+		name := strings.TrimSpace(record[nameCol])
+		quantity, err := strconv.Atoi(strings.TrimSpace(record[quantityCol]))
+		switch {
+		case name == "":
+			rows = append(rows, importRow{invalid: fmt.Sprintf("line %d: missing name", line)})
+		case err != nil || quantity <= 0:
+			rows = append(rows, importRow{invalid: fmt.Sprintf("line %d (%s): invalid quantity %q", line, name, record[quantityCol])})
+		default:
+			rows = append(rows, importRow{name: name, quantity: quantity})
+		}
+	}
+
+	return rows, nil
+}
+
+// writeCSVExport writes items and resources as typed rows of a single CSV table
+func writeCSVExport(w io.Writer, export domain.CraftingExport) error {
+	writer := csv.NewWriter(w)
+
+	records := [][]string{{"type", "name", "quantity", "owned", "missing"}}
+	for _, item := range export.Items {
+		records = append(records, []string{"item", item.Name, strconv.Itoa(item.Quantity), "", ""})
+	}
+	for _, item := range export.Unknown {
+		records = append(records, []string{"unknown", unknownItemName(item), strconv.Itoa(item.Quantity), "", ""})
+	}
+	for _, resource := range export.Resources {
+		records = append(records, []string{
+			"resource", resource.Name, strconv.Itoa(resource.Total),
+			strconv.Itoa(resource.Owned), strconv.Itoa(resource.Missing),
+		})
+	}
+
+	return writer.WriteAll(records)
+}
+
+// writeMarkdownExport writes the list as a Markdown checklist
+func writeMarkdownExport(w io.Writer, export domain.CraftingExport) error {
+	var b strings.Builder
+
+	title := export.Name
+	if title == "" {
+		title = "Crafting list"
+	}
+	fmt.Fprintf(&b, "# %s\n\n## Items\n\n", title)
+	for _, item := range export.Items {
+		fmt.Fprintf(&b, "- [ ] %s x%d\n", item.Name, item.Quantity)
+	}
+
+	if len(export.Unknown) > 0 {
+		b.WriteString("\n## Unknown items\n\n")
+		for _, item := range export.Unknown {
+			fmt.Fprintf(&b, "- %s x%d\n", unknownItemName(item), item.Quantity)
+		}
+	}
+
+	b.WriteString("\n## Resources\n\n")
+	for _, resource := range export.Resources {
+		if resource.Owned > 0 {
+			fmt.Fprintf(&b, "- [ ] %s: %d (%d owned, %d missing)\n", resource.Name, resource.Total, resource.Owned, resource.Missing)
+		} else {
+			fmt.Fprintf(&b, "- [ ] %s: %d\n", resource.Name, resource.Total)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// unknownItemName names an item whose recipe no longer exists, falling back
+// to its recipe ID when the item was saved without a name
+func unknownItemName(item domain.CraftingItem) string {
+	if item.Name != "" {
+		return item.Name
+	}
+	return fmt.Sprintf("recipe %d", item.ID)
+}
+
+func checkFormat(format domain.ExportFormat, allowed ...domain.ExportFormat) error {
+	for _, f := range allowed {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidInput, format)
+}
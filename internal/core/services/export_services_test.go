@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func newExportService(repo *fakeCraftingRepo) *exportService {
+	return NewExportService(repo, NewCraftingService(repo, noTechnologies{}), nil).(*exportService)
+}
+
+// exportRequest asks for a Chest and for a recipe that no longer exists
+var exportRequest = domain.CraftingRequest{Items: []domain.CraftingItem{
+	{ID: 12, Quantity: 1},
+	{ID: 99, Name: "Old Chest", Quantity: 2},
+}}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportService(newChestRepo()).Export(&buf, "Base", exportRequest, domain.FormatJSON); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var export domain.CraftingExport
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("Export() wrote invalid JSON: %v", err)
+	}
+	if export.Name != "Base" {
+		t.Errorf("name = %q, want Base", export.Name)
+	}
+	if want := []domain.CraftingItem{{ID: 12, Name: "Chest", Quantity: 1}}; !reflect.DeepEqual(export.Items, want) {
+		t.Errorf("items = %+v, want %+v", export.Items, want)
+	}
+	if want := []domain.CraftingItem{{ID: 99, Name: "Old Chest", Quantity: 2}}; !reflect.DeepEqual(export.Unknown, want) {
+		t.Errorf("unknown = %+v, want %+v", export.Unknown, want)
+	}
+	want := []domain.ResourceTotal{
+		{Name: "Ore", Total: 4, Missing: 4},
+		{Name: "Wood", Total: 5, Missing: 5},
+	}
+	if !reflect.DeepEqual(export.Resources, want) {
+		t.Errorf("resources = %+v, want %+v", export.Resources, want)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportService(newChestRepo()).Export(&buf, "Base", exportRequest, domain.FormatCSV); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := "type,name,quantity,owned,missing\n" +
+		"item,Chest,1,,\n" +
+		"unknown,Old Chest,2,,\n" +
+		"resource,Ore,4,0,4\n" +
+		"resource,Wood,5,0,5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	repo := newChestRepo(domain.InventoryItem{ResourceID: 1, Quantity: 2})
+	request := exportRequest
+	request.UseInventory = true
+
+	var buf bytes.Buffer
+	if err := newExportService(repo).Export(&buf, "", request, domain.FormatMarkdown); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := "# Crafting list\n\n" +
+		"## Items\n\n- [ ] Chest x1\n\n" +
+		"## Unknown items\n\n- Old Chest x2\n\n" +
+		"## Resources\n\n- [ ] Ore: 4\n- [ ] Wood: 5 (2 owned, 3 missing)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := newExportService(newChestRepo()).Export(&buf, "", exportRequest, domain.ExportFormat("xml"))
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Export() of an unsupported format error = %v, want domain.ErrInvalidInput", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		format domain.ExportFormat
+		input  string
+		want   domain.ImportResult
+	}{
+		{
+			name:   "JSON items",
+			format: domain.FormatJSON,
+			input:  `[{"name": "chest", "quantity": 1}, {"name": "Nail", "quantity": 3}, {"name": "Chest", "quantity": 2}]`,
+			want: domain.ImportResult{Items: []domain.CraftingItem{
+				{ID: 12, Name: "Chest", Quantity: 3},
+				{ID: 11, Name: "Nail", Quantity: 3},
+			}},
+		},
+		{
+			name:   "JSON export",
+			format: domain.FormatJSON,
+			input:  `{"name": "Base", "items": [{"id": 12, "name": "Chest", "quantity": 1}], "unknown": [{"id": 99, "name": "Old Chest", "quantity": 2}]}`,
+			want:   domain.ImportResult{Items: []domain.CraftingItem{{ID: 12, Name: "Chest", Quantity: 1}}},
+		},
+		{
+			name:   "JSON problems",
+			format: domain.FormatJSON,
+			input:  `[{"name": "Ingot", "quantity": 2}, {"name": "Saddle", "quantity": 1}, {"name": "", "quantity": 1}, {"name": "Nail", "quantity": 0}]`,
+			want: domain.ImportResult{
+				Items:   []domain.CraftingItem{{ID: 10, Name: "Ingot", Quantity: 2}},
+				Unknown: []string{"Saddle"},
+				Invalid: []string{"item 3: missing name", "item 4 (Nail): quantity must be positive"},
+			},
+		},
+		{
+			name:   "CSV export",
+			format: domain.FormatCSV,
+			input:  "type,name,quantity,owned,missing\nitem,Chest,1,,\nunknown,Old Chest,2,,\nresource,Ore,4,0,4\n",
+			want:   domain.ImportResult{Items: []domain.CraftingItem{{ID: 12, Name: "Chest", Quantity: 1}}},
+		},
+		{
+			name:   "CSV problems",
+			format: domain.FormatCSV,
+			input:  "Quantity,Name\n2, Nail\nx,Chest\n1,\n1,Saddle\n",
+			want: domain.ImportResult{
+				Items:   []domain.CraftingItem{{ID: 11, Name: "Nail", Quantity: 2}},
+				Unknown: []string{"Saddle"},
+				Invalid: []string{`line 3 (Chest): invalid quantity "x"`, "line 4: missing name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newExportService(newChestRepo()).Import(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			for _, list := range []*[]string{&tt.want.Unknown, &tt.want.Ambiguous, &tt.want.Invalid} {
+				if *list == nil {
+					*list = []string{}
+				}
+			}
+			if !reflect.DeepEqual(*result, tt.want) {
+				t.Errorf("Import() = %+v, want %+v", *result, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportAmbiguous(t *testing.T) {
+	repo := newChestRepo()
+	repo.recipes[13] = &domain.RecipeWithResources{CraftingRecipe: domain.CraftingRecipe{ID: 13, Name: "Chest", Yield: 1}}
+
+	result, err := newExportService(repo).Import(strings.NewReader(`[{"name": "Chest", "quantity": 1}, {"name": "Nail", "quantity": 2}]`), domain.FormatJSON)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if want := []string{"Chest"}; !reflect.DeepEqual(result.Ambiguous, want) {
+		t.Errorf("ambiguous = %v, want %v", result.Ambiguous, want)
+	}
+	if want := []domain.CraftingItem{{ID: 11, Name: "Nail", Quantity: 2}}; !reflect.DeepEqual(result.Items, want) {
+		t.Errorf("items = %+v, want %+v", result.Items, want)
+	}
+}
+
+func TestImportInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		format domain.ExportFormat
+		input  string
+	}{
+		{"invalid JSON", domain.FormatJSON, `{"items": [`},
+		{"empty CSV", domain.FormatCSV, ""},
+		{"CSV without quantity column", domain.FormatCSV, "name\nChest\n"},
+		{"unsupported format", domain.FormatMarkdown, "# Crafting list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newExportService(newChestRepo()).Import(strings.NewReader(tt.input), tt.format)
+			if !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Import() error = %v, want domain.ErrInvalidInput", err)
+			}
+		})
+	}
+}
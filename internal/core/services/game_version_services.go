@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type gameVersionService struct {
+	repo ports.GameVersionRepository
+}
+
+// NewGameVersionService creates a new game version service
+func NewGameVersionService(repo ports.GameVersionRepository) ports.GameVersionService {
+	return &gameVersionService{
+		repo: repo,
+	}
+}
+
+// GetVersions retrieves every recorded game version and flags the current one
+func (s *gameVersionService) GetVersions() ([]domain.GameVersion, error) {
+	versions, err := s.repo.GetGameVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetCurrentGameVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		versions[i].Current = versions[i].Version == current
+	}
+	if versions == nil {
+		versions = []domain.GameVersion{}
+	}
+	return versions, nil
+}
+
+// GetCurrentVersion retrieves the game version of the loaded data
+func (s *gameVersionService) GetCurrentVersion() (string, error) {
+	return s.repo.GetCurrentGameVersion()
+}
+
+// Diff compares the recipes, resources and technologies of two game
+// versions. An empty target version compares against the current one.
+func (s *gameVersionService) Diff(from, to string) (*domain.VersionDiff, error) {
+	if from == "" {
+		return nil, fmt.Errorf("%w: the version to compare from is required", domain.ErrInvalidInput)
+	}
+	if to == "" {
+		current, err := s.repo.GetCurrentGameVersion()
+		if err != nil {
+			return nil, err
+		}
+		to = current
+	}
+
+	before, err := s.snapshot(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.snapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &domain.VersionDiff{
+		From:         from,
+		To:           to,
+		Added:        []string{},
+		Removed:      []string{},
+		Changed:      []domain.RecipeChange{},
+		Resources:    diffRecords(before.Resources, after.Resources),
+		Technologies: diffRecords(before.Technologies, after.Technologies),
+	}
+
+	for recipe, ingredients := range after.Recipes {
+		old, ok := before.Recipes[recipe]
+		if !ok {
+			diff.Added = append(diff.Added, recipe)
+			continue
+		}
+		if changes := diffIngredients(old, ingredients); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, domain.RecipeChange{Recipe: recipe, Ingredients: changes})
+		}
+	}
+	for recipe := range before.Recipes {
+		if _, ok := after.Recipes[recipe]; !ok {
+			diff.Removed = append(diff.Removed, recipe)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Recipe < diff.Changed[j].Recipe
+	})
+
+	return diff, nil
+}
+
+func (s *gameVersionService) snapshot(version string) (*domain.VersionSnapshot, error) {
+	snapshot, err := s.repo.GetVersionSnapshot(version)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("%w: game version %s", domain.ErrNotFound, version)
+	}
+	return snapshot, nil
+}
+
+// diffRecords lists the names only present after or only present before, sorted
+func diffRecords(before, after map[string]bool) domain.RecordDiff {
+	diff := domain.RecordDiff{Added: []string{}, Removed: []string{}}
+	for name := range after {
+		if !before[name] {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for name := range before {
+		if !after[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// diffIngredients lists the ingredients whose quantity differs, by name
+func diffIngredients(before, after map[string]int) []domain.IngredientChange {
+	var changes []domain.IngredientChange
+	for resource, quantity := range after {
+		if before[resource] != quantity {
+			changes = append(changes, domain.IngredientChange{Resource: resource, From: before[resource], To: quantity})
+		}
+	}
+	for resource, quantity := range before {
+		if _, ok := after[resource]; !ok {
+			changes = append(changes, domain.IngredientChange{Resource: resource, From: quantity})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Resource < changes[j].Resource
+	})
+	return changes
+}
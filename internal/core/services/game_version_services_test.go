@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/adapters/database"
+	"palworld-helper/internal/core/domain"
+)
+
+// fakeGameVersionRepo serves recorded snapshots from memory
+type fakeGameVersionRepo struct {
+	current   string
+	snapshots map[string]*domain.VersionSnapshot
+}
+
+func (r *fakeGameVersionRepo) GetGameVersions() ([]domain.GameVersion, error) {
+	var versions []domain.GameVersion
+	for version := range r.snapshots {
+		versions = append(versions, domain.GameVersion{Version: version})
+	}
+	return versions, nil
+}
+
+func (r *fakeGameVersionRepo) GetCurrentGameVersion() (string, error) {
+	return r.current, nil
+}
+
+func (r *fakeGameVersionRepo) GetVersionSnapshot(version string) (*domain.VersionSnapshot, error) {
+	return r.snapshots[version], nil
+}
+
+func nameSet(values ...string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// newGameVersionRepo records a 0.6.0 patch making the Chest more expensive,
+// replacing the Torch with the Pal Sphere and Fiber with Paldium
+func newGameVersionRepo() *fakeGameVersionRepo {
+	return &fakeGameVersionRepo{
+		current: "0.6.0",
+		snapshots: map[string]*domain.VersionSnapshot{
+			"0.5.0": {
+				Recipes: map[string]map[string]int{
+					"Chest": {"Wood": 5, "Nail": 3},
+					"Arrow": {"Wood": 1, "Stone": 1},
+					"Torch": {"Wood": 2, "Fiber": 1},
+				},
+				Resources:    nameSet("Wood", "Stone", "Nail", "Fiber"),
+				Technologies: nameSet("Chest", "Torch"),
+			},
+			"0.6.0": {
+				Recipes: map[string]map[string]int{
+					"Chest":      {"Wood": 8, "Nail": 3},
+					"Arrow":      {"Wood": 1, "Stone": 1},
+					"Pal Sphere": {"Paldium": 1},
+				},
+				Resources:    nameSet("Wood", "Stone", "Nail", "Paldium"),
+				Technologies: nameSet("Chest", "Pal Sphere"),
+			},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	service := NewGameVersionService(newGameVersionRepo())
+
+	diff, err := service.Diff("0.5.0", "")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	want := &domain.VersionDiff{
+		From:    "0.5.0",
+		To:      "0.6.0",
+		Added:   []string{"Pal Sphere"},
+		Removed: []string{"Torch"},
+		Changed: []domain.RecipeChange{
+			{Recipe: "Chest", Ingredients: []domain.IngredientChange{{Resource: "Wood", From: 5, To: 8}}},
+		},
+		Resources:    domain.RecordDiff{Added: []string{"Paldium"}, Removed: []string{"Fiber"}},
+		Technologies: domain.RecordDiff{Added: []string{"Pal Sphere"}, Removed: []string{"Torch"}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("Diff() = %+v, want %+v", diff, want)
+	}
+
+	diff, err = service.Diff("0.6.0", "0.6.0")
+	if err != nil {
+		t.Fatalf("Diff() of a version with itself error = %v", err)
+	}
+	empty := domain.RecordDiff{Added: []string{}, Removed: []string{}}
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) > 0 || !reflect.DeepEqual(diff.Resources, empty) || !reflect.DeepEqual(diff.Technologies, empty) {
+		t.Errorf("Diff() of a version with itself = %+v, want no change", diff)
+	}
+
+	if _, err := service.Diff("", "0.6.0"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Diff() without a version to compare from error = %v, want domain.ErrInvalidInput", err)
+	}
+	if _, err := service.Diff("0.4.0", "0.6.0"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Diff() from an unknown version error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestDiffIngredients(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]int
+		after  map[string]int
+		want   []domain.IngredientChange
+	}{
+		{"unchanged", map[string]int{"Wood": 5}, map[string]int{"Wood": 5}, nil},
+		{
+			name:   "quantity changed",
+			before: map[string]int{"Wood": 5, "Stone": 2},
+			after:  map[string]int{"Wood": 8, "Stone": 2},
+			want:   []domain.IngredientChange{{Resource: "Wood", From: 5, To: 8}},
+		},
+		{
+			name:   "ingredients added and removed",
+			before: map[string]int{"Wood": 5, "Fiber": 1},
+			after:  map[string]int{"Wood": 5, "Stone": 3, "Nail": 2},
+			want: []domain.IngredientChange{
+				{Resource: "Fiber", From: 1},
+				{Resource: "Nail", To: 2},
+				{Resource: "Stone", To: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffIngredients(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffIngredients() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagStale(t *testing.T) {
+	items := func(names ...string) []domain.CraftingItem {
+		var items []domain.CraftingItem
+		for _, name := range names {
+			items = append(items, domain.CraftingItem{Name: name, Quantity: 1})
+		}
+		return items
+	}
+
+	repo := &fakeCraftingListRepo{lists: []domain.CraftingList{
+		{ID: 1, Name: "Untagged", Items: items("Chest")},
+		{ID: 2, Name: "Current", GameVersion: "0.6.0", Items: items("Chest")},
+		{ID: 3, Name: "Rebalanced", GameVersion: "0.5.0", Items: items("Arrow", "Chest")},
+		{ID: 4, Name: "Removed", GameVersion: "0.5.0", Items: items("Torch", "Arrow")},
+		{ID: 5, Name: "Unchanged", GameVersion: "0.5.0", Items: items("Arrow")},
+		{ID: 6, Name: "Unrecorded", GameVersion: "0.4.0", Items: items("Chest")},
+	}}
+	service := NewCraftingListService(repo, nil, nil, NewGameVersionService(newGameVersionRepo()))
+
+	lists, err := service.GetAllLists()
+	if err != nil {
+		t.Fatalf("GetAllLists() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"Untagged":   nil,
+		"Current":    nil,
+		"Rebalanced": {"Chest"},
+		"Removed":    {"Torch"},
+		"Unchanged":  nil,
+		"Unrecorded": nil,
+	}
+	for _, list := range lists {
+		if !reflect.DeepEqual(list.StaleRecipes, want[list.Name]) || list.Stale != (want[list.Name] != nil) {
+			t.Errorf("%s: stale = %v %v, want %v", list.Name, list.Stale, list.StaleRecipes, want[list.Name])
+		}
+	}
+
+	list, err := service.GetList(3)
+	if err != nil {
+		t.Fatalf("GetList() error = %v", err)
+	}
+	if !list.Stale {
+		t.Error("GetList() of a list using a rebalanced recipe is not stale")
+	}
+}
+
+func TestDiffLoadedDatasets(t *testing.T) {
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	defer db.Close()
+
+	load := func(version string, recipes ...string) {
+		t.Helper()
+		dataset := &domain.Dataset{Version: version, GameVersion: version}
+		for _, recipe := range recipes {
+			dataset.Resources = append(dataset.Resources, domain.DatasetResource{Name: recipe + " Ore"})
+			dataset.Recipes = append(dataset.Recipes, domain.DatasetRecipe{Name: recipe, Resources: map[string]int{recipe + " Ore": 1}})
+			dataset.Technologies = append(dataset.Technologies, domain.DatasetTechnology{Name: recipe + " Tech", Level: 1, Points: 1, Recipes: []string{recipe}})
+		}
+		if err := db.UpsertDataset(dataset); err != nil {
+			t.Fatalf("UpsertDataset(%s) error = %v", version, err)
+		}
+	}
+
+	load("1.0", "Old", "Keep")
+	recipes, err := db.GetAllRecipes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &domain.CraftingList{Name: "Base", GameVersion: "1.0"}
+	for _, recipe := range recipes {
+		list.Items = append(list.Items, domain.CraftingItem{ID: recipe.ID, Quantity: 1})
+	}
+	if err := db.CreateCraftingList(list); err != nil {
+		t.Fatal(err)
+	}
+	load("2.0", "Keep")
+
+	versions := NewGameVersionService(db)
+	diff, err := versions.Diff("1.0", "2.0")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"Old"}) || len(diff.Added) != 0 || len(diff.Changed) != 0 {
+		t.Errorf("recipes added %v, removed %v, changed %v, want Old removed", diff.Added, diff.Removed, diff.Changed)
+	}
+	if !reflect.DeepEqual(diff.Resources.Removed, []string{"Old Ore"}) || len(diff.Resources.Added) != 0 {
+		t.Errorf("resources = %+v, want Old Ore removed", diff.Resources)
+	}
+	if !reflect.DeepEqual(diff.Technologies.Removed, []string{"Old Tech"}) || len(diff.Technologies.Added) != 0 {
+		t.Errorf("technologies = %+v, want Old Tech removed", diff.Technologies)
+	}
+
+	lists, err := NewCraftingListService(db, db, nil, versions).GetAllLists()
+	if err != nil {
+		t.Fatalf("GetAllLists() error = %v", err)
+	}
+	if len(lists) != 1 || !lists[0].Stale || !reflect.DeepEqual(lists[0].StaleRecipes, []string{"Old"}) {
+		t.Errorf("lists = %+v, want Base stale because of Old", lists)
+	}
+}
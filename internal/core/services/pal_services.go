@@ -0,0 +1,86 @@
+package services
+
+import (
+	"io/fs"
+	"strings"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+// paldeckImageDir is the URL prefix of the paldeck images served as static files
+const paldeckImageDir = "/static/images/paldeck/"
+
+type palService struct {
+	repo   ports.PalRepository
+	images fs.FS
+}
+
+// NewPalService creates a new paldeck service. images holds the paldeck
+// images served under paldeckImageDir, named after the paldeck codes.
+func NewPalService(repo ports.PalRepository, images fs.FS) ports.PalService {
+	return &palService{
+		repo:   repo,
+		images: images,
+	}
+}
+
+// GetAllPals retrieves every pal of the paldeck
+func (s *palService) GetAllPals() ([]domain.Pal, error) {
+	pals, err := s.repo.GetAllPals()
+	if err != nil {
+		return nil, err
+	}
+	return withImagePaths(pals, s.images), nil
+}
+
+// GetPal retrieves a specific pal, returning domain.ErrNotFound if it does not exist
+func (s *palService) GetPal(id int) (*domain.Pal, error) {
+	pal, err := s.repo.GetPalByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pal == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	pal.ImagePath = paldeckImagePath(*pal, s.images)
+	return pal, nil
+}
+
+// SearchPals retrieves the pals matching the query
+func (s *palService) SearchPals(query domain.PalQuery) ([]domain.Pal, error) {
+	query.Name = strings.TrimSpace(query.Name)
+	query.Element = strings.TrimSpace(query.Element)
+	query.WorkType = strings.TrimSpace(query.WorkType)
+	query.Drop = strings.TrimSpace(query.Drop)
+
+	pals, err := s.repo.SearchPals(query)
+	if err != nil {
+		return nil, err
+	}
+	return withImagePaths(pals, s.images), nil
+}
+
+func withImagePaths(pals []domain.Pal, images fs.FS) []domain.Pal {
+	if pals == nil {
+		return []domain.Pal{}
+	}
+	for i := range pals {
+		pals[i].ImagePath = paldeckImagePath(pals[i], images)
+	}
+	return pals
+}
+
+// paldeckImagePath returns the image of a pal, named after its paldeck code,
+// or an empty path when no image of the pal is shipped
+func paldeckImagePath(pal domain.Pal, images fs.FS) string {
+	name := pal.PaldeckCode() + ".png"
+	if images == nil {
+		return ""
+	}
+	if _, err := fs.Stat(images, name); err != nil {
+		return ""
+	}
+	return paldeckImageDir + name
+}
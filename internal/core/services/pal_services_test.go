@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"palworld-helper/internal/core/domain"
+)
+
+func TestPaldeckImagePath(t *testing.T) {
+	images := fstest.MapFS{
+		"085.png":  {Data: []byte("png")},
+		"012B.png": {Data: []byte("png")},
+	}
+
+	tests := []struct {
+		name string
+		pal  domain.Pal
+		want string
+	}{
+		{"shipped image", domain.Pal{PaldeckNumber: 85}, "/static/images/paldeck/085.png"},
+		{"shipped variant image", domain.Pal{PaldeckNumber: 12, Variant: "B"}, "/static/images/paldeck/012B.png"},
+		{"missing image", domain.Pal{PaldeckNumber: 1}, ""},
+		{"missing variant image", domain.Pal{PaldeckNumber: 85, Variant: "B"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paldeckImagePath(tt.pal, images); got != tt.want {
+				t.Errorf("paldeckImagePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := paldeckImagePath(domain.Pal{PaldeckNumber: 85}, nil); got != "" {
+		t.Errorf("paldeckImagePath() without images = %q, want empty", got)
+	}
+}
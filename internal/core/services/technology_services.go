@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"palworld-helper/internal/core/domain"
+	"palworld-helper/internal/core/ports"
+)
+
+type technologyService struct {
+	repo ports.TechnologyRepository
+}
+
+// NewTechnologyService creates a new technology service
+func NewTechnologyService(repo ports.TechnologyRepository) ports.TechnologyService {
+	return &technologyService{
+		repo: repo,
+	}
+}
+
+// GetTechnologyTree retrieves the technology tree grouped by player level
+func (s *technologyService) GetTechnologyTree() ([]domain.TechnologyLevel, error) {
+	technologies, err := s.repo.GetAllTechnologies()
+	if err != nil {
+		return nil, err
+	}
+
+	levels := []domain.TechnologyLevel{}
+	for _, technology := range technologies {
+		if len(levels) == 0 || levels[len(levels)-1].Level != technology.Level {
+			levels = append(levels, domain.TechnologyLevel{Level: technology.Level})
+		}
+		current := &levels[len(levels)-1]
+		current.Technologies = append(current.Technologies, technology)
+	}
+
+	return levels, nil
+}
+
+// SetUnlocked stores whether the player has unlocked a technology
+func (s *technologyService) SetUnlocked(id int, unlocked bool) error {
+	if err := s.repo.SetTechnologyUnlocked(id, unlocked); err != nil {
+		return fmt.Errorf("failed to update technology %d: %w", id, err)
+	}
+	return nil
+}
+
+// recipeTechnologies indexes technologies by the recipes they unlock
+type recipeTechnologies map[int][]domain.Technology
+
+func loadRecipeTechnologies(repo ports.TechnologyRepository) (recipeTechnologies, error) {
+	technologies, err := repo.GetAllTechnologies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load technologies: %w", err)
+	}
+
+	index := make(recipeTechnologies)
+	for _, technology := range technologies {
+		for _, recipeID := range technology.RecipeIDs {
+			index[recipeID] = append(index[recipeID], technology)
+		}
+	}
+	return index, nil
+}
+
+// locked reports whether a recipe still needs one of its technologies unlocked
+func (t recipeTechnologies) locked(recipeID int) bool {
+	technologies := t[recipeID]
+	for _, technology := range technologies {
+		if technology.Unlocked {
+			return false
+		}
+	}
+	return len(technologies) > 0
+}
+
+// requirement sums up the technologies needed to craft the given recipes.
+// When several technologies unlock the same recipe the lowest level one is
+// used, and recipes already unlocked add neither points nor player level.
+func (t recipeTechnologies) requirement(recipeIDs []int) domain.TechnologyRequirement {
+	requirement := domain.TechnologyRequirement{Technologies: []domain.Technology{}}
+	seen := make(map[int]bool)
+
+	for _, recipeID := range recipeIDs {
+		if len(t[recipeID]) == 0 {
+			continue
+		}
+
+		cheapest := t[recipeID][0]
+		for _, technology := range t[recipeID] {
+			if technology.Unlocked {
+				cheapest = technology
+				break
+			}
+			if technology.Level < cheapest.Level {
+				cheapest = technology
+			}
+		}
+
+		if cheapest.Unlocked {
+			continue
+		}
+		requirement.PlayerLevel = max(requirement.PlayerLevel, cheapest.Level)
+		if seen[cheapest.ID] {
+			continue
+		}
+		seen[cheapest.ID] = true
+		requirement.Points += cheapest.Points
+		requirement.Technologies = append(requirement.Technologies, cheapest)
+	}
+
+	sort.Slice(requirement.Technologies, func(i, j int) bool {
+		if requirement.Technologies[i].Level != requirement.Technologies[j].Level {
+			return requirement.Technologies[i].Level < requirement.Technologies[j].Level
+		}
+		return requirement.Technologies[i].Name < requirement.Technologies[j].Name
+	})
+
+	return requirement
+}
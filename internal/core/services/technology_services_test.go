@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"palworld-helper/internal/core/domain"
+)
+
+// fakeTechnologyRepo keeps the technology tree in memory, in the level and
+// name order the database returns it in
+type fakeTechnologyRepo struct {
+	technologies []domain.Technology
+}
+
+func (r *fakeTechnologyRepo) GetAllTechnologies() ([]domain.Technology, error) {
+	return append([]domain.Technology(nil), r.technologies...), nil
+}
+
+func (r *fakeTechnologyRepo) SetTechnologyUnlocked(id int, unlocked bool) error {
+	for i := range r.technologies {
+		if r.technologies[i].ID == id {
+			r.technologies[i].Unlocked = unlocked
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// newTechnologyRepo unlocks the Chest (recipe 12) at level 2 or level 5, and
+// the Nail (recipe 11) and Ingot (recipe 10) at level 4
+func newTechnologyRepo() *fakeTechnologyRepo {
+	return &fakeTechnologyRepo{technologies: []domain.Technology{
+		{ID: 1, Name: "Wooden Chest", Level: 2, Points: 1, RecipeIDs: []int{12}},
+		{ID: 2, Name: "Metal Working", Level: 4, Points: 2, RecipeIDs: []int{10, 11}},
+		{ID: 3, Name: "Primitive Furnace", Level: 4, Points: 1, RecipeIDs: []int{10}},
+		{ID: 4, Name: "Metal Chest", Level: 5, Points: 3, RecipeIDs: []int{12}},
+	}}
+}
+
+func TestGetTechnologyTree(t *testing.T) {
+	service := NewTechnologyService(newTechnologyRepo())
+
+	levels, err := service.GetTechnologyTree()
+	if err != nil {
+		t.Fatalf("GetTechnologyTree() error = %v", err)
+	}
+
+	got := make(map[int][]string)
+	var order []int
+	for _, level := range levels {
+		order = append(order, level.Level)
+		for _, technology := range level.Technologies {
+			got[level.Level] = append(got[level.Level], technology.Name)
+		}
+	}
+	if want := []int{2, 4, 5}; !reflect.DeepEqual(order, want) {
+		t.Errorf("levels = %v, want %v", order, want)
+	}
+	want := map[int][]string{
+		2: {"Wooden Chest"},
+		4: {"Metal Working", "Primitive Furnace"},
+		5: {"Metal Chest"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("technologies by level = %v, want %v", got, want)
+	}
+
+	levels, err = NewTechnologyService(&fakeTechnologyRepo{}).GetTechnologyTree()
+	if err != nil {
+		t.Fatalf("GetTechnologyTree() of an empty tree error = %v", err)
+	}
+	if levels == nil || len(levels) != 0 {
+		t.Errorf("GetTechnologyTree() of an empty tree = %#v, want an empty slice", levels)
+	}
+}
+
+func TestSetUnlocked(t *testing.T) {
+	repo := newTechnologyRepo()
+	service := NewTechnologyService(repo)
+
+	if err := service.SetUnlocked(2, true); err != nil {
+		t.Fatalf("SetUnlocked(2, true) error = %v", err)
+	}
+	levels, err := service.GetTechnologyTree()
+	if err != nil {
+		t.Fatalf("GetTechnologyTree() error = %v", err)
+	}
+	for _, level := range levels {
+		for _, technology := range level.Technologies {
+			if technology.Unlocked != (technology.ID == 2) {
+				t.Errorf("%s unlocked = %v after unlocking Metal Working", technology.Name, technology.Unlocked)
+			}
+		}
+	}
+
+	if err := service.SetUnlocked(2, false); err != nil {
+		t.Fatalf("SetUnlocked(2, false) error = %v", err)
+	}
+	if repo.technologies[1].Unlocked {
+		t.Error("Metal Working is still unlocked after locking it again")
+	}
+
+	if err := service.SetUnlocked(99, true); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("SetUnlocked() of an unknown technology error = %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestGetAllRecipesLocked(t *testing.T) {
+	techRepo := newTechnologyRepo()
+	techRepo.technologies[2].Unlocked = true // Primitive Furnace unlocks the Ingot
+	service := NewCraftingService(newChestRepo(), techRepo)
+
+	names := func(recipes []domain.RecipeWithResources) map[string]bool {
+		locked := make(map[string]bool)
+		for _, recipe := range recipes {
+			locked[recipe.Name] = recipe.Locked
+		}
+		return locked
+	}
+
+	tests := []struct {
+		name  string
+		query domain.RecipeQuery
+		want  map[string]bool
+	}{
+		{"unflagged", domain.RecipeQuery{}, map[string]bool{"Ingot": false, "Nail": false, "Chest": false}},
+		{"flagged", domain.RecipeQuery{FlagLocked: true}, map[string]bool{"Ingot": false, "Nail": true, "Chest": true}},
+		{"hidden", domain.RecipeQuery{HideLocked: true}, map[string]bool{"Ingot": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipes, err := service.GetAllRecipes(tt.query)
+			if err != nil {
+				t.Fatalf("GetAllRecipes() error = %v", err)
+			}
+			if got := names(recipes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recipes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTechnologyRequirement(t *testing.T) {
+	tests := []struct {
+		name      string
+		unlocked  []int
+		recipeIDs []int
+		level     int
+		points    int
+		names     []string
+	}{
+		{
+			name:      "cheapest technology of each recipe",
+			recipeIDs: []int{10, 11, 12},
+			level:     4,
+			points:    3,
+			names:     []string{"Wooden Chest", "Metal Working"},
+		},
+		{
+			name:      "technology shared by two recipes counted once",
+			recipeIDs: []int{10, 11},
+			level:     4,
+			points:    2,
+			names:     []string{"Metal Working"},
+		},
+		{
+			name:      "unlocked technology adds no level",
+			unlocked:  []int{2},
+			recipeIDs: []int{10, 11, 12},
+			level:     2,
+			points:    1,
+			names:     []string{"Wooden Chest"},
+		},
+		{
+			name:      "recipe unlocked by its higher level technology",
+			unlocked:  []int{4},
+			recipeIDs: []int{12},
+			level:     0,
+			points:    0,
+			names:     []string{},
+		},
+		{
+			name:      "recipe without technology",
+			recipeIDs: []int{99},
+			names:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTechnologyRepo()
+			for _, id := range tt.unlocked {
+				if err := repo.SetTechnologyUnlocked(id, true); err != nil {
+					t.Fatal(err)
+				}
+			}
+			technologies, err := loadRecipeTechnologies(repo)
+			if err != nil {
+				t.Fatalf("loadRecipeTechnologies() error = %v", err)
+			}
+
+			requirement := technologies.requirement(tt.recipeIDs)
+			if requirement.PlayerLevel != tt.level || requirement.Points != tt.points {
+				t.Errorf("requirement = level %d, %d points, want level %d, %d points",
+					requirement.PlayerLevel, requirement.Points, tt.level, tt.points)
+			}
+			names := []string{}
+			for _, technology := range requirement.Technologies {
+				names = append(names, technology.Name)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("technologies = %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
@@ -6,25 +6,64 @@ import (
 	"path/filepath"
 
 	"palworld-helper/internal/adapters/web/handlers"
+	"palworld-helper/internal/core/domain"
 	"palworld-helper/internal/core/ports"
 )
 
 type Server struct {
-	craftingService ports.CraftingService
-	adminService    ports.AdminService
+	craftingService   ports.CraftingService
+	listService       ports.CraftingListService
+	exportService     ports.ExportService
+	technologyService ports.TechnologyService
+	palService        ports.PalService
+	breedingService   ports.BreedingService
+	datasetService    ports.DatasetService
+	versionService    ports.GameVersionService
+	adminService      ports.AdminService
+	authService       ports.AuthService
+	backupService     ports.BackupService
 }
 
-func NewServer(craftingService ports.CraftingService, adminService ports.AdminService) *Server {
+func NewServer(
+	craftingService ports.CraftingService,
+	listService ports.CraftingListService,
+	exportService ports.ExportService,
+	technologyService ports.TechnologyService,
+	palService ports.PalService,
+	breedingService ports.BreedingService,
+	datasetService ports.DatasetService,
+	versionService ports.GameVersionService,
+	adminService ports.AdminService,
+	authService ports.AuthService,
+	backupService ports.BackupService,
+) *Server {
 	return &Server{
-		craftingService: craftingService,
-		adminService:    adminService,
+		craftingService:   craftingService,
+		listService:       listService,
+		exportService:     exportService,
+		technologyService: technologyService,
+		palService:        palService,
+		breedingService:   breedingService,
+		datasetService:    datasetService,
+		versionService:    versionService,
+		adminService:      adminService,
+		authService:       authService,
+		backupService:     backupService,
 	}
 }
 
 func (s *Server) Start(addr string) error {
 	// Initialize handlers
 	craftingHandler := handlers.NewCraftingHandler(s.craftingService)
+	listHandler := handlers.NewCraftingListHandler(s.listService, s.exportService)
+	technologyHandler := handlers.NewTechnologyHandler(s.technologyService)
+	palHandler := handlers.NewPalHandler(s.palService)
+	breedingHandler := handlers.NewBreedingHandler(s.breedingService)
+	datasetHandler := handlers.NewDatasetHandler(s.datasetService)
+	versionHandler := handlers.NewGameVersionHandler(s.versionService)
 	adminHandler := handlers.NewAdminHandler(s.adminService)
+	authHandler := handlers.NewAuthHandler(s.authService)
+	backupHandler := handlers.NewBackupHandler(s.backupService)
 
 	// Setup routes
 	mux := http.NewServeMux()
@@ -43,13 +82,43 @@ func (s *Server) Start(addr string) error {
 	mux.HandleFunc("/", craftingHandler.HomePage)
 	mux.HandleFunc("/api/recipes", craftingHandler.GetRecipes)
 	mux.HandleFunc("/api/calculate", craftingHandler.CalculateResources)
+	mux.HandleFunc("/api/inventory", craftingHandler.HandleInventory)
+	mux.HandleFunc("/api/inventory/", craftingHandler.HandleInventory)
+	mux.HandleFunc("/api/lists", listHandler.HandleLists)
+	mux.HandleFunc("/api/lists/", listHandler.HandleLists)
+	mux.HandleFunc("/api/export", listHandler.Export)
+	mux.HandleFunc("/api/import", listHandler.Import)
+	mux.HandleFunc("/api/stations", craftingHandler.HandleStations)
+	mux.HandleFunc("/api/stations/", craftingHandler.HandleStations)
+	mux.HandleFunc("/api/technologies", technologyHandler.HandleTechnologies)
+	mux.HandleFunc("/api/technologies/", technologyHandler.HandleTechnologies)
+	mux.HandleFunc("/api/pals", palHandler.HandlePals)
+	mux.HandleFunc("/api/pals/", palHandler.HandlePals)
+	mux.HandleFunc("/api/breeding/child", breedingHandler.GetChild)
+	mux.HandleFunc("/api/breeding/path", breedingHandler.FindPath)
+	mux.HandleFunc("/api/versions", versionHandler.GetVersions)
+	mux.HandleFunc("/api/versions/diff", versionHandler.GetDiff)
 
-	// Admin interface
+	// Admin interface, every API route needs a session with a role allowing
+	// its reads (GET) and its writes (other methods)
+	viewer, editor, admin := domain.RoleViewer, domain.RoleEditor, domain.RoleAdmin
 	mux.HandleFunc("/admin", adminHandler.AdminPage)
-	mux.HandleFunc("/admin/api/schema", adminHandler.GetSchema)
-	mux.HandleFunc("/admin/api/table/", adminHandler.HandleTableOperations)
-	mux.HandleFunc("/admin/api/query", adminHandler.ExecuteQuery)
-	mux.HandleFunc("/admin/api/create-table", adminHandler.CreateTable)
+	mux.HandleFunc("/admin/api/login", authHandler.Login)
+	mux.HandleFunc("/admin/api/logout", authHandler.Logout)
+	mux.HandleFunc("/admin/api/me", authHandler.Require(viewer, viewer, authHandler.Me))
+	mux.HandleFunc("/admin/api/users", authHandler.Require(admin, admin, authHandler.HandleUsers))
+	mux.HandleFunc("/admin/api/users/", authHandler.Require(admin, admin, authHandler.HandleUsers))
+	mux.HandleFunc("/admin/api/schema", authHandler.Require(viewer, admin, adminHandler.HandleSchema))
+	mux.HandleFunc("/admin/api/table/", authHandler.Require(viewer, editor, adminHandler.HandleTableOperations))
+	mux.HandleFunc("/admin/api/batch", authHandler.Require(editor, editor, adminHandler.ApplyBatch))
+	// The query policy of the admin service decides what each role may run
+	mux.HandleFunc("/admin/api/query", authHandler.Require(viewer, viewer, adminHandler.ExecuteQuery))
+	mux.HandleFunc("/admin/api/create-table", authHandler.Require(admin, admin, adminHandler.CreateTable))
+	mux.HandleFunc("/admin/api/dataset", authHandler.Require(viewer, admin, datasetHandler.HandleDataset))
+	mux.HandleFunc("/admin/api/audit", authHandler.Require(admin, admin, adminHandler.GetAuditLog))
+	mux.HandleFunc("/admin/api/backups", authHandler.Require(admin, admin, backupHandler.HandleBackups))
+	mux.HandleFunc("/admin/api/backups/", authHandler.Require(admin, admin, backupHandler.HandleBackups))
+	mux.HandleFunc("/admin/api/restore", authHandler.Require(admin, admin, backupHandler.Restore))
 
 	return http.ListenAndServe(addr, mux)
 }
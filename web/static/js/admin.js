@@ -1,11 +1,100 @@
 let currentTable = '';
 let currentEditingRecord = null;
+let currentUser = null;
+
+// Column names of each table, from the schema
+let tableColumns = {};
+// Columns addressing the rows of each table: its primary key, or its rowid
+let tableKeys = {};
+// Rows of the page being browsed
+let currentRows = [];
+// Page, sort and filters of the table being browsed
+let tableView = { offset: 0, limit: 50, sort: '', order: 'asc', filters: {} };
+// Aborts the running query; closing its request interrupts it on the server
+let queryController = null;
+
+const roleRanks = { viewer: 1, editor: 2, admin: 3 };
 
 document.addEventListener('DOMContentLoaded', function() {
+    checkSession();
+});
+
+// hasRole reports whether the logged in user has at least the given role
+function hasRole(role) {
+    return currentUser !== null && roleRanks[currentUser.role] >= roleRanks[role];
+}
+
+async function checkSession() {
+    try {
+        const response = await fetch('/admin/api/me');
+        if (response.status === 401) {
+            showLogin();
+            return;
+        }
+        if (!response.ok) throw new Error(`HTTP error! status: ${response.status}`);
+
+        startSession(await response.json());
+    } catch (error) {
+        console.error('Error checking session:', error);
+        showNotification('Failed to check session', 'error');
+    }
+}
+
+function showLogin() {
+    currentUser = null;
+    document.getElementById('adminContent').style.display = 'none';
+    document.getElementById('userBadge').style.display = 'none';
+    document.getElementById('loginSection').style.display = 'block';
+}
+
+function startSession(user) {
+    currentUser = user;
+    document.getElementById('loginSection').style.display = 'none';
+    document.getElementById('adminContent').style.display = 'block';
+    document.getElementById('userBadge').style.display = 'flex';
+    document.getElementById('currentUser').textContent = `${user.username} (${user.role})`;
+
+    // Hide what the role of the user does not allow
+    document.querySelectorAll('[data-role]').forEach(element => {
+        element.style.display = hasRole(element.dataset.role) ? '' : 'none';
+    });
+
     showTab('schema');
     loadSchema();
     loadTableList();
-});
+}
+
+async function login(event) {
+    event.preventDefault();
+
+    try {
+        const response = await fetch('/admin/api/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                username: document.getElementById('loginUsername').value,
+                password: document.getElementById('loginPassword').value
+            })
+        });
+        if (!response.ok) throw new Error(await response.text());
+
+        const session = await response.json();
+        document.getElementById('loginForm').reset();
+        startSession(session.user);
+    } catch (error) {
+        console.error('Error logging in:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+async function logout() {
+    try {
+        await fetch('/admin/api/logout', { method: 'POST' });
+    } catch (error) {
+        console.error('Error logging out:', error);
+    }
+    showLogin();
+}
 
 function showTab(tabName) {
     // Hide all tabs
@@ -19,6 +108,14 @@ function showTab(tabName) {
     // Show selected tab
     document.getElementById(`${tabName}-tab`).classList.add('active');
     document.querySelector(`[onclick="showTab('${tabName}')"]`).classList.add('active');
+
+    if (tabName === 'users') {
+        loadUsers();
+    } else if (tabName === 'audit') {
+        loadAuditLog();
+    } else if (tabName === 'backups') {
+        loadBackups();
+    }
 }
 
 async function loadSchema() {
@@ -52,12 +149,28 @@ function renderSchema(schema) {
                         <span class="column-type">${escapeHtml(col.type)}</span>
                         <div class="column-attributes">
                             ${col.primary_key ? '<span class="column-attribute">PK</span>' : ''}
+                            ${(table.foreign_keys || []).filter(key => key.columns.includes(col.name)).map(key => `
+                                <span class="column-attribute">FK: ${escapeHtml(key.table)}.${escapeHtml(key.references[key.columns.indexOf(col.name)] || '?')}</span>
+                            `).join('')}
                             ${col.not_null ? '<span class="column-attribute">NOT NULL</span>' : ''}
                             ${col.default_value ? `<span class="column-attribute">DEFAULT: ${escapeHtml(col.default_value)}</span>` : ''}
                         </div>
                     </div>
                 `).join('')}
             </div>
+            ${(table.indexes || []).length > 0 ? `
+            <div class="columns-list">
+                ${table.indexes.map(index => `
+                    <div class="column-item">
+                        <span class="column-name">${escapeHtml(index.name)}</span>
+                        <span class="column-type">${index.columns.map(col => escapeHtml(col || '(expression)')).join(', ')}</span>
+                        <div class="column-attributes">
+                            ${index.unique ? '<span class="column-attribute">UNIQUE</span>' : ''}
+                            ${index.partial ? '<span class="column-attribute">PARTIAL</span>' : ''}
+                        </div>
+                    </div>
+                `).join('')}
+            </div>` : ''}
         </div>
     `).join('');
 }
@@ -69,33 +182,110 @@ async function loadTableList() {
 
         const schema = await response.json();
         const tableSelect = document.getElementById('tableSelect');
+        const alterSelect = document.getElementById('alterTable');
+        const alterSelected = alterSelect.value;
 
         tableSelect.innerHTML = '<option value="">Select a table...</option>';
+        alterSelect.innerHTML = '<option value="">Select a table...</option>';
+        tableColumns = {};
+        tableKeys = {};
         schema.forEach(table => {
+            tableColumns[table.name] = table.columns.map(col => col.name);
+            tableKeys[table.name] = table.row_key;
+
             const option = document.createElement('option');
             option.value = table.name;
             option.textContent = table.name;
             tableSelect.appendChild(option);
+            alterSelect.appendChild(option.cloneNode(true));
         });
+
+        if (tableColumns[alterSelected]) {
+            alterSelect.value = alterSelected;
+        }
+        updateAlterColumns();
     } catch (error) {
         console.error('Error loading table list:', error);
         showNotification('Failed to load table list', 'error');
     }
 }
 
+function selectTable() {
+    document.getElementById('importReport').innerHTML = '';
+    tableView = { offset: 0, limit: tableView.limit, sort: '', order: 'asc', filters: {} };
+    loadTableData();
+}
+
+function sortTable(column) {
+    if (tableView.sort === column) {
+        tableView.order = tableView.order === 'asc' ? 'desc' : 'asc';
+    } else {
+        tableView.sort = column;
+        tableView.order = 'asc';
+    }
+    tableView.offset = 0;
+    loadTableData();
+}
+
+function filterTable(column, value) {
+    if (value.trim()) {
+        tableView.filters[column] = value.trim();
+    } else {
+        delete tableView.filters[column];
+    }
+    tableView.offset = 0;
+    loadTableData();
+}
+
+function pageTable(direction) {
+    tableView.offset = Math.max(0, tableView.offset + direction * tableView.limit);
+    loadTableData();
+}
+
+// parseFilter turns the text of a filter box into column:operator:value. The
+// text may start with =, !=, >, >=, < or <=, or be null or !null, and is
+// otherwise searched for anywhere in the column.
+function parseFilter(column, text) {
+    if (text === 'null') return `${column}:null`;
+    if (text === '!null') return `${column}:notnull`;
+
+    const operators = [['!=', 'ne'], ['>=', 'gte'], ['<=', 'lte'], ['=', 'eq'], ['>', 'gt'], ['<', 'lt']];
+    for (const [prefix, operator] of operators) {
+        if (text.startsWith(prefix)) {
+            return `${column}:${operator}:${text.slice(prefix.length).trim()}`;
+        }
+    }
+    return `${column}:contains:${text}`;
+}
+
+function tableQueryParams() {
+    const params = new URLSearchParams({ limit: tableView.limit, offset: tableView.offset });
+    if (tableView.sort) {
+        params.set('sort', tableView.sort);
+        params.set('order', tableView.order);
+    }
+    Object.entries(tableView.filters).forEach(([column, text]) => {
+        params.append('filter', parseFilter(column, text));
+    });
+    return params;
+}
+
 async function loadTableData() {
     const tableSelect = document.getElementById('tableSelect');
     const tableName = tableSelect.value;
     const addButton = document.getElementById('addRecordBtn');
+    const transferButtons = [document.getElementById('exportTableBtn'), document.getElementById('importTableBtn')];
 
     if (!tableName) {
         document.getElementById('tableDataContainer').innerHTML = '';
         addButton.disabled = true;
+        transferButtons.forEach(button => button.disabled = true);
         return;
     }
 
     currentTable = tableName;
     addButton.disabled = false;
+    transferButtons.forEach(button => button.disabled = false);
 
     // Afficher un indicateur de chargement
     document.getElementById('tableDataContainer').innerHTML = `
@@ -107,7 +297,7 @@ async function loadTableData() {
     try {
         console.log(`Loading data for table: ${tableName}`);
 
-        const response = await fetch(`/admin/api/table/${tableName}`, {
+        const response = await fetch(`/admin/api/table/${encodeURIComponent(tableName)}?${tableQueryParams()}`, {
             method: 'GET',
             headers: {
                 'Accept': 'application/json',
@@ -132,9 +322,7 @@ async function loadTableData() {
             throw new Error(`Invalid JSON response: ${parseError.message}`);
         }
 
-        console.log('Parsed data:', data);
-        console.log('Data type:', typeof data);
-        console.log('Data length:', Array.isArray(data) ? data.length : 'Not an array');
+        console.log(`Showing ${data.rows.length} of ${data.total} rows`);
 
         renderTableData(data);
 
@@ -151,8 +339,8 @@ async function loadTableData() {
         document.getElementById('tableDataContainer').innerHTML = `
             <div style="color: #e94560; text-align: center; padding: 20px; background: rgba(233, 69, 96, 0.1); border-radius: 5px; margin: 10px 0;">
                 <h3>⚠️ Error Loading Table Data</h3>
-                <p><strong>Table:</strong> ${tableName}</p>
-                <p><strong>Error:</strong> ${errorMessage}</p>
+                <p><strong>Table:</strong> ${escapeHtml(tableName)}</p>
+                <p><strong>Error:</strong> ${escapeHtml(errorMessage)}</p>
                 <div style="margin-top: 15px;">
                     <button onclick="loadSchema(); setTimeout(() => loadTableData(), 1000);" class="btn btn-primary">Refresh Schema & Retry</button>
                     <button onclick="loadTableData()" class="btn btn-secondary">Retry Load</button>
@@ -162,44 +350,169 @@ async function loadTableData() {
     }
 }
 
-function renderTableData(data) {
+function renderTableData(page) {
     const container = document.getElementById('tableDataContainer');
+    const columns = tableColumns[currentTable] || (page.rows.length > 0 ? Object.keys(page.rows[0]) : []);
+    currentRows = page.rows;
 
-    if (data.length === 0) {
+    if (page.total === 0 && Object.keys(tableView.filters).length === 0) {
         container.innerHTML = '<p style="color: #e94560; text-align: center;">No data found in this table.</p>';
         return;
     }
 
-    const columns = Object.keys(data[0]);
+    const sortMark = col => tableView.sort === col ? (tableView.order === 'asc' ? ' ▲' : ' ▼') : '';
+    const first = page.total === 0 ? 0 : page.offset + 1;
+    const last = page.offset + page.rows.length;
 
     container.innerHTML = `
         <div class="data-table">
             <table>
                 <thead>
                     <tr>
-                        ${columns.map(col => `<th>${escapeHtml(col)}</th>`).join('')}
+                        ${columns.map(col => `<th class="sortable" data-column="${escapeHtml(col)}">${escapeHtml(col)}${sortMark(col)}</th>`).join('')}
                         <th>Actions</th>
                     </tr>
+                    <tr class="filter-row">
+                        ${columns.map(col => `
+                            <th><input type="text" placeholder="Filter..." title="Text to search, or =, !=, >, >=, <, <= followed by a value, or null, !null"
+                                value="${escapeHtml(tableView.filters[col] || '')}" data-column="${escapeHtml(col)}"></th>
+                        `).join('')}
+                        <th></th>
+                    </tr>
                 </thead>
                 <tbody>
-                    ${data.map(row => `
+                    ${page.rows.length === 0 ? `<tr><td colspan="${columns.length + 1}">No rows match the filters.</td></tr>` : ''}
+                    ${page.rows.map((row, index) => `
                         <tr>
-                            ${columns.map(col => `<td>${escapeHtml(String(row[col] || ''))}</td>`).join('')}
+                            ${columns.map(col => `<td>${escapeHtml(String(row[col] ?? ''))}</td>`).join('')}
                             <td>
+                                ${hasRole('editor') ? `
                                 <div class="action-buttons">
-                                    <button onclick="editRecord(${row.id})" class="btn btn-primary btn-small">Edit</button>
-                                    <button onclick="deleteRecord(${row.id})" class="btn btn-danger btn-small">Delete</button>
-                                </div>
+                                    <button onclick="editRecord(${index})" class="btn btn-primary btn-small">Edit</button>
+                                    <button onclick="deleteRecord(${index})" class="btn btn-danger btn-small">Delete</button>
+                                </div>` : ''}
                             </td>
                         </tr>
                     `).join('')}
                 </tbody>
             </table>
         </div>
+        <div class="pager">
+            <button onclick="pageTable(-1)" class="btn btn-secondary btn-small" ${page.offset === 0 ? 'disabled' : ''}>Previous</button>
+            <span>Rows ${first}-${last} of ${page.total}</span>
+            <button onclick="pageTable(1)" class="btn btn-secondary btn-small" ${last >= page.total ? 'disabled' : ''}>Next</button>
+        </div>
     `;
+
+    // Column names come from the database, so they are passed through data
+    // attributes rather than written into inline handlers
+    container.querySelectorAll('th.sortable').forEach(header => {
+        header.addEventListener('click', () => sortTable(header.dataset.column));
+    });
+    container.querySelectorAll('.filter-row input').forEach(input => {
+        input.addEventListener('change', () => filterTable(input.dataset.column, input.value));
+    });
+}
+
+// rowPath returns the URL path of a row: the values of its key columns
+function rowPath(row) {
+    const keys = tableKeys[currentTable] || ['id'];
+    return `/admin/api/table/${encodeURIComponent(currentTable)}/` +
+        keys.map(key => encodeURIComponent(String(row[key]))).join('/');
 }
 
-async function executeQuery() {
+function exportTable() {
+    if (!currentTable) return;
+
+    const format = document.getElementById('exportFormat').value;
+    window.location.href = `/admin/api/table/${encodeURIComponent(currentTable)}/export?format=${format}`;
+}
+
+// importTable sends the chosen file, its format taken from its extension, and
+// reports the rejected rows
+async function importTable(event) {
+    event.preventDefault();
+    if (!currentTable) return;
+
+    const file = document.getElementById('importFile').files[0];
+    if (!file) return;
+
+    const extension = file.name.split('.').pop().toLowerCase();
+    const format = ['csv', 'ndjson'].includes(extension) ? extension : 'json';
+    const params = new URLSearchParams({
+        format,
+        mode: document.getElementById('importMode').value,
+        dry_run: document.getElementById('importDryRun').checked
+    });
+
+    try {
+        const response = await fetch(`/admin/api/table/${encodeURIComponent(currentTable)}/import?${params}`, {
+            method: 'POST',
+            body: file
+        });
+        if (!response.ok && response.status !== 422) throw new Error(await response.text());
+
+        const result = await response.json();
+        renderImportReport(result);
+        if (result.committed) {
+            showNotification(`Imported ${result.imported} rows into ${result.table}`, 'success');
+            loadTableData();
+        } else if (result.errors.length > 0) {
+            showNotification(`${result.errors.length} rows were rejected, nothing was imported`, 'error');
+        } else {
+            showNotification(`Dry run: ${result.imported} rows can be imported`, 'info');
+        }
+    } catch (error) {
+        console.error('Error importing table:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+function renderImportReport(result) {
+    const container = document.getElementById('importReport');
+
+    if (result.errors.length === 0) {
+        container.innerHTML = '';
+        return;
+    }
+
+    container.innerHTML = `
+        <div class="data-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>Row</th>
+                        <th>Error</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${result.errors.map(error => `
+                        <tr>
+                            <td>${error.row}</td>
+                            <td>${escapeHtml(error.error)}</td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        </div>
+    `;
+}
+
+function executeQuery() {
+    runQuery('run');
+}
+
+function explainQuery() {
+    runQuery('plan');
+}
+
+function cancelQuery() {
+    if (queryController) {
+        queryController.abort();
+    }
+}
+
+async function runQuery(mode) {
     const query = document.getElementById('queryInput').value.trim();
 
     if (!query) {
@@ -207,13 +520,17 @@ async function executeQuery() {
         return;
     }
 
+    queryController = new AbortController();
+    setQueryRunning(true);
+
     try {
         const response = await fetch('/admin/api/query', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json'
             },
-            body: JSON.stringify({ query })
+            body: JSON.stringify({ query, mode }),
+            signal: queryController.signal
         });
 
         if (!response.ok) {
@@ -222,14 +539,58 @@ async function executeQuery() {
         }
 
         const result = await response.json();
-        renderQueryResults(result);
-        showNotification('Query executed successfully', 'success');
+        if (mode === 'plan') {
+            renderQueryPlan(result);
+            showNotification('Query plan loaded', 'success');
+        } else {
+            renderQueryResults(result);
+            showNotification('Query executed successfully', 'success');
+        }
     } catch (error) {
+        if (error.name === 'AbortError') {
+            showNotification('Query cancelled', 'info');
+            return;
+        }
         console.error('Error executing query:', error);
         showNotification(`Query failed: ${error.message}`, 'error');
+    } finally {
+        queryController = null;
+        setQueryRunning(false);
     }
 }
 
+function setQueryRunning(running) {
+    document.getElementById('executeQueryBtn').disabled = running;
+    document.getElementById('explainQueryBtn').disabled = running;
+    document.getElementById('cancelQueryBtn').disabled = !running;
+}
+
+function renderQueryPlan(plan) {
+    const container = document.getElementById('queryResults');
+
+    container.innerHTML = `
+        <div class="query-result">
+            <div class="result-info">
+                Query plan
+            </div>
+            ${plan.steps.length > 0 ? renderPlanSteps(plan.steps) : '<p>SQLite reported no plan.</p>'}
+        </div>
+    `;
+}
+
+function renderPlanSteps(steps) {
+    return `
+        <ul class="query-plan">
+            ${steps.map(step => `
+                <li>
+                    ${escapeHtml(step.detail)}
+                    ${step.children.length > 0 ? renderPlanSteps(step.children) : ''}
+                </li>
+            `).join('')}
+        </ul>
+    `;
+}
+
 function renderQueryResults(result) {
     const container = document.getElementById('queryResults');
 
@@ -384,6 +745,108 @@ async function createTable() {
     }
 }
 
+function updateAlterColumns() {
+    const table = document.getElementById('alterTable').value;
+    const columnSelect = document.getElementById('alterColumn');
+
+    columnSelect.innerHTML = (tableColumns[table] || [])
+        .map(column => `<option value="${escapeHtml(column)}">${escapeHtml(column)}</option>`)
+        .join('');
+    updateAlterForm();
+}
+
+function updateAlterForm() {
+    const action = document.getElementById('alterAction').value;
+
+    document.getElementById('alterColumnGroup').style.display =
+        ['rename_column', 'alter_column', 'drop_column'].includes(action) ? '' : 'none';
+    document.getElementById('alterNewNameGroup').style.display =
+        ['rename_column', 'rename_table'].includes(action) ? '' : 'none';
+    document.getElementById('alterDefinition').style.display =
+        ['add_column', 'alter_column'].includes(action) ? '' : 'none';
+    document.getElementById('alterDefName').style.display = action === 'add_column' ? '' : 'none';
+    document.getElementById('alterPreview').innerHTML = '';
+}
+
+async function alterTable(preview) {
+    const action = document.getElementById('alterAction').value;
+    const change = {
+        action: action,
+        table: document.getElementById('alterTable').value
+    };
+
+    if (!change.table) {
+        showNotification('Please select a table', 'error');
+        return;
+    }
+    if (['rename_column', 'alter_column', 'drop_column'].includes(action)) {
+        change.column_name = document.getElementById('alterColumn').value;
+    }
+    if (['rename_column', 'rename_table'].includes(action)) {
+        change.new_name = document.getElementById('alterNewName').value.trim();
+    }
+    if (['add_column', 'alter_column'].includes(action)) {
+        const defaultValue = document.getElementById('alterDefDefault').value.trim();
+        change.column = {
+            name: action === 'add_column' ? document.getElementById('alterDefName').value.trim() : change.column_name,
+            type: document.getElementById('alterDefType').value,
+            primary_key: document.getElementById('alterDefPrimary').checked,
+            not_null: document.getElementById('alterDefNotNull').checked,
+            default_value: defaultValue || null
+        };
+    }
+
+    if (!preview && ['drop_column', 'drop_table'].includes(action) &&
+        !confirm('This change deletes data and cannot be undone. Continue?')) {
+        return;
+    }
+
+    try {
+        const response = await fetch('/admin/api/schema' + (preview ? '?preview=true' : ''), {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json'
+            },
+            body: JSON.stringify(change)
+        });
+
+        if (!response.ok) {
+            const errorText = await response.text();
+            throw new Error(errorText);
+        }
+
+        const plan = await response.json();
+        renderSchemaPlan(plan);
+
+        if (!plan.applied) return;
+
+        showNotification(`Table "${change.table}" altered successfully`, 'success');
+        document.getElementById('alterNewName').value = '';
+        document.getElementById('alterDefName').value = '';
+        document.getElementById('alterDefDefault').value = '';
+
+        // Refresh schema and table list
+        loadSchema();
+        loadTableList();
+
+    } catch (error) {
+        console.error('Error altering table:', error);
+        showNotification(`Failed to alter table: ${error.message}`, 'error');
+    }
+}
+
+function renderSchemaPlan(plan) {
+    document.getElementById('alterPreview').innerHTML = `
+        <div class="query-result">
+            <div class="result-info">
+                ${plan.applied ? 'Applied' : 'Preview'}${plan.rebuild ? ' (the table is rebuilt)' : ''}
+            </div>
+            <pre>${escapeHtml(plan.statements.join(';\n') + ';')}</pre>
+            ${plan.warnings.map(warning => `<div>${escapeHtml(warning)}</div>`).join('')}
+        </div>
+    `;
+}
+
 async function addNewRecord() {
     if (!currentTable) {
         showNotification('No table selected', 'error');
@@ -403,7 +866,7 @@ async function addNewRecord() {
             return;
         }
 
-        openEditModal(null, tableSchema.columns);
+        openEditModal(null, tableSchema);
 
     } catch (error) {
         console.error('Error preparing new record:', error);
@@ -411,7 +874,7 @@ async function addNewRecord() {
     }
 }
 
-async function editRecord(recordId) {
+async function editRecord(index) {
     try {
         // Get table schema
         const schemaResponse = await fetch('/admin/api/schema');
@@ -425,19 +888,14 @@ async function editRecord(recordId) {
             return;
         }
 
-        // Get record data
-        const dataResponse = await fetch(`/admin/api/table/${currentTable}`);
-        if (!dataResponse.ok) throw new Error(`HTTP error! status: ${dataResponse.status}`);
-
-        const data = await dataResponse.json();
-        const record = data.find(r => r.id === recordId);
+        const record = currentRows[index];
 
         if (!record) {
             showNotification('Record not found', 'error');
             return;
         }
 
-        openEditModal(record, tableSchema.columns);
+        openEditModal(record, tableSchema);
 
     } catch (error) {
         console.error('Error loading record for edit:', error);
@@ -445,13 +903,13 @@ async function editRecord(recordId) {
     }
 }
 
-async function deleteRecord(recordId) {
+async function deleteRecord(index) {
     if (!confirm('Are you sure you want to delete this record?')) {
         return;
     }
 
     try {
-        const response = await fetch(`/admin/api/table/${currentTable}/${recordId}`, {
+        const response = await fetch(rowPath(currentRows[index]), {
             method: 'DELETE'
         });
 
@@ -469,8 +927,9 @@ async function deleteRecord(recordId) {
     }
 }
 
-function openEditModal(record, columns) {
+function openEditModal(record, table) {
     currentEditingRecord = record;
+    const columns = table.columns;
 
     const modal = document.getElementById('editModal');
     const modalTitle = document.getElementById('modalTitle');
@@ -499,6 +958,65 @@ function openEditModal(record, columns) {
     }).join('');
 
     modal.style.display = 'block';
+    loadForeignKeyOptions(table);
+}
+
+// loadForeignKeyOptions replaces the inputs of foreign key columns with the
+// rows of the parent table, labelled by name. Parents with too many rows to
+// list get suggestions on the input instead.
+async function loadForeignKeyOptions(table) {
+    for (const key of table.foreign_keys || []) {
+        if (key.columns.length !== 1) continue;
+
+        const column = table.columns.find(col => col.name === key.columns[0]);
+        const input = document.getElementById(`edit_${key.columns[0]}`);
+        if (!column || !input) continue;
+
+        try {
+            const response = await fetch(`/admin/api/table/${encodeURIComponent(table.name)}/lookup/${encodeURIComponent(column.name)}?limit=1000`);
+            if (!response.ok) throw new Error(await response.text());
+
+            const lookup = await response.json();
+            if (input.isConnected) {
+                renderLookup(input, column, lookup);
+            }
+        } catch (error) {
+            console.error(`Error loading options for ${column.name}:`, error);
+        }
+    }
+}
+
+function renderLookup(input, column, lookup) {
+    const optionLabel = option => `${option.label} (${lookup.references} ${option.value})`;
+
+    if (lookup.truncated) {
+        const list = document.createElement('datalist');
+        list.id = `${input.id}_options`;
+        list.innerHTML = lookup.options.map(option =>
+            `<option value="${escapeHtml(String(option.value))}" label="${escapeHtml(optionLabel(option))}"></option>`
+        ).join('');
+        input.setAttribute('list', list.id);
+        input.after(list);
+        return;
+    }
+
+    const current = input.value;
+    const select = document.createElement('select');
+    select.id = input.id;
+    select.name = input.name;
+    select.disabled = input.disabled;
+    select.required = input.required;
+
+    select.innerHTML = `<option value="">${column.not_null ? 'Select...' : '(none)'}</option>` +
+        lookup.options.map(option =>
+            `<option value="${escapeHtml(String(option.value))}">${escapeHtml(optionLabel(option))}</option>`
+        ).join('');
+    if (current !== '' && !lookup.options.some(option => String(option.value) === current)) {
+        select.innerHTML += `<option value="${escapeHtml(current)}">${escapeHtml(current)} (not in ${escapeHtml(lookup.parent)})</option>`;
+    }
+    select.value = current;
+
+    input.replaceWith(select);
 }
 
 function closeModal() {
@@ -540,11 +1058,11 @@ async function saveRecord() {
         if (currentEditingRecord) {
             // Update existing record
             method = 'PUT';
-            url = `/admin/api/table/${currentTable}/${currentEditingRecord.id}`;
+            url = rowPath(currentEditingRecord);
         } else {
             // Create new record
             method = 'POST';
-            url = `/admin/api/table/${currentTable}`;
+            url = `/admin/api/table/${encodeURIComponent(currentTable)}`;
         }
 
         console.log(`Making ${method} request to: ${url}`);
@@ -598,6 +1116,318 @@ async function saveRecord() {
     }
 }
 
+async function loadUsers() {
+    try {
+        const response = await fetch('/admin/api/users');
+        if (!response.ok) throw new Error(await response.text());
+
+        renderUsers(await response.json());
+    } catch (error) {
+        console.error('Error loading users:', error);
+        showNotification('Failed to load users', 'error');
+    }
+}
+
+function renderUsers(users) {
+    const container = document.getElementById('usersContainer');
+
+    container.innerHTML = `
+        <div class="data-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>Username</th>
+                        <th>Role</th>
+                        <th>Created</th>
+                        <th>Actions</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${users.map(user => `
+                        <tr>
+                            <td>${escapeHtml(user.username)}</td>
+                            <td>
+                                <select onchange="updateUserRole(${user.id}, this.value)">
+                                    ${Object.keys(roleRanks).map(role => `
+                                        <option value="${role}" ${role === user.role ? 'selected' : ''}>${role}</option>
+                                    `).join('')}
+                                </select>
+                            </td>
+                            <td>${escapeHtml(user.created_at)}</td>
+                            <td>
+                                <div class="action-buttons">
+                                    <button onclick="resetUserPassword(${user.id})" class="btn btn-secondary btn-small">Reset Password</button>
+                                    ${user.id !== currentUser.id ? `<button onclick="deleteUser(${user.id})" class="btn btn-danger btn-small">Delete</button>` : ''}
+                                </div>
+                            </td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        </div>
+    `;
+}
+
+async function createUser(event) {
+    event.preventDefault();
+
+    try {
+        const response = await fetch('/admin/api/users', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                username: document.getElementById('newUsername').value,
+                password: document.getElementById('newPassword').value,
+                role: document.getElementById('newRole').value
+            })
+        });
+        if (!response.ok) throw new Error(await response.text());
+
+        document.getElementById('userForm').reset();
+        showNotification('User created successfully', 'success');
+        loadUsers();
+    } catch (error) {
+        console.error('Error creating user:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+async function saveUser(id, changes, message) {
+    try {
+        const response = await fetch(`/admin/api/users/${id}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(changes)
+        });
+        if (!response.ok) throw new Error(await response.text());
+
+        showNotification(message, 'success');
+    } catch (error) {
+        console.error('Error updating user:', error);
+        showNotification(error.message, 'error');
+    }
+    loadUsers();
+}
+
+function updateUserRole(id, role) {
+    saveUser(id, { role: role }, 'Role updated successfully');
+}
+
+function resetUserPassword(id) {
+    const password = prompt('New password (at least 8 characters):');
+    if (password) {
+        saveUser(id, { password: password }, 'Password updated successfully');
+    }
+}
+
+async function deleteUser(id) {
+    if (!confirm('Are you sure you want to delete this user?')) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/admin/api/users/${id}`, { method: 'DELETE' });
+        if (!response.ok) throw new Error(await response.text());
+
+        showNotification('User deleted successfully', 'success');
+        loadUsers();
+    } catch (error) {
+        console.error('Error deleting user:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+async function loadAuditLog(event) {
+    if (event) event.preventDefault();
+
+    const filters = {
+        user: document.getElementById('auditUser').value,
+        action: document.getElementById('auditAction').value,
+        table: document.getElementById('auditTable').value,
+        row_id: document.getElementById('auditRow').value,
+        since: document.getElementById('auditSince').value,
+        until: document.getElementById('auditUntil').value
+    };
+    const params = new URLSearchParams();
+    Object.entries(filters).forEach(([key, value]) => {
+        if (value) params.set(key, value);
+    });
+
+    try {
+        const response = await fetch(`/admin/api/audit?${params}`);
+        if (!response.ok) throw new Error(await response.text());
+
+        renderAuditLog(await response.json());
+    } catch (error) {
+        console.error('Error loading audit log:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+function renderAuditLog(entries) {
+    const container = document.getElementById('auditContainer');
+
+    if (entries.length === 0) {
+        container.innerHTML = '<p style="color: #e94560; text-align: center;">No audit entries found.</p>';
+        return;
+    }
+
+    const formatSnapshot = snapshot => snapshot ? `<pre>${escapeHtml(JSON.stringify(snapshot, null, 2))}</pre>` : '';
+
+    container.innerHTML = `
+        <div class="data-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>When</th>
+                        <th>User</th>
+                        <th>Action</th>
+                        <th>Table</th>
+                        <th>Row</th>
+                        <th>Query</th>
+                        <th>Before</th>
+                        <th>After</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${entries.map(entry => `
+                        <tr>
+                            <td>${escapeHtml(entry.created_at)}</td>
+                            <td>${escapeHtml(entry.username)}</td>
+                            <td>${escapeHtml(entry.action)}</td>
+                            <td>${escapeHtml(entry.table_name || '')}</td>
+                            <td>${escapeHtml(entry.row_id || '')}</td>
+                            <td>${escapeHtml(entry.query || '')}</td>
+                            <td>${formatSnapshot(entry.before)}</td>
+                            <td>${formatSnapshot(entry.after)}</td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        </div>
+    `;
+}
+
+async function loadBackups() {
+    try {
+        const response = await fetch('/admin/api/backups');
+        if (!response.ok) throw new Error(await response.text());
+
+        renderBackups(await response.json());
+    } catch (error) {
+        console.error('Error loading backups:', error);
+        showNotification('Failed to load backups', 'error');
+    }
+}
+
+function renderBackups(backups) {
+    const container = document.getElementById('backupsContainer');
+
+    if (backups.length === 0) {
+        container.innerHTML = '<p style="color: #e94560; text-align: center;">No backups yet.</p>';
+        return;
+    }
+
+    container.innerHTML = `
+        <div class="data-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>Created</th>
+                        <th>Kind</th>
+                        <th>Size</th>
+                        <th>Actions</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${backups.map(backup => `
+                        <tr>
+                            <td>${escapeHtml(new Date(backup.created_at).toLocaleString())}</td>
+                            <td>${escapeHtml(backup.kind)}</td>
+                            <td>${(backup.size / 1024).toFixed(1)} KB</td>
+                            <td>
+                                <div class="action-buttons">
+                                    <a href="/admin/api/backups/${encodeURIComponent(backup.name)}" class="btn btn-secondary btn-small">Download</a>
+                                    <button data-action="restore" data-name="${escapeHtml(backup.name)}" class="btn btn-primary btn-small">Restore</button>
+                                    <button data-action="delete" data-name="${escapeHtml(backup.name)}" class="btn btn-danger btn-small">Delete</button>
+                                </div>
+                            </td>
+                        </tr>
+                    `).join('')}
+                </tbody>
+            </table>
+        </div>
+    `;
+
+    container.querySelectorAll('button[data-action="restore"]').forEach(button => {
+        button.addEventListener('click', () => restoreBackup(button.dataset.name));
+    });
+    container.querySelectorAll('button[data-action="delete"]').forEach(button => {
+        button.addEventListener('click', () => deleteBackup(button.dataset.name));
+    });
+}
+
+async function createBackup() {
+    try {
+        const response = await fetch('/admin/api/backups', { method: 'POST' });
+        if (!response.ok) throw new Error(await response.text());
+
+        const backup = await response.json();
+        showNotification(`Backup ${backup.name} created`, 'success');
+        loadBackups();
+    } catch (error) {
+        console.error('Error creating backup:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+async function deleteBackup(name) {
+    if (!confirm(`Delete backup ${name}?`)) return;
+
+    try {
+        const response = await fetch(`/admin/api/backups/${encodeURIComponent(name)}`, { method: 'DELETE' });
+        if (!response.ok) throw new Error(await response.text());
+
+        showNotification('Backup deleted successfully', 'success');
+        loadBackups();
+    } catch (error) {
+        console.error('Error deleting backup:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
+async function restoreBackup(name) {
+    if (!confirm(`Replace the database with backup ${name}? The current database is backed up first.`)) return;
+
+    await restoreDatabase(`/admin/api/backups/${encodeURIComponent(name)}/restore`);
+}
+
+async function restoreUpload(event) {
+    event.preventDefault();
+
+    const file = document.getElementById('restoreFile').files[0];
+    if (!file) return;
+    if (!confirm(`Replace the database with ${file.name}? The current database is backed up first.`)) return;
+
+    await restoreDatabase('/admin/api/restore', file);
+}
+
+// restoreDatabase runs a restore, then checks the session still exists since
+// the users come from the restored database
+async function restoreDatabase(url, body) {
+    try {
+        const response = await fetch(url, { method: 'POST', body });
+        if (!response.ok) throw new Error(await response.text());
+
+        showNotification('Database restored successfully', 'success');
+        await checkSession();
+        if (currentUser) showTab('backups');
+    } catch (error) {
+        console.error('Error restoring database:', error);
+        showNotification(error.message, 'error');
+    }
+}
+
 function escapeHtml(text) {
     const map = {
         '&': '&amp;',
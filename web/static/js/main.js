@@ -2,6 +2,7 @@ let recipes = [];
 let selectedItems = [];
 let categories = [];
 let activeCategory = 'all'; // Ajouter une variable pour tracker le filtre actif
+let currentListId = null;
 
 // Initialize the application
 document.addEventListener('DOMContentLoaded', function() {
@@ -9,6 +10,8 @@ document.addEventListener('DOMContentLoaded', function() {
     activeCategory = localStorage.getItem('activeCategory') || 'all';
 
     loadRecipes();
+    loadTechnologies();
+    loadSavedLists();
     renderCart();
     setupEventListeners();
 });
@@ -28,7 +31,8 @@ function setupEventListeners() {
 
 async function loadRecipes() {
     try {
-        const response = await fetch('/api/recipes');
+        const locked = document.getElementById('hideLocked').checked ? 'hide' : 'flag';
+        const response = await fetch(`/api/recipes?locked=${locked}`);
         if (!response.ok) {
             throw new Error(`HTTP error! status: ${response.status}`);
         }
@@ -84,10 +88,10 @@ function renderRecipes(filteredRecipes = recipes) {
 
     filteredRecipes.forEach(recipe => {
         const card = document.createElement('div');
-        card.className = 'recipe-card';
+        card.className = recipe.locked ? 'recipe-card locked' : 'recipe-card';
         card.innerHTML = `
-            <div class="recipe-title">${escapeHtml(recipe.name)}</div>
-            <div class="recipe-category">${escapeHtml(recipe.category)}</div>
+            <div class="recipe-title">${escapeHtml(recipe.name)}${recipe.locked ? ' 🔒' : ''}</div>
+            <div class="recipe-category">${escapeHtml(recipe.category)}${recipe.station ? ` - ${escapeHtml(recipe.station)}` : ''}</div>
             <div class="recipe-description">${escapeHtml(recipe.description || '')}</div>
             <ul class="resources-list">
                 ${recipe.resources.map(r => `<li>${escapeHtml(r.name)}: ${r.quantity}</li>`).join('')}
@@ -101,6 +105,61 @@ function renderRecipes(filteredRecipes = recipes) {
     });
 }
 
+async function loadTechnologies() {
+    try {
+        const response = await fetch('/api/technologies');
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        renderTechnologies(await response.json() || []);
+    } catch (error) {
+        console.error('Error loading technologies:', error);
+        showError('Failed to load the technology tree.');
+    }
+}
+
+function renderTechnologies(levels) {
+    const container = document.getElementById('technologyTree');
+
+    if (levels.length === 0) {
+        container.innerHTML = '<p style="color: #0f3460; text-align: center;">No technologies found</p>';
+        return;
+    }
+
+    container.innerHTML = levels.map(level => `
+        <div class="tech-level">
+            <span class="tech-level-title">Level ${level.level}</span>
+            ${level.technologies.map(tech => `
+                <label class="tech-item">
+                    <input type="checkbox" ${tech.unlocked ? 'checked' : ''} onchange="toggleTechnology(${tech.id}, this.checked)">
+                    ${escapeHtml(tech.name)} (${tech.points} pt)
+                </label>
+            `).join('')}
+        </div>
+    `).join('');
+}
+
+async function toggleTechnology(technologyId, unlocked) {
+    try {
+        const response = await fetch(`/api/technologies/${technologyId}`, {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ unlocked })
+        });
+
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+
+        loadRecipes();
+    } catch (error) {
+        console.error('Error updating technology:', error);
+        showError('Failed to update technology.');
+    }
+}
+
 function filterByCategory(category) {
     // Sauvegarder le filtre actif
     activeCategory = category;
@@ -171,6 +230,199 @@ function renderCart() {
     `).join('');
 }
 
+async function loadSavedLists() {
+    try {
+        const response = await fetch('/api/lists');
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const lists = await response.json();
+
+        const select = document.getElementById('savedLists');
+        select.innerHTML = '<option value="">Saved lists...</option>' + lists.map(list =>
+            `<option value="${list.id}" data-name="${escapeHtml(list.name)}" ${list.id === currentListId ? 'selected' : ''}>` +
+            `${escapeHtml(list.name)}${list.stale ? ' (outdated)' : ''}</option>`
+        ).join('');
+    } catch (error) {
+        console.error('Error loading saved lists:', error);
+        showError('Failed to load saved lists.');
+    }
+}
+
+async function loadSavedList() {
+    const listId = parseInt(document.getElementById('savedLists').value);
+    if (!listId) {
+        currentListId = null;
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/lists/${listId}`);
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const list = await response.json();
+
+        currentListId = list.id;
+        selectedItems = list.items.map(item => ({ id: item.id, quantity: item.quantity, name: item.name }));
+        renderCart();
+        if (list.stale) {
+            showError(`List ${list.name} was planned for game version ${list.game_version}. ` +
+                `These recipes changed since: ${list.stale_recipes.join(', ')}. Save the list again once reviewed.`);
+        } else {
+            showSuccess(`Loaded list ${list.name}`);
+        }
+    } catch (error) {
+        console.error('Error loading saved list:', error);
+        showError('Failed to load the saved list.');
+    }
+}
+
+async function saveCurrentList() {
+    if (selectedItems.length === 0) {
+        showError('Please select some items first!');
+        return;
+    }
+
+    const select = document.getElementById('savedLists');
+    const currentName = currentListId ? select.options[select.selectedIndex].dataset.name : '';
+    const name = prompt('List name:', currentName);
+    if (!name) return;
+
+    // Saving under the same name updates the loaded list, a new name creates a copy
+    const updating = currentListId && name === currentName;
+
+    try {
+        const response = await fetch(updating ? `/api/lists/${currentListId}` : '/api/lists', {
+            method: updating ? 'PUT' : 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ name, items: selectedItems })
+        });
+
+        if (!response.ok) {
+            const errorText = await response.text();
+            throw new Error(errorText || `HTTP error! status: ${response.status}`);
+        }
+
+        const list = await response.json();
+        currentListId = list.id;
+        loadSavedLists();
+        showSuccess(`Saved list ${list.name}`);
+    } catch (error) {
+        console.error('Error saving list:', error);
+        showError(`Failed to save list. ${error.message}`);
+    }
+}
+
+async function deleteSavedList() {
+    if (!currentListId) {
+        showError('Please load a saved list first');
+        return;
+    }
+
+    if (!confirm('Delete this saved list?')) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/lists/${currentListId}`, { method: 'DELETE' });
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+
+        currentListId = null;
+        loadSavedLists();
+        showSuccess('List deleted');
+    } catch (error) {
+        console.error('Error deleting list:', error);
+        showError('Failed to delete list.');
+    }
+}
+
+async function exportList() {
+    if (selectedItems.length === 0) {
+        showError('Please select some items first!');
+        return;
+    }
+
+    const format = document.getElementById('exportFormat').value;
+    const select = document.getElementById('savedLists');
+    const name = currentListId ? select.options[select.selectedIndex].dataset.name : '';
+
+    try {
+        const response = await fetch(`/api/export?format=${format}`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({
+                name,
+                items: selectedItems,
+                stop_at_intermediates: document.getElementById('stopAtIntermediates').checked,
+                use_inventory: document.getElementById('useInventory').checked
+            })
+        });
+
+        if (!response.ok) {
+            const errorText = await response.text();
+            throw new Error(errorText || `HTTP error! status: ${response.status}`);
+        }
+
+        const extension = { json: 'json', csv: 'csv', markdown: 'md' }[format];
+        const link = document.createElement('a');
+        link.href = URL.createObjectURL(await response.blob());
+        link.download = `${name || 'crafting-list'}.${extension}`;
+        link.click();
+        URL.revokeObjectURL(link.href);
+    } catch (error) {
+        console.error('Error exporting list:', error);
+        showError(`Failed to export list. ${error.message}`);
+    }
+}
+
+async function importList() {
+    const input = document.getElementById('importFile');
+    const file = input.files[0];
+    if (!file) return;
+
+    const format = file.name.toLowerCase().endsWith('.csv') ? 'csv' : 'json';
+
+    try {
+        const response = await fetch(`/api/import?format=${format}`, {
+            method: 'POST',
+            body: await file.text()
+        });
+
+        if (!response.ok) {
+            const errorText = await response.text();
+            throw new Error(errorText || `HTTP error! status: ${response.status}`);
+        }
+
+        const result = await response.json();
+        currentListId = null;
+        selectedItems = result.items.map(item => ({ id: item.id, quantity: item.quantity, name: item.name }));
+        renderCart();
+
+        const problems = [
+            ...result.unknown.map(name => `unknown recipe "${name}"`),
+            ...result.ambiguous.map(name => `ambiguous recipe "${name}"`),
+            ...result.invalid
+        ];
+        if (problems.length > 0) {
+            showError(`Imported ${result.items.length} item(s), skipped: ${problems.join(', ')}`);
+        } else {
+            showSuccess(`Imported ${result.items.length} item(s)`);
+        }
+    } catch (error) {
+        console.error('Error importing list:', error);
+        showError(`Failed to import list. ${error.message}`);
+    } finally {
+        input.value = '';
+    }
+}
+
 async function calculateResources() {
     if (selectedItems.length === 0) {
         showError('Please select some items first!');
@@ -183,18 +435,28 @@ async function calculateResources() {
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({ items: selectedItems })
+            body: JSON.stringify({
+                items: selectedItems,
+                stop_at_intermediates: document.getElementById('stopAtIntermediates').checked,
+                use_inventory: document.getElementById('useInventory').checked,
+                work_speed: readWorkSpeed()
+            })
         });
 
         if (!response.ok) {
-            throw new Error(`HTTP error! status: ${response.status}`);
+            const errorText = await response.text();
+            throw new Error(errorText || `HTTP error! status: ${response.status}`);
         }
 
-        const resourceTotals = await response.json();
-        renderResults(resourceTotals);
+        const result = await response.json();
+        renderResults(result.resources);
+        renderWorkload(result.workload);
+        renderCrafts(result.crafts);
+        renderStations(result.stations);
+        renderTechnologyRequirement(result.technology);
     } catch (error) {
         console.error('Error calculating resources:', error);
-        showError('Failed to calculate resources. Please try again.');
+        showError(`Failed to calculate resources. ${error.message}`);
     }
 }
 
@@ -211,8 +473,8 @@ function renderResults(resourceTotals) {
 
     totalsContainer.innerHTML = resourceTotals.map(resource => `
         <div class="resource-total">
-            <span>${escapeHtml(resource.name)}</span>
-            <span>${resource.total}</span>
+            <span>${escapeHtml(resource.name)}${resource.craftable ? ' <em>(craftable)</em>' : ''}</span>
+            <span>${resource.owned > 0 ? `${resource.missing} missing (${resource.owned}/${resource.total} owned)` : resource.total}</span>
         </div>
     `).join('');
 
@@ -220,6 +482,146 @@ function renderResults(resourceTotals) {
     resultsSection.scrollIntoView({ behavior: 'smooth' });
 }
 
+function readWorkSpeed() {
+    const pals = document.getElementById('handiworkPals').value
+        .split(',')
+        .map(level => parseInt(level.trim()))
+        .filter(level => !isNaN(level))
+        .map((level, i) => ({ name: `Pal ${i + 1}`, handiwork_level: level }));
+
+    return {
+        player_speed: parseFloat(document.getElementById('playerSpeed').value) || 0,
+        pals
+    };
+}
+
+function renderWorkload(workload) {
+    const container = document.getElementById('workloadEstimate');
+
+    if (workload.total_work === 0) {
+        container.innerHTML = '';
+        return;
+    }
+
+    container.innerHTML = `
+        <h3>Estimated Crafting Time</h3>
+        <div class="resource-total">
+            <span>${workload.total_work} work at speed ${workload.work_speed}</span>
+            <span>${formatDuration(workload.seconds)}</span>
+        </div>
+    `;
+}
+
+function formatDuration(seconds) {
+    const total = Math.ceil(seconds);
+    const hours = Math.floor(total / 3600);
+    const minutes = Math.floor((total % 3600) / 60);
+    const secs = total % 60;
+
+    if (hours > 0) return `${hours}h ${minutes}m`;
+    if (minutes > 0) return `${minutes}m ${secs}s`;
+    return `${secs}s`;
+}
+
+function renderCrafts(crafts) {
+    const container = document.getElementById('craftingSteps');
+
+    if (crafts.length === 0) {
+        container.innerHTML = '';
+        return;
+    }
+
+    container.innerHTML = `
+        <h3>Crafting Steps</h3>
+        ${crafts.map(craft => `
+            <div class="resource-total">
+                <span>${escapeHtml(craft.name)}${craft.yield > 1 ? ` <em>(x${craft.yield} per craft)</em>` : ''}</span>
+                <span>${craft.batches} craft(s) = ${craft.produced}${craft.overproduction > 0 ? ` (+${craft.overproduction} extra)` : ''}</span>
+            </div>
+        `).join('')}
+    `;
+}
+
+function renderStations(stations) {
+    const container = document.getElementById('stationRequirements');
+
+    if (stations.length === 0) {
+        container.innerHTML = '';
+        return;
+    }
+
+    container.innerHTML = `
+        <h3>Crafting Stations</h3>
+        ${stations.map(station => `
+            <div class="resource-total">
+                <span>${escapeHtml(station.name)} <em>(${station.recipes.map(escapeHtml).join(', ')})</em></span>
+                <span>
+                    <label><input type="checkbox" ${station.built ? 'checked' : ''} onchange="setStationBuilt(${station.id}, this.checked)"> Built</label>
+                    ${!station.built && station.recipe_id ? `<button class="btn btn-primary" onclick="addStationToCart(${station.recipe_id})">Add to list</button>` : ''}
+                </span>
+            </div>
+        `).join('')}
+    `;
+}
+
+async function setStationBuilt(stationId, built) {
+    try {
+        const response = await fetch(`/api/stations/${stationId}`, {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ built })
+        });
+
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+
+        calculateResources();
+    } catch (error) {
+        console.error('Error updating station:', error);
+        showError('Failed to update station.');
+    }
+}
+
+function addStationToCart(recipeId) {
+    const recipe = recipes.find(r => r.id === recipeId);
+    if (!recipe) {
+        showError('Station recipe not found');
+        return;
+    }
+
+    if (!selectedItems.some(item => item.id === recipeId)) {
+        selectedItems.push({ id: recipeId, quantity: 1, name: recipe.name });
+    }
+
+    renderCart();
+    calculateResources();
+}
+
+function renderTechnologyRequirement(requirement) {
+    const container = document.getElementById('technologyRequirement');
+
+    if (requirement.technologies.length === 0) {
+        container.innerHTML = requirement.player_level > 0
+            ? `<p class="tech-summary">Everything is unlocked (player level ${requirement.player_level} required).</p>`
+            : '';
+        return;
+    }
+
+    container.innerHTML = `
+        <h3>Technologies to Unlock</h3>
+        <p class="tech-summary">Player level ${requirement.player_level}, ${requirement.points} technology point(s)</p>
+        ${requirement.technologies.map(tech => `
+            <div class="resource-total">
+                <span>${escapeHtml(tech.name)}</span>
+                <span>Lv ${tech.level} - ${tech.points} pt</span>
+            </div>
+        `).join('')}
+    `;
+}
+
 // Utility functions
 function escapeHtml(text) {
     const map = {
@@ -17,13 +17,37 @@ const AdminPageHTML = `<!DOCTYPE html>
                 <a href="/" class="nav-link">Crafting Helper</a>
                 <a href="/admin" class="nav-link active">Database Admin</a>
             </nav>
+            <div id="userBadge" class="user-badge" style="display: none;">
+                <span id="currentUser"></span>
+                <button onclick="logout()" class="btn btn-secondary btn-small">Log Out</button>
+            </div>
         </header>
 
+        <!-- Login -->
+        <div id="loginSection" class="login-section" style="display: none;">
+            <h2>Log In</h2>
+            <form id="loginForm" onsubmit="login(event)">
+                <div class="form-group">
+                    <label for="loginUsername">Username:</label>
+                    <input type="text" id="loginUsername" autocomplete="username" required>
+                </div>
+                <div class="form-group">
+                    <label for="loginPassword">Password:</label>
+                    <input type="password" id="loginPassword" autocomplete="current-password" required>
+                </div>
+                <button type="submit" class="btn btn-primary">Log In</button>
+            </form>
+        </div>
+
+        <div id="adminContent" style="display: none;">
         <div class="admin-tabs">
             <button class="tab-btn active" onclick="showTab('schema')">Database Schema</button>
             <button class="tab-btn" onclick="showTab('query')">SQL Query</button>
             <button class="tab-btn" onclick="showTab('tables')">Manage Tables</button>
-            <button class="tab-btn" onclick="showTab('create')">Create Table</button>
+            <button class="tab-btn" onclick="showTab('create')" data-role="admin">Create Table</button>
+            <button class="tab-btn" onclick="showTab('users')" data-role="admin">Users</button>
+            <button class="tab-btn" onclick="showTab('audit')" data-role="admin">Audit Log</button>
+            <button class="tab-btn" onclick="showTab('backups')" data-role="admin">Backups</button>
         </div>
 
         <!-- Schema Tab -->
@@ -38,7 +62,9 @@ const AdminPageHTML = `<!DOCTYPE html>
             <h2>Execute SQL Query</h2>
             <div class="query-section">
                 <textarea id="queryInput" placeholder="Enter your SQL query here..." rows="6"></textarea>
-                <button onclick="executeQuery()" class="btn btn-primary">Execute Query</button>
+                <button onclick="executeQuery()" class="btn btn-primary" id="executeQueryBtn">Execute Query</button>
+                <button onclick="explainQuery()" class="btn btn-secondary" id="explainQueryBtn">Explain Plan</button>
+                <button onclick="cancelQuery()" class="btn btn-danger" id="cancelQueryBtn" disabled>Cancel</button>
                 <div class="query-examples">
                     <h3>Example Queries:</h3>
                     <button onclick="setQuery('SELECT * FROM crafting_recipes')" class="btn btn-secondary">View All Recipes</button>
@@ -53,11 +79,30 @@ const AdminPageHTML = `<!DOCTYPE html>
         <div id="tables-tab" class="tab-content">
             <h2>Manage Table Data</h2>
             <div class="table-selector">
-                <select id="tableSelect" onchange="loadTableData()">
+                <select id="tableSelect" onchange="selectTable()">
                     <option value="">Select a table...</option>
                 </select>
-                <button onclick="addNewRecord()" class="btn btn-success" id="addRecordBtn" disabled>Add New Record</button>
+                <button onclick="addNewRecord()" class="btn btn-success" id="addRecordBtn" data-role="editor" disabled>Add New Record</button>
+            </div>
+            <div class="table-selector">
+                <select id="exportFormat">
+                    <option value="csv">CSV</option>
+                    <option value="json">JSON</option>
+                    <option value="ndjson">NDJSON</option>
+                </select>
+                <button onclick="exportTable()" class="btn btn-primary" id="exportTableBtn" disabled>Export</button>
+                <form id="importForm" class="table-selector" data-role="editor" onsubmit="importTable(event)">
+                    <input type="file" id="importFile" accept=".csv,.json,.ndjson" required>
+                    <select id="importMode">
+                        <option value="insert">Insert rows</option>
+                        <option value="upsert">Upsert by primary key</option>
+                        <option value="replace">Replace all rows</option>
+                    </select>
+                    <label><input type="checkbox" id="importDryRun" checked> Dry run</label>
+                    <button type="submit" class="btn btn-success" id="importTableBtn" disabled>Import</button>
+                </form>
             </div>
+            <div id="importReport"></div>
             <div id="tableDataContainer"></div>
         </div>
 
@@ -90,6 +135,117 @@ const AdminPageHTML = `<!DOCTYPE html>
                 </div>
                 <button onclick="createTable()" class="btn btn-primary">Create Table</button>
             </div>
+
+            <h2>Alter Table</h2>
+            <div class="create-table-form">
+                <div class="form-group">
+                    <label for="alterTable">Table:</label>
+                    <select id="alterTable" onchange="updateAlterColumns()">
+                        <option value="">Select a table...</option>
+                    </select>
+                </div>
+                <div class="form-group">
+                    <label for="alterAction">Change:</label>
+                    <select id="alterAction" onchange="updateAlterForm()">
+                        <option value="add_column">Add column</option>
+                        <option value="rename_column">Rename column</option>
+                        <option value="alter_column">Change column definition</option>
+                        <option value="drop_column">Drop column</option>
+                        <option value="rename_table">Rename table</option>
+                        <option value="drop_table">Drop table</option>
+                    </select>
+                </div>
+                <div class="form-group" id="alterColumnGroup">
+                    <label for="alterColumn">Column:</label>
+                    <select id="alterColumn"></select>
+                </div>
+                <div class="form-group" id="alterNewNameGroup">
+                    <label for="alterNewName">New Name:</label>
+                    <input type="text" id="alterNewName" placeholder="Enter new name">
+                </div>
+                <div class="column-row" id="alterDefinition">
+                    <input type="text" placeholder="Column name" id="alterDefName">
+                    <select id="alterDefType">
+                        <option value="INTEGER">INTEGER</option>
+                        <option value="TEXT">TEXT</option>
+                        <option value="REAL">REAL</option>
+                        <option value="BLOB">BLOB</option>
+                    </select>
+                    <label><input type="checkbox" id="alterDefPrimary"> Primary Key</label>
+                    <label><input type="checkbox" id="alterDefNotNull"> Not Null</label>
+                    <input type="text" placeholder="Default value" id="alterDefDefault">
+                </div>
+                <button onclick="alterTable(true)" class="btn btn-secondary">Preview</button>
+                <button onclick="alterTable(false)" class="btn btn-primary">Apply Change</button>
+                <div id="alterPreview"></div>
+            </div>
+        </div>
+
+        <!-- Users Tab -->
+        <div id="users-tab" class="tab-content">
+            <h2>Users</h2>
+            <div id="usersContainer"></div>
+            <h3>Add User</h3>
+            <form id="userForm" class="create-table-form" onsubmit="createUser(event)">
+                <div class="form-group">
+                    <label for="newUsername">Username:</label>
+                    <input type="text" id="newUsername" required>
+                </div>
+                <div class="form-group">
+                    <label for="newPassword">Password:</label>
+                    <input type="password" id="newPassword" autocomplete="new-password" minlength="8" required>
+                </div>
+                <div class="form-group">
+                    <label for="newRole">Role:</label>
+                    <select id="newRole">
+                        <option value="viewer">Viewer</option>
+                        <option value="editor">Editor</option>
+                        <option value="admin">Admin</option>
+                    </select>
+                </div>
+                <button type="submit" class="btn btn-primary">Add User</button>
+            </form>
+        </div>
+
+        <!-- Audit Log Tab -->
+        <div id="audit-tab" class="tab-content">
+            <h2>Audit Log</h2>
+            <form id="auditFilters" class="table-selector" onsubmit="loadAuditLog(event)">
+                <input type="text" id="auditUser" placeholder="User">
+                <select id="auditAction">
+                    <option value="">All actions</option>
+                    <option value="insert">Insert</option>
+                    <option value="update">Update</option>
+                    <option value="delete">Delete</option>
+                    <option value="create_table">Create table</option>
+                    <option value="alter_schema">Alter schema</option>
+                    <option value="query">Query</option>
+                    <option value="import">Import</option>
+                    <option value="backup">Backup</option>
+                    <option value="restore">Restore</option>
+                </select>
+                <input type="text" id="auditTable" placeholder="Table">
+                <input type="text" id="auditRow" placeholder="Row ID">
+                <input type="date" id="auditSince" title="Since">
+                <input type="date" id="auditUntil" title="Until">
+                <button type="submit" class="btn btn-primary">Filter</button>
+            </form>
+            <div id="auditContainer"></div>
+        </div>
+
+        <!-- Backups Tab -->
+        <div id="backups-tab" class="tab-content">
+            <h2>Backups</h2>
+            <div class="table-selector">
+                <button onclick="createBackup()" class="btn btn-primary">Create Backup</button>
+            </div>
+            <div id="backupsContainer"></div>
+            <h3>Restore From File</h3>
+            <form id="restoreForm" class="table-selector" onsubmit="restoreUpload(event)">
+                <input type="file" id="restoreFile" accept=".db,.sqlite,.sqlite3" required>
+                <button type="submit" class="btn btn-danger">Restore</button>
+            </form>
+        </div>
         </div>
 
         <!-- Modal for editing records -->
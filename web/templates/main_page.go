@@ -27,15 +27,47 @@ const MainPageHTML = `<!DOCTYPE html>
 
         <div class="recipes-grid" id="recipesGrid"></div>
 
+        <div class="tech-section">
+            <h2>Technology Tree</h2>
+            <label class="cart-option"><input type="checkbox" id="hideLocked" onchange="loadRecipes()"> Hide locked recipes</label>
+            <div id="technologyTree"></div>
+        </div>
+
         <div class="cart-section">
             <h2>Selected Items</h2>
+            <div class="saved-lists">
+                <select id="savedLists" onchange="loadSavedList()">
+                    <option value="">Saved lists...</option>
+                </select>
+                <button class="btn btn-primary" onclick="saveCurrentList()">Save List</button>
+                <button class="btn btn-danger" onclick="deleteSavedList()">Delete List</button>
+            </div>
+            <div class="saved-lists">
+                <select id="exportFormat">
+                    <option value="json">JSON</option>
+                    <option value="csv">CSV</option>
+                    <option value="markdown">Markdown checklist</option>
+                </select>
+                <button class="btn btn-secondary" onclick="exportList()">Export</button>
+                <label class="btn btn-secondary">Import<input type="file" id="importFile" accept=".json,.csv" onchange="importList()" hidden></label>
+            </div>
             <div id="cart"></div>
+            <label class="cart-option"><input type="checkbox" id="stopAtIntermediates"> Stop at intermediate materials (e.g. Ingot)</label>
+            <label class="cart-option"><input type="checkbox" id="useInventory"> Subtract resources from my inventory</label>
+            <div class="work-speed">
+                <label>Player work speed <input type="number" id="playerSpeed" class="quantity-input" min="0" value="100"></label>
+                <label>Handiwork pals (levels, comma separated) <input type="text" id="handiworkPals" class="quantity-input" placeholder="e.g. 2, 3"></label>
+            </div>
             <button class="calculate-btn" onclick="calculateResources()">Calculate Total Resources</button>
         </div>
 
         <div class="results-section hidden" id="results">
             <h2>Total Resources Needed</h2>
             <div id="resourceTotals"></div>
+            <div id="workloadEstimate"></div>
+            <div id="craftingSteps"></div>
+            <div id="stationRequirements"></div>
+            <div id="technologyRequirement"></div>
         </div>
     </div>
 